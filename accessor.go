@@ -0,0 +1,172 @@
+package stst
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// AccessorGenerator generates getters, setters and builders of structs.
+	//
+	//   - `GetXxx()` returns the field, or zero value if the receiver is nil (like protobuf)
+	//   - `SetXxx(v)` sets the field
+	//   - `XxxBuilder` sets fields fluently and builds the struct
+	//
+	// Only exported fields are handled, and they are configured by `stst` tag.
+	//   - -: no accessors
+	//   - noget: no getter
+	//   - set: setter is generated
+	//   - nobuild: the field can not be set by builder
+	AccessorGenerator struct{}
+
+	accessorField struct {
+		field *Field
+		get   bool
+		set   bool
+		build bool
+	}
+)
+
+// NewAccessorGenerator returns AccessorGenerator.
+func NewAccessorGenerator() *AccessorGenerator {
+	return &AccessorGenerator{}
+}
+
+// Generate returns Go source of accessors for struct schemas.
+// The pkg should be the package of the schemas because accessors are methods.
+func (g *AccessorGenerator) Generate(pkg GoPackage, schemas []*Schema) ([]byte, error) {
+	p := newGoTypePrinter(pkg)
+	var b strings.Builder
+	for _, sc := range schemas {
+		if !sc.IsStruct() || len(sc.TypePrefixes) > 0 {
+			continue
+		}
+		fields, err := g.fields(sc)
+		if err != nil {
+			return nil, fmt.Errorf("accessor: %s: %w", sc.Name, err)
+		}
+		g.accessors(&b, p, sc, fields)
+	}
+	out, err := p.file(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("accessor: %w", err)
+	}
+	return out, nil
+}
+
+func (g *AccessorGenerator) fields(sc *Schema) ([]*accessorField, error) {
+	names := make(map[string]bool, len(sc.Fields))
+	for _, f := range sc.Fields {
+		names[f.Name] = true
+	}
+
+	var out []*accessorField
+	for _, f := range sc.Fields {
+		if !f.IsExported() {
+			continue
+		}
+		opts, err := ststOptions(f)
+		if err != nil {
+			return nil, err
+		}
+		if opts["-"] {
+			continue
+		}
+		af := &accessorField{
+			field: f,
+			get:   !opts["noget"],
+			set:   opts["set"],
+			build: !opts["nobuild"],
+		}
+		// field and method can not have same name
+		if names["Get"+f.Name] {
+			if opts["noget"] {
+				af.get = false
+			} else {
+				return nil, fmt.Errorf("field %s: getter conflicts with field Get%s", f.Name, f.Name)
+			}
+		}
+		if af.set && names["Set"+f.Name] {
+			return nil, fmt.Errorf("field %s: setter conflicts with field Set%s", f.Name, f.Name)
+		}
+		if af.build && f.Name == "Build" {
+			return nil, fmt.Errorf("field %s: builder method conflicts with Build, use `stst:\"nobuild\"`", f.Name)
+		}
+		out = append(out, af)
+	}
+	return out, nil
+}
+
+func (g *AccessorGenerator) accessors(b *strings.Builder, p *goTypePrinter, sc *Schema, fields []*accessorField) {
+	decl, args := typeParamsDecl(sc.TypeParams)
+	typeParams := typeParamNames(sc.TypeParams)
+	srcPkgID := p.pkg.ID
+	if sc.Type != nil && sc.Type.PkgID != "" {
+		srcPkgID = sc.Type.PkgID
+	}
+	typ := sc.Name + args
+
+	var buildable []*accessorField
+	for _, f := range fields {
+		ft := p.field(f.field, srcPkgID, typeParams)
+		if f.get {
+			fmt.Fprintf(b, "// Get%s returns %s of the %s, or zero value if the %s is nil.\n", f.field.Name, f.field.Name, sc.Name, sc.Name)
+			fmt.Fprintf(b, "func (x *%s) Get%s() %s {\n", typ, f.field.Name, ft)
+			if zero := zeroLiteral(f.field); zero != "" {
+				fmt.Fprintf(b, "if x == nil {\nreturn %s\n}\n", zero)
+			} else {
+				fmt.Fprintf(b, "if x == nil {\nvar zero %s\nreturn zero\n}\n", ft)
+			}
+			fmt.Fprintf(b, "return x.%s\n}\n\n", f.field.Name)
+		}
+		if f.set {
+			fmt.Fprintf(b, "// Set%s sets %s of the %s.\n", f.field.Name, f.field.Name, sc.Name)
+			fmt.Fprintf(b, "func (x *%s) Set%s(v %s) {\nx.%s = v\n}\n\n", typ, f.field.Name, ft, f.field.Name)
+		}
+		if f.build {
+			buildable = append(buildable, f)
+		}
+	}
+	if len(buildable) == 0 {
+		return
+	}
+
+	builder := sc.Name + "Builder"
+	fmt.Fprintf(b, "// %s builds %s.\n", builder, sc.Name)
+	fmt.Fprintf(b, "type %s%s struct {\nx %s\n}\n\n", builder, decl, typ)
+	fmt.Fprintf(b, "// New%s returns %s.\n", builder, builder)
+	fmt.Fprintf(b, "func New%s%s() *%s%s {\nreturn &%s%s{}\n}\n\n", builder, decl, builder, args, builder, args)
+	for _, f := range buildable {
+		fmt.Fprintf(b, "// %s sets %s of the %s.\n", f.field.Name, f.field.Name, sc.Name)
+		fmt.Fprintf(b, "func (b *%s%s) %s(v %s) *%s%s {\nb.x.%s = v\nreturn b\n}\n\n",
+			builder, args, f.field.Name, p.field(f.field, srcPkgID, typeParams), builder, args, f.field.Name)
+	}
+	fmt.Fprintf(b, "// Build returns %s built by the %s.\n", sc.Name, builder)
+	fmt.Fprintf(b, "func (b *%s%s) Build() *%s {\nx := b.x\nreturn &x\n}\n\n", builder, args, typ)
+}
+
+// zeroLiteral returns literal of zero value of the Field.
+// It returns empty string if the literal can not be determined, like struct.
+func zeroLiteral(f *Field) string {
+	if len(f.TypePrefixes) > 0 {
+		if f.TypePrefixes[0].Kind() == TypePrefixKindArray {
+			return ""
+		}
+		return "nil"
+	}
+	if f.IsFunc() || f.IsMap() || f.IsUntitledInterface {
+		return "nil"
+	}
+	if f.IsUntitledStruct || f.Type == nil || !f.Type.IsBasic() {
+		return ""
+	}
+	switch f.Type.Underlying {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "error", "any", "interface{}":
+		return "nil"
+	}
+	return "0"
+}
@@ -0,0 +1,111 @@
+package stst_test
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const accessorSrc = `package data
+
+import "time"
+
+type Good struct {
+	Name string
+}
+
+type Status int
+
+type Config struct {
+	Name     string         ` + "`stst:\"set\"`" + `
+	Port     int
+	Status   Status
+	Good     *Good
+	Born     time.Time
+	Attrs    map[string]int ` + "`stst:\"noget,nobuild\"`" + `
+	Secret   string         ` + "`stst:\"-\"`" + `
+	internal string
+}
+`
+
+func TestAccessorGenerate(t *testing.T) {
+	schemas := parseSource(t, accessorSrc)
+
+	got, err := stst.NewAccessorGenerator().Generate(stst.GoPackage{Name: "data", ID: testPkg}, []*stst.Schema{findSchema(schemas, "Config")})
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "data_gen.go", got, parser.AllErrors)
+	require.NoError(t, err)
+
+	src := string(got)
+	for _, want := range []string{
+		"func (x *Config) GetName() string {\n\tif x == nil {\n\t\treturn \"\"\n\t}\n\treturn x.Name\n}",
+		"func (x *Config) SetName(v string) {\n\tx.Name = v\n}",
+		"func (x *Config) GetPort() int {\n\tif x == nil {\n\t\treturn 0\n\t}",
+		"func (x *Config) GetStatus() Status {",
+		"func (x *Config) GetGood() *Good {\n\tif x == nil {\n\t\treturn nil\n\t}",
+		"func (x *Config) GetBorn() time.Time {\n\tif x == nil {\n\t\tvar zero time.Time\n\t\treturn zero\n\t}",
+		"type ConfigBuilder struct {\n\tx Config\n}",
+		"func NewConfigBuilder() *ConfigBuilder {",
+		"func (b *ConfigBuilder) Port(v int) *ConfigBuilder {\n\tb.x.Port = v\n\treturn b\n}",
+		"func (b *ConfigBuilder) Build() *Config {\n\tx := b.x\n\treturn &x\n}",
+	} {
+		assert.Contains(t, src, want)
+	}
+	for _, notWant := range []string{
+		"SetPort",
+		"GetAttrs",
+		"ConfigBuilder) Attrs",
+		"Secret",
+		"internal",
+	} {
+		assert.NotContains(t, src, notWant)
+	}
+}
+
+func TestAccessorGenerate_Generic(t *testing.T) {
+	schemas := parseSource(t, "package data\n\ntype Box[T any] struct {\n\tValue T\n}\n")
+	got, err := stst.NewAccessorGenerator().Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+	require.NoError(t, err)
+
+	src := string(got)
+	assert.Contains(t, src, "func (x *Box[T]) GetValue() T {\n\tif x == nil {\n\t\tvar zero T\n\t\treturn zero\n\t}")
+	assert.Contains(t, src, "type BoxBuilder[T any] struct {\n\tx Box[T]\n}")
+	assert.Contains(t, src, "func NewBoxBuilder[T any]() *BoxBuilder[T] {")
+	assert.Contains(t, src, "func (b *BoxBuilder[T]) Build() *Box[T] {")
+}
+
+func TestAccessorGenerate_Error(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		err    string
+	}{
+		{
+			name:   "getter conflicts",
+			fields: "Name string\n\tGetName string `stst:\"noget\"`",
+			err:    "getter conflicts with field GetName",
+		},
+		{
+			name:   "builder conflicts",
+			fields: "Build string",
+			err:    "builder method conflicts with Build",
+		},
+		{
+			name:   "unknown option",
+			fields: "Name string `stst:\"get\"`",
+			err:    "unknown option of stst tag: get",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			schemas := parseSource(t, "package data\n\ntype Sample struct {\n\t"+tt.fields+"\n}\n")
+			_, err := stst.NewAccessorGenerator().Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package stst
+
+import (
+	"reflect"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+type (
+	// PackageSchemas is the fact of Analyzer exported for each package.
+	// It is gob encoded by drivers which run analyzers per package (like unitchecker and nogo).
+	PackageSchemas struct {
+		// Path is the package path
+		Path    string
+		Schemas []*Schema
+	}
+
+	// AnalysisResult is the result of Analyzer.
+	AnalysisResult struct {
+		// Schemas are schemas of the analyzed package
+		Schemas []*Schema
+		// Deps are schemas of the dependencies by package path, which are passed as facts
+		Deps map[string][]*Schema
+	}
+)
+
+// Analyzer parses schemas of each package.
+// Other analyzers can get them by `pass.ResultOf[stst.Analyzer].(*stst.AnalysisResult)`,
+// and schemas of dependencies are available through PackageSchemas facts without loading packages again.
+// Positions and layouts of schemas and fields are recorded.
+var Analyzer = &analysis.Analyzer{
+	Name:       "stst",
+	Doc:        "parse schemas of types in the package\n\nIt provides stst schemas as the result and PackageSchemas facts for other analyzers.",
+	Run:        runAnalyzer,
+	ResultType: reflect.TypeOf((*AnalysisResult)(nil)),
+	FactTypes:  []analysis.Fact{(*PackageSchemas)(nil)},
+}
+
+// AFact marks PackageSchemas as analysis.Fact.
+func (*PackageSchemas) AFact() {}
+
+func (f *PackageSchemas) String() string {
+	return "schemas of " + f.Path
+}
+
+func runAnalyzer(pass *analysis.Pass) (interface{}, error) {
+	p := NewParser(&packages.Package{
+		ID:         pass.Pkg.Path(),
+		Name:       pass.Pkg.Name(),
+		PkgPath:    pass.Pkg.Path(),
+		Fset:       pass.Fset,
+		Syntax:     pass.Files,
+		Types:      pass.Pkg,
+		TypesInfo:  pass.TypesInfo,
+		TypesSizes: pass.TypesSizes,
+	})
+	p.Positions = true
+	p.Layout = true
+	schemas := p.Parse()
+	pass.ExportPackageFact(&PackageSchemas{Path: pass.Pkg.Path(), Schemas: schemas})
+
+	res := &AnalysisResult{
+		Schemas: schemas,
+		Deps:    map[string][]*Schema{},
+	}
+	for _, f := range pass.AllPackageFacts() {
+		if f.Package == pass.Pkg {
+			continue
+		}
+		if ps, ok := f.Fact.(*PackageSchemas); ok {
+			res.Deps[f.Package.Path()] = ps.Schemas
+		}
+	}
+	return res, nil
+}
+
+// All returns schemas of the package and its dependencies in order of package paths.
+// It can be passed to NewIndex and generators to resolve types of other packages.
+func (r *AnalysisResult) All() []*Schema {
+	paths := make([]string, 0, len(r.Deps))
+	for path := range r.Deps {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	out := append([]*Schema{}, r.Schemas...)
+	for _, path := range paths {
+		out = append(out, r.Deps[path]...)
+	}
+	return out
+}
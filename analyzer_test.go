@@ -0,0 +1,82 @@
+package stst_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"go/types"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+)
+
+// analyzerPass returns Pass to run stst.Analyzer for the source with facts of other packages.
+func analyzerPass(t *testing.T, path, src string, facts []analysis.PackageFact, exported *[]analysis.Fact) *analysis.Pass {
+	t.Helper()
+	pkg := loadSource(t, path, src)
+	return &analysis.Pass{
+		Analyzer:          stst.Analyzer,
+		Fset:              pkg.Fset,
+		Files:             pkg.Syntax,
+		Pkg:               pkg.Types,
+		TypesInfo:         pkg.TypesInfo,
+		TypesSizes:        pkg.TypesSizes,
+		ResultOf:          map[*analysis.Analyzer]interface{}{},
+		ExportPackageFact: func(fact analysis.Fact) { *exported = append(*exported, fact) },
+		AllPackageFacts:   func() []analysis.PackageFact { return facts },
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	require.NoError(t, analysis.Validate([]*analysis.Analyzer{stst.Analyzer}))
+
+	dep := types.NewPackage("example.com/dep", "dep")
+	depFact := &stst.PackageSchemas{
+		Path:    "example.com/dep",
+		Schemas: []*stst.Schema{{Name: "Base", Type: namedType("Base")}},
+	}
+	var exported []analysis.Fact
+	pass := analyzerPass(t, "example.com/model", `package model
+
+// User is a user.
+type User struct {
+	ID   int64  `+"`json:\"id\"`"+`
+	Name string `+"`json:\"name\"`"+`
+}
+`, []analysis.PackageFact{{Package: dep, Fact: depFact}}, &exported)
+
+	res, err := stst.Analyzer.Run(pass)
+	require.NoError(t, err)
+	got, ok := res.(*stst.AnalysisResult)
+	require.True(t, ok)
+
+	require.Len(t, got.Schemas, 1)
+	user := got.Schemas[0]
+	assert.Equal(t, "User", user.Name)
+	assert.Equal(t, []string{"// User is a user."}, user.Doc)
+	assert.Equal(t, 4, user.Pos.Line)
+	require.Len(t, user.Fields, 2)
+	assert.Equal(t, 5, user.Fields[0].Pos.Line)
+	assert.Equal(t, int64(24), user.Size)
+
+	assert.Equal(t, map[string][]*stst.Schema{"example.com/dep": depFact.Schemas}, got.Deps)
+	var names []string
+	for _, sc := range got.All() {
+		names = append(names, sc.Name)
+	}
+	assert.Equal(t, []string{"User", "Base"}, names)
+
+	require.Len(t, exported, 1)
+	fact, ok := exported[0].(*stst.PackageSchemas)
+	require.True(t, ok)
+	assert.Equal(t, "example.com/model", fact.Path)
+
+	// facts are gob encoded to be passed across packages
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(fact))
+	decoded := new(stst.PackageSchemas)
+	require.NoError(t, gob.NewDecoder(&buf).Decode(decoded))
+	assert.Equal(t, fact, decoded)
+}
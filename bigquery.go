@@ -0,0 +1,329 @@
+package stst
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// BigQueryField is a field of BigQuery table schema.
+	// It is marshaled into the JSON format used by `bq` command.
+	BigQueryField struct {
+		Name   string           `json:"name"`
+		Type   BigQueryType     `json:"type"`
+		Mode   BigQueryMode     `json:"mode,omitempty"`
+		Fields []*BigQueryField `json:"fields,omitempty"`
+	}
+
+	BigQueryType string
+	BigQueryMode string
+
+	// BigQueryGenerator generates BigQuery table schema from Schema.
+	// Types are inferred in the same way as `bigquery.InferSchema` of cloud.google.com/go/bigquery.
+	// Fields are REQUIRED unless they are nullable types like bigquery.NullString or tagged `nullable`,
+	// pointers are only allowed for structs and *big.Rat (NUMERIC), and map[string]interface{} is JSON.
+	BigQueryGenerator struct {
+		idx *Index
+	}
+)
+
+const (
+	BigQueryTypeString    = BigQueryType("STRING")
+	BigQueryTypeBytes     = BigQueryType("BYTES")
+	BigQueryTypeInteger   = BigQueryType("INTEGER")
+	BigQueryTypeFloat     = BigQueryType("FLOAT")
+	BigQueryTypeBoolean   = BigQueryType("BOOLEAN")
+	BigQueryTypeTimestamp = BigQueryType("TIMESTAMP")
+	BigQueryTypeDate      = BigQueryType("DATE")
+	BigQueryTypeTime      = BigQueryType("TIME")
+	BigQueryTypeDateTime  = BigQueryType("DATETIME")
+	BigQueryTypeNumeric   = BigQueryType("NUMERIC")
+	BigQueryTypeGeography = BigQueryType("GEOGRAPHY")
+	BigQueryTypeJSON      = BigQueryType("JSON")
+	BigQueryTypeRecord    = BigQueryType("RECORD")
+
+	BigQueryModeNullable = BigQueryMode("NULLABLE")
+	BigQueryModeRequired = BigQueryMode("REQUIRED")
+	BigQueryModeRepeated = BigQueryMode("REPEATED")
+
+	bigQueryTagKey         = "bigquery"
+	bigQueryNullableOption = "nullable"
+)
+
+// types which are treated specially by cloud.google.com/go/bigquery
+var bigQueryKnownTypes = map[UnderlyingType]struct {
+	typ      BigQueryType
+	nullable bool
+}{
+	"time.Time":                                  {typ: BigQueryTypeTimestamp},
+	"cloud.google.com/go/civil.Date":             {typ: BigQueryTypeDate},
+	"cloud.google.com/go/civil.Time":             {typ: BigQueryTypeTime},
+	"cloud.google.com/go/civil.DateTime":         {typ: BigQueryTypeDateTime},
+	"cloud.google.com/go/bigquery.NullString":    {typ: BigQueryTypeString, nullable: true},
+	"cloud.google.com/go/bigquery.NullInt64":     {typ: BigQueryTypeInteger, nullable: true},
+	"cloud.google.com/go/bigquery.NullFloat64":   {typ: BigQueryTypeFloat, nullable: true},
+	"cloud.google.com/go/bigquery.NullBool":      {typ: BigQueryTypeBoolean, nullable: true},
+	"cloud.google.com/go/bigquery.NullTimestamp": {typ: BigQueryTypeTimestamp, nullable: true},
+	"cloud.google.com/go/bigquery.NullDate":      {typ: BigQueryTypeDate, nullable: true},
+	"cloud.google.com/go/bigquery.NullTime":      {typ: BigQueryTypeTime, nullable: true},
+	"cloud.google.com/go/bigquery.NullDateTime":  {typ: BigQueryTypeDateTime, nullable: true},
+	"cloud.google.com/go/bigquery.NullGeography": {typ: BigQueryTypeGeography, nullable: true},
+	"cloud.google.com/go/bigquery.NullJSON":      {typ: BigQueryTypeJSON, nullable: true},
+}
+
+// NewBigQueryGenerator returns BigQueryGenerator.
+// The schemas are used to resolve named types referenced by fields.
+func NewBigQueryGenerator(schemas []*Schema) *BigQueryGenerator {
+	return &BigQueryGenerator{
+		idx: NewIndex(schemas),
+	}
+}
+
+// Generate returns fields of BigQuery table schema for the struct Schema.
+func (g *BigQueryGenerator) Generate(sc *Schema) ([]*BigQueryField, error) {
+	if sc == nil || !sc.IsStruct() {
+		return nil, fmt.Errorf("bigquery: schema must be struct")
+	}
+	out, err := g.fields(sc.Fields, map[*Schema]bool{sc: true})
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: %s: %w", sc.Name, err)
+	}
+	return out, nil
+}
+
+func (g *BigQueryGenerator) fields(fields []*Field, visiting map[*Schema]bool) ([]*BigQueryField, error) {
+	var out []*BigQueryField
+	for _, f := range fields {
+		var tag *Tag
+		if t, ok := f.Tag(bigQueryTagKey); ok {
+			tag = t
+		}
+		if tag != nil && tag.Name() == "-" {
+			continue
+		}
+
+		name := f.Name
+		if tag != nil && tag.Name() != "" {
+			name = tag.Name()
+		} else if f.IsEmbedded && len(f.TypePrefixes) == 0 {
+			// fields of embedded struct are promoted
+			if emb, ok := g.idx.Lookup(f.Type); ok && emb.IsStruct() {
+				if visiting[emb] {
+					return nil, fmt.Errorf("recursive type: %s", emb.Name)
+				}
+				visiting[emb] = true
+				promoted, err := g.fields(emb.Fields, visiting)
+				delete(visiting, emb)
+				if err != nil {
+					return nil, err
+				}
+				out = appendBigQueryFields(out, promoted)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		bf, err := g.field(f, tag != nil && tag.HasOption(bigQueryNullableOption), visiting)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		bf.Name = name
+		out = appendBigQueryFields(out, []*BigQueryField{bf})
+	}
+	return out, nil
+}
+
+func (g *BigQueryGenerator) field(f *Field, nullableTag bool, visiting map[*Schema]bool) (*BigQueryField, error) {
+	prefixes := f.TypePrefixes
+	out := &BigQueryField{Mode: BigQueryModeRequired}
+	var isBytes bool
+	if n := len(prefixes); n > 0 && prefixes[n-1] == TypePrefixSlice && f.Type != nil && isByte(f.Type) {
+		isBytes = true
+		prefixes = prefixes[:n-1]
+	}
+
+	var repeated, ptr bool
+	for i, pref := range prefixes {
+		switch pref.Kind() {
+		case TypePrefixKindPtr:
+			if ptr {
+				return nil, fmt.Errorf("pointer to pointer is not supported")
+			}
+			ptr = true
+		case TypePrefixKindSlice, TypePrefixKindArray:
+			if i != 0 {
+				if ptr {
+					return nil, fmt.Errorf("pointer to slice is not supported")
+				}
+				return nil, fmt.Errorf("nested repeated is not supported")
+			}
+			repeated = true
+		}
+	}
+	if repeated {
+		if nullableTag {
+			return nil, fmt.Errorf("nullable option on repeated field")
+		}
+		out.Mode = BigQueryModeRepeated
+	}
+
+	switch {
+	case isBytes:
+		if ptr {
+			return nil, fmt.Errorf("pointer to []byte is not supported")
+		}
+		out.Type = BigQueryTypeBytes
+		return bigQueryNullable(out, true, nullableTag)
+	case f.IsMap():
+		// map[string]interface{} is JSON
+		if ptr || !isBigQueryJSONMap(f.Map) {
+			return nil, fmt.Errorf("unsupported map")
+		}
+		out.Type = BigQueryTypeJSON
+		return bigQueryNullable(out, false, nullableTag)
+	case f.IsUntitledStruct:
+		var fields []*Field
+		if f.Schema != nil {
+			fields = f.Schema.Fields
+		}
+		if _, err := g.record(out, fields, visiting); err != nil {
+			return nil, err
+		}
+		return bigQueryNullable(out, ptr, nullableTag)
+	case f.Type == nil:
+		return nil, fmt.Errorf("unsupported type")
+	}
+
+	if f.Type.Underlying == "math/big.Rat" {
+		// only *big.Rat is NUMERIC
+		if !ptr {
+			return nil, fmt.Errorf("unsupported type: big.Rat, use *big.Rat")
+		}
+		out.Type = BigQueryTypeNumeric
+		return bigQueryNullable(out, true, nullableTag)
+	}
+	if ptr {
+		if sc, ok := g.idx.Lookup(f.Type); !ok || !sc.IsStruct() || len(sc.TypePrefixes) != 0 {
+			return nil, fmt.Errorf("pointer to %s is not supported", f.Type.Underlying)
+		}
+	}
+	if known, ok := bigQueryKnownTypes[f.Type.Underlying]; ok {
+		out.Type = known.typ
+		if known.nullable {
+			if repeated {
+				return nil, fmt.Errorf("repeated %s is not supported", f.Type.Underlying)
+			}
+			out.Mode = BigQueryModeNullable
+		}
+		return bigQueryNullable(out, false, nullableTag)
+	}
+	if f.Type.IsBasic() {
+		typ, err := bigQueryBasicType(f.Type.Underlying)
+		if err != nil {
+			return nil, err
+		}
+		out.Type = typ
+		return bigQueryNullable(out, false, nullableTag)
+	}
+
+	sc, ok := g.idx.Lookup(f.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown type: %s", f.Type.Underlying)
+	}
+	if sc.IsStruct() && len(sc.TypePrefixes) == 0 {
+		if visiting[sc] {
+			return nil, fmt.Errorf("recursive type: %s", sc.Name)
+		}
+		visiting[sc] = true
+		defer delete(visiting, sc)
+		if _, err := g.record(out, sc.Fields, visiting); err != nil {
+			return nil, err
+		}
+		return bigQueryNullable(out, ptr, nullableTag)
+	}
+	if sc.Type != nil && sc.Type.IsBasic() && len(sc.TypePrefixes) == 0 {
+		typ, err := bigQueryBasicType(sc.Type.Underlying)
+		if err != nil {
+			return nil, err
+		}
+		out.Type = typ
+		return bigQueryNullable(out, false, nullableTag)
+	}
+	return nil, fmt.Errorf("unsupported type: %s", f.Type.Underlying)
+}
+
+func (g *BigQueryGenerator) record(out *BigQueryField, fields []*Field, visiting map[*Schema]bool) (*BigQueryField, error) {
+	fs, err := g.fields(fields, visiting)
+	if err != nil {
+		return nil, err
+	}
+	if len(fs) == 0 {
+		return nil, fmt.Errorf("struct has no fields")
+	}
+	out.Type = BigQueryTypeRecord
+	out.Fields = fs
+	return out, nil
+}
+
+// bigQueryNullable makes the field NULLABLE if it is tagged as nullable.
+// Like bigquery.InferSchema, only []byte and pointer to struct can be tagged.
+func bigQueryNullable(out *BigQueryField, allowed, tagged bool) (*BigQueryField, error) {
+	if !tagged {
+		return out, nil
+	}
+	if !allowed {
+		return nil, fmt.Errorf("nullable option is only for []byte and pointer to struct")
+	}
+	out.Mode = BigQueryModeNullable
+	return out, nil
+}
+
+// isBigQueryJSONMap returns whether the map is `map[string]interface{}`.
+func isBigQueryJSONMap(m *Map) bool {
+	if m == nil || m.Key == nil || m.Value == nil || m.Key.Type == nil || len(m.Key.TypePrefixes) != 0 || len(m.Value.TypePrefixes) != 0 {
+		return false
+	}
+	if m.Key.Type.Underlying != "string" {
+		return false
+	}
+	v := m.Value
+	if v.IsUntitledInterface {
+		return v.Schema == nil
+	}
+	return v.Type != nil && (v.Type.Underlying == "any" || v.Type.Underlying == "interface{}")
+}
+
+// appendBigQueryFields appends fields which has not been added yet.
+// Field names are case insensitive in BigQuery.
+func appendBigQueryFields(fields []*BigQueryField, adds []*BigQueryField) []*BigQueryField {
+	for _, add := range adds {
+		dup := false
+		for _, f := range fields {
+			if strings.EqualFold(f.Name, add.Name) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			fields = append(fields, add)
+		}
+	}
+	return fields
+}
+
+func bigQueryBasicType(u UnderlyingType) (BigQueryType, error) {
+	switch u {
+	case "string":
+		return BigQueryTypeString, nil
+	case "bool":
+		return BigQueryTypeBoolean, nil
+	case "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "byte", "rune":
+		return BigQueryTypeInteger, nil
+	case "float32", "float64":
+		return BigQueryTypeFloat, nil
+	}
+	// uint, uint64 and uintptr can overflow INTEGER
+	return "", fmt.Errorf("unsupported type: %s", u)
+}
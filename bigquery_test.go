@@ -0,0 +1,133 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bigQuerySrc = `package data
+
+import (
+	"math/big"
+	"time"
+)
+
+type SampleString string
+
+type Good struct {
+	Name   string
+	Sample SampleString
+}
+
+type Person struct {
+	Name  string ` + "`bigquery:\"name\"`" + `
+	Age   int    ` + "`bigquery:\"age\"`" + `
+	Sex   string ` + "`bigquery:\"-\"`" + `
+	Hobby string ` + "`bigquery:\"hobby\"`" + `
+	Good
+}
+
+type Shop struct {
+	Goods    []*Good ` + "`bigquery:\"goods\"`" + `
+	Owner    *Good
+	Manager  *Good ` + "`bigquery:\"manager,nullable\"`" + `
+	OpenedAt time.Time
+	Raw      []byte ` + "`bigquery:\"raw,nullable\"`" + `
+	Price    *big.Rat
+	Extra    map[string]interface{}
+	Tags     [][]string ` + "`bigquery:\"-\"`" + `
+	memo     string
+}
+
+type Animal struct {
+	Attrs map[string]int
+}
+
+type Note struct {
+	Text *string
+}
+
+type Hobby struct {
+	Name string ` + "`bigquery:\"name,nullable\"`" + `
+}
+
+type Money struct {
+	Amount big.Rat
+}
+`
+
+func TestBigQueryGenerate(t *testing.T) {
+	schemas := parseSource(t, bigQuerySrc)
+	good := []*stst.BigQueryField{
+		{Name: "Name", Type: stst.BigQueryTypeString, Mode: stst.BigQueryModeRequired},
+		{Name: "Sample", Type: stst.BigQueryTypeString, Mode: stst.BigQueryModeRequired},
+	}
+
+	tests := []struct {
+		name    string
+		schema  string
+		want    []*stst.BigQueryField
+		wantErr string
+	}{
+		{
+			name:   "ok: tags and embedded struct",
+			schema: "Person",
+			want: []*stst.BigQueryField{
+				{Name: "name", Type: stst.BigQueryTypeString, Mode: stst.BigQueryModeRequired},
+				{Name: "age", Type: stst.BigQueryTypeInteger, Mode: stst.BigQueryModeRequired},
+				{Name: "hobby", Type: stst.BigQueryTypeString, Mode: stst.BigQueryModeRequired},
+				{Name: "Sample", Type: stst.BigQueryTypeString, Mode: stst.BigQueryModeRequired},
+			},
+		},
+		{
+			name:   "ok: record, repeated, numeric and json",
+			schema: "Shop",
+			want: []*stst.BigQueryField{
+				{Name: "goods", Type: stst.BigQueryTypeRecord, Mode: stst.BigQueryModeRepeated, Fields: good},
+				{Name: "Owner", Type: stst.BigQueryTypeRecord, Mode: stst.BigQueryModeRequired, Fields: good},
+				{Name: "manager", Type: stst.BigQueryTypeRecord, Mode: stst.BigQueryModeNullable, Fields: good},
+				{Name: "OpenedAt", Type: stst.BigQueryTypeTimestamp, Mode: stst.BigQueryModeRequired},
+				{Name: "raw", Type: stst.BigQueryTypeBytes, Mode: stst.BigQueryModeNullable},
+				{Name: "Price", Type: stst.BigQueryTypeNumeric, Mode: stst.BigQueryModeRequired},
+				{Name: "Extra", Type: stst.BigQueryTypeJSON, Mode: stst.BigQueryModeRequired},
+			},
+		},
+		{
+			name:    "ng: map is not supported",
+			schema:  "Animal",
+			wantErr: "bigquery: Animal: field Attrs: unsupported map",
+		},
+		{
+			name:    "ng: pointer to string",
+			schema:  "Note",
+			wantErr: "bigquery: Note: field Text: pointer to string is not supported",
+		},
+		{
+			name:    "ng: nullable string",
+			schema:  "Hobby",
+			wantErr: "bigquery: Hobby: field Name: nullable option is only for []byte and pointer to struct",
+		},
+		{
+			name:    "ng: big.Rat which is not pointer",
+			schema:  "Money",
+			wantErr: "bigquery: Money: field Amount: unsupported type: big.Rat, use *big.Rat",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := stst.NewBigQueryGenerator(schemas)
+			got, err := g.Generate(findSchema(schemas, tt.schema))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package stst
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+type (
+	// Cache stores schemas of packages in a directory to reuse them for unchanged packages.
+	//
+	// Schemas are keyed by the Go toolchain and target platform reported by `go env`, Env and BuildFlags of
+	// packages.Config, options of Parser and contents of Go files of the package and its dependencies
+	// (standard packages are keyed only by the toolchain).
+	Cache struct {
+		Dir string
+		// GoVersion overrides GOVERSION of `go env` in keys if it is not empty
+		GoVersion string
+		// Positions and Layout are passed to Parser
+		Positions bool
+		Layout    bool
+	}
+
+	// CachedPackage is schemas of a package loaded by Cache.
+	CachedPackage struct {
+		Path    string
+		Schemas []*Schema
+		// Hit is true if the schemas are from the cache
+		Hit bool
+	}
+
+	// cacheBuild is the build environment which is a part of keys.
+	cacheBuild struct {
+		// env is output of `go env` like GOVERSION and GOARCH
+		env string
+		// goroot is GOROOT of `go env` to find standard packages
+		goroot     string
+		cfgEnv     []string
+		buildFlags []string
+	}
+
+	// cacheEntry is gob encoded in the cache file of a package.
+	cacheEntry struct {
+		Key     string
+		Path    string
+		Schemas []*Schema
+	}
+)
+
+// cacheVersion is changed when format of the cache or results of Parser are changed.
+const cacheVersion = 2
+
+const cacheExt = ".gob"
+
+// cacheGoEnv are variables of `go env` which are parts of keys.
+var cacheGoEnv = []string{"GOVERSION", "GOOS", "GOARCH", "GOFLAGS", "GOROOT"}
+
+// NewCache returns Cache whose files are in the directory.
+// The directory is created if it does not exist.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	return &Cache{Dir: dir}, nil
+}
+
+// Load returns schemas of the packages matching the patterns.
+// Packages are listed without type checking at first, and only changed packages are loaded fully and parsed.
+// The Mode and Tests of the cfg are overwritten. Packages with errors are not cached.
+func (c *Cache) Load(cfg *packages.Config, patterns ...string) ([]*CachedPackage, error) {
+	var base packages.Config
+	if cfg != nil {
+		base = *cfg
+	}
+	base.Tests = false
+	bld, err := c.build(&base)
+	if err != nil {
+		return nil, err
+	}
+
+	list := base
+	list.Mode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps
+	roots, err := packages.Load(&list, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("cache: failed to list packages: %w", err)
+	}
+
+	keys := map[string]string{}
+	out := make([]*CachedPackage, len(roots))
+	missed := map[string]int{}
+	var paths []string
+	for i, p := range roots {
+		key, err := c.key(bld, p, keys)
+		if err != nil {
+			return nil, err
+		}
+		if len(p.Errors) == 0 {
+			if schemas, ok := c.get(p.PkgPath, key); ok {
+				out[i] = &CachedPackage{Path: p.PkgPath, Schemas: schemas, Hit: true}
+				continue
+			}
+		}
+		missed[p.PkgPath] = i
+		paths = append(paths, p.PkgPath)
+	}
+	if len(paths) == 0 {
+		return out, nil
+	}
+
+	full := base
+	full.Mode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+		packages.NeedTypes | packages.NeedTypesInfo | packages.NeedTypesSizes
+	pkgs, err := packages.Load(&full, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("cache: failed to load packages: %w", err)
+	}
+	for _, p := range pkgs {
+		i, ok := missed[p.PkgPath]
+		if !ok {
+			continue
+		}
+		schemas := c.parse(p)
+		out[i] = &CachedPackage{Path: p.PkgPath, Schemas: schemas}
+		if len(p.Errors) > 0 {
+			continue
+		}
+		if err := c.put(p.PkgPath, keys[p.PkgPath], schemas); err != nil {
+			return nil, err
+		}
+	}
+	for i, p := range out {
+		if p == nil {
+			out[i] = &CachedPackage{Path: roots[i].PkgPath}
+		}
+	}
+	return out, nil
+}
+
+// Get returns cached schemas of the package if its files are not changed.
+// The package needs PkgPath, GoFiles and Imports (loaded with NeedName, NeedFiles, NeedImports and NeedDeps).
+// Get and Put use the environment of the current process like Load with nil packages.Config.
+func (c *Cache) Get(pkg *packages.Package) ([]*Schema, bool, error) {
+	key, err := c.defaultKey(pkg)
+	if err != nil {
+		return nil, false, err
+	}
+	schemas, ok := c.get(pkg.PkgPath, key)
+	return schemas, ok, nil
+}
+
+// Put stores the schemas of the package.
+func (c *Cache) Put(pkg *packages.Package, schemas []*Schema) error {
+	key, err := c.defaultKey(pkg)
+	if err != nil {
+		return err
+	}
+	return c.put(pkg.PkgPath, key, schemas)
+}
+
+// Invalidate removes cached schemas of the packages.
+func (c *Cache) Invalidate(pkgPaths ...string) error {
+	for _, path := range pkgPaths {
+		if err := os.Remove(c.file(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// Clear removes all cached schemas.
+func (c *Cache) Clear() error {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*"+cacheExt))
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("cache: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) parse(pkg *packages.Package) []*Schema {
+	p := NewParser(pkg)
+	p.Positions = c.Positions
+	p.Layout = c.Layout
+	return p.Parse()
+}
+
+// file returns path of the cache file of the package.
+func (c *Cache) file(pkgPath string) string {
+	sum := sha256.Sum256([]byte(pkgPath))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:16])+cacheExt)
+}
+
+// get returns the cached schemas if the key matches.
+// Broken cache files are treated as missing.
+func (c *Cache) get(pkgPath, key string) ([]*Schema, bool) {
+	f, err := os.Open(c.file(pkgPath))
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	var entry cacheEntry
+	if err := gob.NewDecoder(f).Decode(&entry); err != nil {
+		return nil, false
+	}
+	if entry.Key != key || entry.Path != pkgPath {
+		return nil, false
+	}
+	return entry.Schemas, true
+}
+
+// put writes the cache file through a temporary file so that readers do not see partial files.
+func (c *Cache) put(pkgPath, key string, schemas []*Schema) error {
+	tmp, err := os.CreateTemp(c.Dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(&cacheEntry{Key: key, Path: pkgPath, Schemas: schemas}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cache: %s: %w", pkgPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.file(pkgPath)); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	return nil
+}
+
+// build returns the build environment of the cfg.
+// Variables of `go env` are read with Dir and Env of the cfg so that they are the same as packages.Load.
+func (c *Cache) build(cfg *packages.Config) (*cacheBuild, error) {
+	cmd := exec.Command("go", append([]string{"env"}, cacheGoEnv...)...)
+	cmd.Dir = cfg.Dir
+	cmd.Env = cfg.Env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cache: go env: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	vals := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(vals) != len(cacheGoEnv) {
+		return nil, fmt.Errorf("cache: go env: unexpected output: %q", out)
+	}
+	if c.GoVersion != "" {
+		vals[0] = c.GoVersion
+	}
+	var env strings.Builder
+	for i, name := range cacheGoEnv {
+		fmt.Fprintf(&env, "%s=%s\n", name, vals[i])
+	}
+	return &cacheBuild{
+		env:        env.String(),
+		goroot:     vals[len(vals)-1],
+		cfgEnv:     cfg.Env,
+		buildFlags: cfg.BuildFlags,
+	}, nil
+}
+
+// defaultKey returns key of the package with the environment of the current process.
+func (c *Cache) defaultKey(pkg *packages.Package) (string, error) {
+	bld, err := c.build(&packages.Config{})
+	if err != nil {
+		return "", err
+	}
+	return c.key(bld, pkg, map[string]string{})
+}
+
+// key returns hash of the build environment, options and files of the package and its dependencies.
+// Keys are memoized by package paths.
+func (c *Cache) key(bld *cacheBuild, pkg *packages.Package, memo map[string]string) (string, error) {
+	if key, ok := memo[pkg.PkgPath]; ok {
+		return key, nil
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "stst cache %d\n%s%q\n%q\n", cacheVersion, bld.env, bld.cfgEnv, bld.buildFlags)
+	fmt.Fprintf(h, "%s\npositions=%t layout=%t\n", pkg.PkgPath, c.Positions, c.Layout)
+	if !isGoRoot(bld.goroot, pkg) {
+		for _, name := range pkg.GoFiles {
+			if err := hashFile(h, name); err != nil {
+				return "", fmt.Errorf("cache: %s: %w", pkg.PkgPath, err)
+			}
+		}
+	}
+	imports := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		key, err := c.key(bld, pkg.Imports[path], memo)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "import %s %s\n", path, key)
+	}
+	key := hex.EncodeToString(h.Sum(nil))
+	memo[pkg.PkgPath] = key
+	return key, nil
+}
+
+func hashFile(w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fh := sha256.New()
+	if _, err := io.Copy(fh, f); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "file %s %x\n", name, fh.Sum(nil))
+	return err
+}
+
+// isGoRoot returns whether the package is in the GOROOT (standard packages).
+func isGoRoot(goroot string, pkg *packages.Package) bool {
+	if goroot == "" || len(pkg.GoFiles) == 0 {
+		return false
+	}
+	return strings.HasPrefix(pkg.GoFiles[0], filepath.Join(goroot, "src")+string(filepath.Separator))
+}
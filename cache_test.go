@@ -0,0 +1,202 @@
+package stst_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+const cacheSrc = `package data
+
+import "time"
+
+type (
+	SampleString string
+
+	Good struct {
+		Name      string        ` + "`json:\"name\" db:\"name\"`" + `
+		SamplePtr *SampleString ` + "`json:\"sample_ptr,omitempty\"`" + `
+	}
+
+	Person struct {
+		// comment
+		Name  string ` + "`json:\"name\" bigquery:\"name\"`" + `
+		Age   int    ` + "`json:\"age,omitempty\" bigquery:\"age\"`" + `
+		Sex   string ` + "`json:\"-\" bigquery:\"-\"`" + `
+		Hobby string ` + "`bigquery:\"hobby,nullable\"`" + `
+		Good
+	}
+
+	Animal struct {
+		ID      string         ` + "`json:\"id\" bigquery:\"id\"`" + `
+		Goods   []*Good        ` + "`json:\"goods\"`" + `
+		GoodPtr *Good          ` + "`json:\"good_ptr,omitempty\" bigquery:\"good_ptr\"`" + `
+		Born    time.Time      ` + "`json:\"born\"`" + `
+		Attrs   map[string]int ` + "`json:\"attrs\"`" + `
+		Meta    struct {
+			Note string ` + "`json:\"note\"`" + `
+		} ` + "`json:\"meta\"`" + `
+		strs []string
+		Fn   func(v any)
+	}
+)
+`
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+		return path
+	}
+	depFile := write("dep.go", "package dep\n")
+	pkg := &packages.Package{
+		PkgPath: "example.com/model",
+		GoFiles: []string{write("model.go", "package model\n")},
+		Imports: map[string]*packages.Package{
+			"example.com/dep": {PkgPath: "example.com/dep", GoFiles: []string{depFile}},
+		},
+	}
+
+	c, err := stst.NewCache(filepath.Join(dir, "cache"))
+	require.NoError(t, err)
+	_, ok, err := c.Get(pkg)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	schemas := parseSource(t, cacheSrc)
+	require.NoError(t, c.Put(pkg, schemas))
+	got, ok, err := c.Get(pkg)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, schemas, got)
+
+	// options are parts of keys
+	c.Layout = true
+	_, ok, err = c.Get(pkg)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	c.Layout = false
+
+	// other Go version
+	other := *c
+	other.GoVersion = "go0.0"
+	_, ok, err = other.Get(pkg)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// dependency is changed
+	write("dep.go", "package dep\n\ntype X int\n")
+	_, ok, err = c.Get(pkg)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Put(pkg, schemas))
+	require.NoError(t, c.Invalidate(pkg.PkgPath, "example.com/unknown"))
+	_, ok, err = c.Get(pkg)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Put(pkg, schemas))
+	require.NoError(t, c.Clear())
+	_, ok, err = c.Get(pkg)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	entries, err := os.ReadDir(c.Dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// files can not be read
+	require.NoError(t, os.Remove(depFile))
+	_, _, err = c.Get(pkg)
+	assert.Error(t, err)
+}
+
+func TestCache_Load(t *testing.T) {
+	const path = "github.com/maru44/stst/tests/data/bbb"
+	c, err := stst.NewCache(t.TempDir())
+	require.NoError(t, err)
+
+	// store schemas with the package listed in the same way as Load
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+	}, path)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	schemas := parseSource(t, "package data\n\ntype Cached int\n")
+	require.NoError(t, c.Put(pkgs[0], schemas))
+
+	got, err := c.Load(nil, path)
+	require.NoError(t, err)
+	assert.Equal(t, []*stst.CachedPackage{{Path: path, Schemas: schemas, Hit: true}}, got)
+}
+
+func TestCache_Load_Changed(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	}
+	write("go.mod", "module example.com/cached\n\ngo 1.19\n")
+	write("model/model.go", "package model\n\ntype A struct {\n\tID int\n}\n")
+
+	c, err := stst.NewCache(filepath.Join(dir, "cache"))
+	require.NoError(t, err)
+	cfg := &packages.Config{Dir: dir}
+	load := func(t *testing.T, cfg *packages.Config) *stst.CachedPackage {
+		got, err := c.Load(cfg, "./model")
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, "example.com/cached/model", got[0].Path)
+		return got[0]
+	}
+	names := func(p *stst.CachedPackage) []string {
+		var out []string
+		for _, sc := range p.Schemas {
+			out = append(out, sc.Name)
+		}
+		return out
+	}
+
+	got := load(t, cfg)
+	assert.False(t, got.Hit)
+	assert.Equal(t, []string{"A"}, names(got))
+	got = load(t, cfg)
+	assert.True(t, got.Hit)
+	assert.Equal(t, []string{"A"}, names(got))
+
+	// changed package is parsed again and the result is stored
+	write("model/model.go", "package model\n\ntype A struct {\n\tID int\n}\n\ntype B struct {\n\tName string\n}\n")
+	got = load(t, cfg)
+	assert.False(t, got.Hit)
+	assert.Equal(t, []string{"A", "B"}, names(got))
+	got = load(t, cfg)
+	assert.True(t, got.Hit)
+	assert.Equal(t, []string{"A", "B"}, names(got))
+
+	// Env and BuildFlags are parts of keys
+	for name, other := range map[string]*packages.Config{
+		"Env":        {Dir: dir, Env: append(os.Environ(), "STST_TEST=1")},
+		"BuildFlags": {Dir: dir, BuildFlags: []string{"-tags=stst"}},
+	} {
+		other := other
+		t.Run(name, func(t *testing.T) {
+			got := load(t, other)
+			assert.False(t, got.Hit)
+			assert.Equal(t, []string{"A", "B"}, names(got))
+		})
+	}
+
+	// target platform is a part of keys
+	load(t, cfg)
+	t.Setenv("GOARCH", "386")
+	got = load(t, cfg)
+	assert.False(t, got.Hit)
+	assert.Equal(t, []string{"A", "B"}, names(got))
+}
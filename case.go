@@ -0,0 +1,71 @@
+package stst
+
+import (
+	"strings"
+	"unicode"
+)
+
+// toSnakeCase converts name like `UserID` to `user_id` and `UserIDs` to `user_ids`.
+func toSnakeCase(name string) string {
+	return toDelimitedCase(name, '_')
+}
+
+// toKebabCase converts name like `UserID` to `user-id`.
+func toKebabCase(name string) string {
+	return toDelimitedCase(name, '-')
+}
+
+func toDelimitedCase(name string, delim rune) string {
+	rs := []rune(name)
+	var b strings.Builder
+	for i, r := range rs {
+		if r == '_' || r == '-' || r == ' ' {
+			if b.Len() > 0 && i+1 < len(rs) {
+				b.WriteRune(delim)
+			}
+			continue
+		}
+		if unicode.IsUpper(r) {
+			if i > 0 && b.Len() > 0 {
+				prev := rs[i-1]
+				nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1]) && !isPluralSuffix(rs, i+1)
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteRune(delim)
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// toLowerCamelCase converts name like `UserID` to `userID`, `HTTPServer` to `httpServer` and `IDs` to `ids`.
+func toLowerCamelCase(name string) string {
+	rs := []rune(name)
+	i := 0
+	for i < len(rs) && unicode.IsUpper(rs[i]) {
+		i++
+	}
+	switch {
+	case i == 0:
+		return name
+	case i == 1 || i == len(rs):
+		// `Name` or `ID`
+	case isPluralSuffix(rs, i):
+		// plural of the leading capitals like `URLs` or `IDsByName`
+	case unicode.IsLower(rs[i]):
+		// keep the last upper letter as the head of next word
+		i--
+	}
+	for j := 0; j < i; j++ {
+		rs[j] = unicode.ToLower(rs[j])
+	}
+	return string(rs)
+}
+
+// isPluralSuffix reports whether rs[i] is `s` of plural capitals like `IDs` or `URLsByName`.
+func isPluralSuffix(rs []rune, i int) bool {
+	return rs[i] == 's' && (i+1 == len(rs) || !unicode.IsLower(rs[i+1]))
+}
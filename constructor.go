@@ -0,0 +1,335 @@
+package stst
+
+import (
+	"fmt"
+	"go/token"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// ConstructorGenerator generates constructors and functional options of structs.
+	//
+	// Fields are opted in by `stst` tag.
+	//   - required: field is an argument of the constructor
+	//   - option: field can be set by option function `WithXxx`
+	//
+	// Fields with `default` tag (like `default:"10"`) are set to the value before options are applied.
+	// Structs without required or option fields are skipped.
+	ConstructorGenerator struct {
+		idx *Index
+	}
+
+	ctorField struct {
+		field    *Field
+		required bool
+		option   bool
+		// def is literal of `default` tag
+		def string
+		// defConv is true if def needs conversion to the type of field
+		defConv bool
+		// defConst is true if def is name of constant
+		defConst bool
+	}
+)
+
+const defaultTagKey = "default"
+
+// NewConstructorGenerator returns ConstructorGenerator.
+// The schemas are used to resolve named types of fields with `default` tag.
+func NewConstructorGenerator(schemas []*Schema) *ConstructorGenerator {
+	return &ConstructorGenerator{
+		idx: NewIndex(schemas),
+	}
+}
+
+// Generate returns Go source of constructors for struct schemas.
+// The pkg should be the package of the schemas because the constructors touch unexported fields.
+//
+// For struct `Xxx`, `NewXxx(required args..., opts ...XxxOption) *Xxx` and `WithYyy(v) XxxOption`
+// for each option field `Yyy` are generated.
+// If option names conflict between structs, they are named as `WithXxxYyy`.
+func (g *ConstructorGenerator) Generate(pkg GoPackage, schemas []*Schema) ([]byte, error) {
+	p := newGoTypePrinter(pkg)
+
+	targets := make(map[*Schema][]*ctorField)
+	optionNames := map[string]int{}
+	for _, sc := range schemas {
+		if !sc.IsStruct() || len(sc.TypePrefixes) > 0 {
+			continue
+		}
+		fields, err := g.fields(sc)
+		if err != nil {
+			return nil, fmt.Errorf("constructor: %s: %w", sc.Name, err)
+		}
+		if fields == nil {
+			continue
+		}
+		targets[sc] = fields
+		for _, f := range fields {
+			if f.option {
+				optionNames[f.field.Name]++
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, sc := range schemas {
+		fields, ok := targets[sc]
+		if !ok {
+			continue
+		}
+		if err := g.constructor(&b, p, sc, fields, optionNames); err != nil {
+			return nil, fmt.Errorf("constructor: %s: %w", sc.Name, err)
+		}
+	}
+	out, err := p.file(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("constructor: %w", err)
+	}
+	return out, nil
+}
+
+// fields returns fields handled by the constructor.
+// It returns nil if the struct has neither required nor option fields.
+func (g *ConstructorGenerator) fields(sc *Schema) ([]*ctorField, error) {
+	var out []*ctorField
+	optedIn := false
+	for _, f := range sc.Fields {
+		cf := &ctorField{field: f}
+		opts, err := ststOptions(f)
+		if err != nil {
+			return nil, err
+		}
+		cf.required = opts["required"]
+		cf.option = opts["option"]
+		if tag, ok := f.Tag(defaultTagKey); ok {
+			if cf.required {
+				return nil, fmt.Errorf("field %s: required field can not have default", f.Name)
+			}
+			if err := g.setDefault(cf, tag.RawValue); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+		if cf.required && cf.option {
+			return nil, fmt.Errorf("field %s: field can not be both required and option", f.Name)
+		}
+		if cf.required || cf.option {
+			optedIn = true
+		}
+		if cf.required || cf.option || cf.def != "" {
+			out = append(out, cf)
+		}
+	}
+	if !optedIn {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func (g *ConstructorGenerator) constructor(b *strings.Builder, p *goTypePrinter, sc *Schema, fields []*ctorField, optionNames map[string]int) error {
+	decl, args := typeParamsDecl(sc.TypeParams)
+	typeParams := typeParamNames(sc.TypeParams)
+	srcPkgID := p.pkg.ID
+	if sc.Type != nil && sc.Type.PkgID != "" {
+		srcPkgID = sc.Type.PkgID
+	}
+	typ := sc.Name + args
+	option := sc.Name + "Option"
+
+	fmt.Fprintf(b, "// %s is option of New%s.\n", option, sc.Name)
+	fmt.Fprintf(b, "type %s%s func(*%s)\n\n", option, decl, typ)
+
+	var params, assigns []string
+	used := map[string]bool{"x": true, "opt": true, "opts": true}
+	for _, f := range fields {
+		if !f.required {
+			continue
+		}
+		name := ctorArgName(f.field.Name, used)
+		params = append(params, name+" "+p.field(f.field, srcPkgID, typeParams))
+		assigns = append(assigns, fmt.Sprintf("%s: %s,", f.field.Name, name))
+	}
+	params = append(params, "opts ..."+option+args)
+
+	fmt.Fprintf(b, "// New%s returns %s.\n", sc.Name, sc.Name)
+	fmt.Fprintf(b, "func New%s%s(%s) *%s {\n", sc.Name, decl, strings.Join(params, ", "), typ)
+	fmt.Fprintf(b, "x := &%s{\n%s}\n", typ, joinLines(assigns))
+	for _, f := range fields {
+		if f.def == "" {
+			continue
+		}
+		def := f.def
+		switch {
+		case f.defConst:
+			def = p.qualify(f.field.Type.PkgID) + def
+		case f.defConv || len(f.field.TypePrefixes) > 0:
+			def = p.typeName(f.field.Type, srcPkgID, typeParams) + "(" + def + ")"
+		}
+		if len(f.field.TypePrefixes) > 0 {
+			// only pointer is allowed for default
+			fmt.Fprintf(b, "{\nv := %s\nx.%s = &v\n}\n", def, f.field.Name)
+			continue
+		}
+		fmt.Fprintf(b, "x.%s = %s\n", f.field.Name, def)
+	}
+	b.WriteString("for _, opt := range opts {\nopt(x)\n}\nreturn x\n}\n\n")
+
+	for _, f := range fields {
+		if !f.option {
+			continue
+		}
+		name := "With" + strings.ToUpper(f.field.Name[:1]) + f.field.Name[1:]
+		if optionNames[f.field.Name] > 1 {
+			name = "With" + sc.Name + strings.ToUpper(f.field.Name[:1]) + f.field.Name[1:]
+		}
+		fmt.Fprintf(b, "// %s sets %s of %s.\n", name, f.field.Name, sc.Name)
+		fmt.Fprintf(b, "func %s%s(v %s) %s%s {\n", name, decl, p.field(f.field, srcPkgID, typeParams), option, args)
+		fmt.Fprintf(b, "return func(x *%s) {\nx.%s = v\n}\n}\n\n", typ, f.field.Name)
+	}
+	return nil
+}
+
+// setDefault sets value in `default` tag to the ctorField.
+// Basic types (including named ones), time.Duration and pointers to them are supported.
+func (g *ConstructorGenerator) setDefault(cf *ctorField, raw string) error {
+	f := cf.field
+	if f.IsFunc() || f.IsMap() || f.IsUntitledStruct || f.IsUntitledInterface || f.Type == nil {
+		return fmt.Errorf("default is not supported for the type")
+	}
+	for i, pref := range f.TypePrefixes {
+		if i > 0 || pref.Kind() != TypePrefixKindPtr {
+			return fmt.Errorf("default is not supported for %s", pref)
+		}
+	}
+
+	t := f.Type
+	if t.Underlying == "time.Duration" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+		cf.def = strconv.FormatInt(int64(d), 10)
+		cf.defConv = true
+		return nil
+	}
+
+	underlying := t.Underlying
+	var sc *Schema
+	if !t.IsBasic() {
+		found, ok := g.idx.Lookup(t)
+		if !ok || found.Type == nil || !found.Type.IsBasic() || len(found.TypePrefixes) > 0 {
+			return fmt.Errorf("default is not supported for %s", t.Underlying)
+		}
+		sc = found
+		underlying = found.Type.Underlying
+	} else if t.TypeName != string(t.Underlying) {
+		sc = g.localSchema(t.TypeName)
+	}
+	if sc != nil {
+		// constant of the type like `default:"StatusActive"`
+		for _, c := range sc.Consts {
+			if c.Name == raw {
+				cf.def = raw
+				cf.defConst = true
+				return nil
+			}
+		}
+	}
+
+	lit, err := basicLiteral(underlying, raw)
+	if err != nil {
+		return err
+	}
+	cf.def = lit
+	// conversion is needed for named type
+	cf.defConv = t.TypeName != string(underlying)
+	return nil
+}
+
+// localSchema returns the Schema in the same package whose name is the name.
+func (g *ConstructorGenerator) localSchema(name string) *Schema {
+	for _, sc := range g.idx.Schemas() {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+// basicLiteral returns Go literal of the raw value as the basic type.
+// The parsed value is formatted again because values accepted by strconv (like `t` or `Inf`) are not always Go literals.
+func basicLiteral(u UnderlyingType, raw string) (string, error) {
+	invalid := fmt.Errorf("invalid default for %s: %s", u, raw)
+	switch u {
+	case "string":
+		return strconv.Quote(raw), nil
+	case "bool":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatBool(v), nil
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		v, err := strconv.ParseInt(raw, 0, basicBitSize(u))
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatInt(v, 10), nil
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		v, err := strconv.ParseUint(raw, 0, basicBitSize(u))
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatUint(v, 10), nil
+	case "float32", "float64":
+		bits := basicBitSize(u)
+		v, err := strconv.ParseFloat(raw, bits)
+		// Inf and NaN are not constants in Go
+		if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", invalid
+		}
+		return strconv.FormatFloat(v, 'g', -1, bits), nil
+	}
+	return "", fmt.Errorf("default is not supported for %s", u)
+}
+
+// basicBitSize returns bit size of the numeric type, 64 for int, uint and uintptr.
+func basicBitSize(u UnderlyingType) int {
+	switch u {
+	case "int8", "uint8", "byte":
+		return 8
+	case "int16", "uint16":
+		return 16
+	case "int32", "uint32", "rune", "float32":
+		return 32
+	}
+	return 64
+}
+
+// ctorArgName returns argument name for the field which does not conflict with others.
+func ctorArgName(fieldName string, used map[string]bool) string {
+	name := toLowerCamelCase(fieldName)
+	if token.IsKeyword(name) || used[name] {
+		name += "Arg"
+	}
+	for i := 2; used[name]; i++ {
+		name = toLowerCamelCase(fieldName) + "Arg" + strconv.Itoa(i)
+	}
+	used[name] = true
+	return name
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// GenFilePath returns path of generated file next to the source like `user_gen.go` for `user.go`.
+func GenFilePath(src string) string {
+	return strings.TrimSuffix(src, ".go") + "_gen.go"
+}
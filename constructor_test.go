@@ -0,0 +1,192 @@
+package stst_test
+
+import (
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const constructorSrc = `package data
+
+import "time"
+
+type Good struct {
+	Name string
+}
+
+type Person struct {
+	Name string
+}
+
+type Status int
+
+const StatusActive Status = 1
+
+type Server struct {
+	Addr    string        ` + "`stst:\"required\"`" + `
+	Func    string        ` + "`stst:\"required\"`" + `
+	Port    int           ` + "`stst:\"option\" default:\"8080\"`" + `
+	Timeout time.Duration ` + "`stst:\"option\" default:\"1m30s\"`" + `
+	Status  Status        ` + "`default:\"StatusActive\"`" + `
+	Name    *string       ` + "`stst:\"option\" default:\"srv\"`" + `
+	Good    *Good         ` + "`stst:\"option\"`" + `
+	ignored bool
+}
+
+type Client struct {
+	Port int ` + "`stst:\"option\"`" + `
+}
+`
+
+// constructorSample returns schemas of `type Sample struct` with the field.
+func constructorSample(t *testing.T, field string) []*stst.Schema {
+	t.Helper()
+	return parseSource(t, "package data\n\ntype Sample struct {\n\t"+field+"\n}\n")
+}
+
+func TestConstructorGenerate(t *testing.T) {
+	schemas := parseSource(t, constructorSrc)
+
+	got, err := stst.NewConstructorGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "data_gen.go", got, parser.AllErrors)
+	require.NoError(t, err)
+
+	src := string(got)
+	for _, want := range []string{
+		"import (\n\t\"time\"\n)",
+		"type ServerOption func(*Server)",
+		// keyword is not used as argument name
+		"func NewServer(addr string, funcArg string, opts ...ServerOption) *Server {\n" +
+			"\tx := &Server{\n\t\tAddr: addr,\n\t\tFunc: funcArg,\n\t}\n" +
+			"\tx.Port = 8080\n" +
+			"\tx.Timeout = time.Duration(90000000000)\n" +
+			"\tx.Status = StatusActive\n" +
+			"\t{\n\t\tv := string(\"srv\")\n\t\tx.Name = &v\n\t}\n" +
+			"\tfor _, opt := range opts {\n\t\topt(x)\n\t}\n\treturn x\n}",
+		// Port conflicts with Client
+		"func WithServerPort(v int) ServerOption {\n\treturn func(x *Server) {\n\t\tx.Port = v\n\t}\n}",
+		"func WithClientPort(v int) ClientOption {",
+		"func WithTimeout(v time.Duration) ServerOption {",
+		"func WithGood(v *Good) ServerOption {",
+		"func NewClient(opts ...ClientOption) *Client {",
+	} {
+		assert.Contains(t, src, want)
+	}
+	// structs without stst tag are skipped
+	assert.NotContains(t, src, "NewPerson")
+	assert.NotContains(t, src, "ignored")
+}
+
+func TestConstructorGenerate_Generic(t *testing.T) {
+	schemas := parseSource(t, "package data\n\ntype Box[T any] struct {\n\tValue T `stst:\"required\"`\n\tLabel string `stst:\"option\"`\n}\n")
+	got, err := stst.NewConstructorGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+	require.NoError(t, err)
+
+	src := string(got)
+	assert.Contains(t, src, "type BoxOption[T any] func(*Box[T])")
+	assert.Contains(t, src, "func NewBox[T any](value T, opts ...BoxOption[T]) *Box[T] {")
+	assert.Contains(t, src, "func WithLabel[T any](v string) BoxOption[T] {")
+}
+
+func TestConstructorGenerate_Default(t *testing.T) {
+	tests := []struct {
+		typ  string
+		def  string
+		want string
+	}{
+		{typ: "bool", def: "t", want: "true"},
+		{typ: "bool", def: "T", want: "true"},
+		{typ: "bool", def: "1", want: "true"},
+		{typ: "bool", def: "F", want: "false"},
+		{typ: "int", def: "0x10", want: "16"},
+		{typ: "int8", def: "-128", want: "-128"},
+		{typ: "uint", def: "1_000", want: "1000"},
+		{typ: "float64", def: "1e3", want: "1000"},
+		{typ: "float64", def: "0.5", want: "0.5"},
+		{typ: "float32", def: "0.1", want: "0.1"},
+		{typ: "string", def: "a\"b", want: `"a\"b"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.typ+" "+tt.def, func(t *testing.T) {
+			schemas := constructorSample(t, "A "+tt.typ+" `stst:\"option\" default:"+strconv.Quote(tt.def)+"`")
+			got, err := stst.NewConstructorGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+			require.NoError(t, err)
+			_, err = parser.ParseFile(token.NewFileSet(), "data_gen.go", got, parser.AllErrors)
+			require.NoError(t, err)
+			assert.Contains(t, string(got), "\tx.A = "+tt.want+"\n")
+		})
+	}
+}
+
+func TestConstructorGenerate_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		err   string
+	}{
+		{
+			name:  "unknown option",
+			field: "A int `stst:\"requird\"`",
+			err:   "unknown option of stst tag: requird",
+		},
+		{
+			name:  "required with default",
+			field: "A int `stst:\"required\" default:\"1\"`",
+			err:   "required field can not have default",
+		},
+		{
+			name:  "invalid default",
+			field: "A int `stst:\"option\" default:\"one\"`",
+			err:   "invalid default for int: one",
+		},
+		{
+			name:  "default out of range",
+			field: "A int8 `stst:\"option\" default:\"128\"`",
+			err:   "invalid default for int8: 128",
+		},
+		{
+			name:  "Inf default",
+			field: "A float64 `stst:\"option\" default:\"Inf\"`",
+			err:   "invalid default for float64: Inf",
+		},
+		{
+			name:  "infinity default",
+			field: "A float64 `stst:\"option\" default:\"-infinity\"`",
+			err:   "invalid default for float64: -infinity",
+		},
+		{
+			name:  "NaN default",
+			field: "A float32 `stst:\"option\" default:\"NaN\"`",
+			err:   "invalid default for float32: NaN",
+		},
+		{
+			name:  "float32 overflow",
+			field: "A float32 `stst:\"option\" default:\"1e39\"`",
+			err:   "invalid default for float32: 1e39",
+		},
+		{
+			name:  "default of slice",
+			field: "A []int `default:\"1\"`",
+			err:   "default is not supported for []",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			schemas := constructorSample(t, tt.field)
+			_, err := stst.NewConstructorGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestGenFilePath(t *testing.T) {
+	assert.Equal(t, "tests/data/user_gen.go", stst.GenFilePath("tests/data/user.go"))
+}
@@ -0,0 +1,437 @@
+package stst
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	SQLDialect string
+
+	// DDLGenerator generates `CREATE TABLE` statements from struct Schema with `db` tags.
+	//
+	// The first value of `db` tag is column name and the rest are options.
+	//   - pk: column is (a part of) primary key
+	//   - autoincrement: column is auto incremented, it must be integer and only primary key column in SQLite
+	//   - unique, unique=name: column has unique constraint, columns with same name make composite one
+	//   - index, index=name: column is indexed, columns with same name make composite index
+	//   - size=n: length of VARCHAR
+	//   - type=xxx: column type, overrides inferred type
+	//   - default=xxx: default value
+	//
+	// Columns are NOT NULL unless the field is pointer or nullable type like sql.NullString.
+	// Fields without `db` tag are mapped to snake cased name.
+	DDLGenerator struct {
+		dialect SQLDialect
+		idx     *Index
+	}
+
+	ddlColumn struct {
+		name     string
+		typ      string
+		nullable bool
+		pk       bool
+		autoInc  bool
+		def      string
+	}
+
+	ddlTable struct {
+		name    string
+		columns []*ddlColumn
+		uniques map[string][]string
+		indexes map[string][]string
+	}
+)
+
+const (
+	SQLDialectPostgres = SQLDialect("postgres")
+	SQLDialectMySQL    = SQLDialect("mysql")
+	SQLDialectSQLite   = SQLDialect("sqlite")
+
+	dbTagKey = "db"
+)
+
+// nullable types in database/sql
+var sqlNullTypes = map[UnderlyingType]UnderlyingType{
+	"database/sql.NullString":  "string",
+	"database/sql.NullInt64":   "int64",
+	"database/sql.NullInt32":   "int32",
+	"database/sql.NullInt16":   "int16",
+	"database/sql.NullByte":    "uint8",
+	"database/sql.NullFloat64": "float64",
+	"database/sql.NullBool":    "bool",
+	"database/sql.NullTime":    "time.Time",
+}
+
+// NewDDLGenerator returns DDLGenerator for the dialect.
+// The schemas are used to resolve embedded structs.
+func NewDDLGenerator(dialect SQLDialect, schemas []*Schema) (*DDLGenerator, error) {
+	switch dialect {
+	case SQLDialectPostgres, SQLDialectMySQL, SQLDialectSQLite:
+	default:
+		return nil, fmt.Errorf("ddl: unsupported dialect: %s", dialect)
+	}
+	return &DDLGenerator{
+		dialect: dialect,
+		idx:     NewIndex(schemas),
+	}, nil
+}
+
+// Generate returns DDL of the struct Schema. Table name is snake cased name of the Schema.
+func (g *DDLGenerator) Generate(sc *Schema) (string, error) {
+	if sc == nil {
+		return "", fmt.Errorf("ddl: schema is nil")
+	}
+	return g.GenerateTable(sc, toSnakeCase(sc.Name))
+}
+
+// GenerateTable returns DDL of the struct Schema as the table.
+func (g *DDLGenerator) GenerateTable(sc *Schema, table string) (string, error) {
+	if sc == nil || !sc.IsStruct() {
+		return "", fmt.Errorf("ddl: schema must be struct")
+	}
+	t := &ddlTable{
+		name:    table,
+		uniques: map[string][]string{},
+		indexes: map[string][]string{},
+	}
+	if err := g.addColumns(t, sc.Fields, map[*Schema]bool{sc: true}); err != nil {
+		return "", fmt.Errorf("ddl: %s: %w", sc.Name, err)
+	}
+	if len(t.columns) == 0 {
+		return "", fmt.Errorf("ddl: %s: no columns", sc.Name)
+	}
+	if err := g.checkAutoIncrement(t); err != nil {
+		return "", fmt.Errorf("ddl: %s: %w", sc.Name, err)
+	}
+	return g.write(t), nil
+}
+
+// checkAutoIncrement returns error for autoincrement columns which the dialect can not declare.
+func (g *DDLGenerator) checkAutoIncrement(t *ddlTable) error {
+	var pks int
+	for _, c := range t.columns {
+		if c.pk {
+			pks++
+		}
+	}
+	for _, c := range t.columns {
+		if !c.autoInc {
+			continue
+		}
+		switch g.dialect {
+		case SQLDialectPostgres:
+			if g.columnType(c) == c.typ {
+				return fmt.Errorf("column %s: autoincrement is not supported for %s", c.name, c.typ)
+			}
+		case SQLDialectSQLite:
+			if !c.pk || pks != 1 {
+				return fmt.Errorf("column %s: autoincrement must be the only primary key column", c.name)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *DDLGenerator) addColumns(t *ddlTable, fields []*Field, visiting map[*Schema]bool) error {
+	for _, f := range fields {
+		var tag *Tag
+		if tg, ok := f.Tag(dbTagKey); ok {
+			tag = tg
+		}
+		if tag != nil && tag.Name() == "-" {
+			continue
+		}
+		if f.IsEmbedded && (tag == nil || tag.Name() == "") {
+			emb, ok := g.idx.Lookup(f.Type)
+			if ok && emb.IsStruct() {
+				if visiting[emb] {
+					return fmt.Errorf("recursive type: %s", emb.Name)
+				}
+				visiting[emb] = true
+				err := g.addColumns(t, emb.Fields, visiting)
+				delete(visiting, emb)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		col := &ddlColumn{
+			name: toSnakeCase(f.Name),
+		}
+		if tag != nil && tag.Name() != "" {
+			col.name = tag.Name()
+		}
+		opts := ddlOptions(tag)
+		if err := g.setType(col, f, opts); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if _, ok := opts["pk"]; ok {
+			col.pk = true
+			col.nullable = false
+		}
+		if _, ok := opts["autoincrement"]; ok {
+			col.autoInc = true
+		}
+		if v, ok := opts["default"]; ok {
+			col.def = v
+		}
+		if v, ok := opts["unique"]; ok {
+			if v == "" {
+				v = fmt.Sprintf("uq_%s_%s", t.name, col.name)
+			}
+			t.uniques[v] = append(t.uniques[v], col.name)
+		}
+		if v, ok := opts["index"]; ok {
+			if v == "" {
+				v = fmt.Sprintf("idx_%s_%s", t.name, col.name)
+			}
+			t.indexes[v] = append(t.indexes[v], col.name)
+		}
+		t.columns = append(t.columns, col)
+	}
+	return nil
+}
+
+func (g *DDLGenerator) setType(col *ddlColumn, f *Field, opts map[string]string) error {
+	prefixes := f.TypePrefixes
+	typ := f.Type
+	if f.Type == nil {
+		if v, ok := opts["type"]; ok {
+			col.typ = v
+			return nil
+		}
+		return fmt.Errorf("unsupported type")
+	}
+
+	u := typ.Underlying
+	if n := len(prefixes); n > 0 && prefixes[n-1] == TypePrefixSlice && isByte(typ) {
+		u = "[]byte"
+		prefixes = prefixes[:n-1]
+	}
+	for _, pref := range prefixes {
+		if pref.Kind() != TypePrefixKindPtr {
+			if v, ok := opts["type"]; ok {
+				col.typ = v
+				return nil
+			}
+			return fmt.Errorf("slice or array is not supported")
+		}
+		col.nullable = true
+	}
+	if nu, ok := sqlNullTypes[u]; ok {
+		u = nu
+		col.nullable = true
+	} else if !typ.IsBasic() && u != "time.Time" {
+		// named basic type like `type Status int` defined in other package
+		if sc, ok := g.idx.Lookup(typ); ok && sc.Type != nil && sc.Type.IsBasic() && len(sc.TypePrefixes) == 0 {
+			u = sc.Type.Underlying
+		}
+	}
+
+	if v, ok := opts["type"]; ok {
+		col.typ = v
+		return nil
+	}
+	st, ok := g.sqlType(u, opts["size"])
+	if !ok {
+		return fmt.Errorf("unsupported type: %s", typ.Underlying)
+	}
+	col.typ = st
+	return nil
+}
+
+func (g *DDLGenerator) sqlType(u UnderlyingType, size string) (string, bool) {
+	switch g.dialect {
+	case SQLDialectPostgres:
+		switch u {
+		case "string":
+			if size != "" {
+				return "VARCHAR(" + size + ")", true
+			}
+			return "TEXT", true
+		case "bool":
+			return "BOOLEAN", true
+		case "int8", "int16", "uint8", "byte":
+			return "SMALLINT", true
+		case "int32", "uint16", "rune":
+			return "INTEGER", true
+		case "int", "int64", "uint32":
+			return "BIGINT", true
+		case "uint", "uint64":
+			// BIGINT overflows above math.MaxInt64
+			return "NUMERIC(20)", true
+		case "float32":
+			return "REAL", true
+		case "float64":
+			return "DOUBLE PRECISION", true
+		case "[]byte":
+			return "BYTEA", true
+		case "time.Time":
+			return "TIMESTAMP WITH TIME ZONE", true
+		}
+	case SQLDialectMySQL:
+		switch u {
+		case "string":
+			if size == "" {
+				size = "255"
+			}
+			return "VARCHAR(" + size + ")", true
+		case "bool":
+			return "BOOLEAN", true
+		case "int8":
+			return "TINYINT", true
+		case "uint8", "byte":
+			return "TINYINT UNSIGNED", true
+		case "int16":
+			return "SMALLINT", true
+		case "uint16":
+			return "SMALLINT UNSIGNED", true
+		case "int32", "rune":
+			return "INT", true
+		case "uint32":
+			return "INT UNSIGNED", true
+		case "int", "int64":
+			return "BIGINT", true
+		case "uint", "uint64":
+			return "BIGINT UNSIGNED", true
+		case "float32":
+			return "FLOAT", true
+		case "float64":
+			return "DOUBLE", true
+		case "[]byte":
+			return "BLOB", true
+		case "time.Time":
+			return "DATETIME(6)", true
+		}
+	case SQLDialectSQLite:
+		switch u {
+		case "string":
+			return "TEXT", true
+		case "bool", "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
+			return "INTEGER", true
+		case "float32", "float64":
+			return "REAL", true
+		case "[]byte":
+			return "BLOB", true
+		case "time.Time":
+			return "DATETIME", true
+		}
+	}
+	return "", false
+}
+
+func (g *DDLGenerator) write(t *ddlTable) string {
+	var pks []string
+	for _, c := range t.columns {
+		if c.pk {
+			pks = append(pks, c.name)
+		}
+	}
+	// SQLite requires AUTOINCREMENT to be declared in column definition of INTEGER PRIMARY KEY
+	inlinePK := g.dialect == SQLDialectSQLite && len(pks) == 1
+
+	var lines []string
+	for _, c := range t.columns {
+		line := g.quote(c.name) + " " + g.columnType(c)
+		if inlinePK && c.pk {
+			line += " PRIMARY KEY"
+			if c.autoInc {
+				line += " AUTOINCREMENT"
+			}
+		} else if !c.nullable {
+			line += " NOT NULL"
+		}
+		if c.autoInc && g.dialect == SQLDialectMySQL {
+			line += " AUTO_INCREMENT"
+		}
+		if c.def != "" {
+			line += " DEFAULT " + c.def
+		}
+		lines = append(lines, line)
+	}
+	if len(pks) > 0 && !inlinePK {
+		lines = append(lines, "PRIMARY KEY ("+g.quoteAll(pks)+")")
+	}
+	for _, name := range sortedKeys(t.uniques) {
+		lines = append(lines, "CONSTRAINT "+g.quote(name)+" UNIQUE ("+g.quoteAll(t.uniques[name])+")")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n", g.quote(t.name))
+	for i, l := range lines {
+		b.WriteString("    " + l)
+		if i != len(lines)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(");\n")
+	for _, name := range sortedKeys(t.indexes) {
+		fmt.Fprintf(&b, "CREATE INDEX %s ON %s (%s);\n", g.quote(name), g.quote(t.name), g.quoteAll(t.indexes[name]))
+	}
+	return b.String()
+}
+
+func (g *DDLGenerator) columnType(c *ddlColumn) string {
+	if c.autoInc && g.dialect == SQLDialectPostgres {
+		switch c.typ {
+		case "SMALLINT":
+			return "SMALLSERIAL"
+		case "INTEGER":
+			return "SERIAL"
+		case "BIGINT":
+			return "BIGSERIAL"
+		}
+	}
+	return c.typ
+}
+
+func (g *DDLGenerator) quote(name string) string {
+	if g.dialect == SQLDialectMySQL {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+func (g *DDLGenerator) quoteAll(names []string) string {
+	qs := make([]string, len(names))
+	for i, n := range names {
+		qs[i] = g.quote(n)
+	}
+	return strings.Join(qs, ", ")
+}
+
+// ddlOptions returns options of `db` tag as map.
+// Option without value like `pk` has empty string as its value.
+func ddlOptions(tag *Tag) map[string]string {
+	out := map[string]string{}
+	if tag == nil || len(tag.Values) < 2 {
+		return out
+	}
+	for _, v := range tag.Values[1:] {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) == 2 {
+			out[kv[0]] = kv[1]
+			continue
+		}
+		out[kv[0]] = ""
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,222 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDLGenerate(t *testing.T) {
+	schemas := []*stst.Schema{
+		{
+			Name: "Model",
+			Type: namedType("Model"),
+			Fields: []*stst.Field{
+				{
+					Name: "ID",
+					Type: basicType("int64"),
+					Tags: []*stst.Tag{tag("db", "id", "pk", "autoincrement")},
+				},
+				{
+					Name: "CreatedAt",
+					Type: timeType(),
+					Tags: []*stst.Tag{tag("db", "created_at")},
+				},
+			},
+		},
+		{
+			Name: "UserAccount",
+			Type: namedType("UserAccount"),
+			Fields: []*stst.Field{
+				{
+					Name:       "Model",
+					Type:       namedType("Model"),
+					IsEmbedded: true,
+				},
+				{
+					Name: "Email",
+					Type: basicType("string"),
+					Tags: []*stst.Tag{tag("db", "email", "unique", "size=320")},
+				},
+				{
+					Name: "Nickname",
+					Type: &stst.Type{Underlying: "database/sql.NullString", PkgID: "database/sql", PkgPlusName: "sql.NullString", TypeName: "NullString"},
+					Tags: []*stst.Tag{tag("db", "nickname", "index=idx_name")},
+				},
+				{
+					Name:         "Age",
+					Type:         basicType("int"),
+					TypePrefixes: []stst.TypePrefix{stst.TypePrefixPtr},
+					Tags:         []*stst.Tag{tag("db", "age", "index=idx_name")},
+				},
+				{
+					Name: "IsActive",
+					Type: basicType("bool"),
+					Tags: []*stst.Tag{tag("db", "", "default=true")},
+				},
+				{
+					Name: "Balance",
+					Type: basicType("uint64"),
+					Tags: []*stst.Tag{tag("db", "balance")},
+				},
+				{
+					Name: "Password",
+					Type: basicType("string"),
+					Tags: []*stst.Tag{tag("db", "-")},
+				},
+				{
+					Name: "memo",
+					Type: basicType("string"),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		dialect stst.SQLDialect
+		want    string
+	}{
+		{
+			name:    "ok: postgres",
+			dialect: stst.SQLDialectPostgres,
+			want: `CREATE TABLE "user_account" (
+    "id" BIGSERIAL NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "email" VARCHAR(320) NOT NULL,
+    "nickname" TEXT,
+    "age" BIGINT,
+    "is_active" BOOLEAN NOT NULL DEFAULT true,
+    "balance" NUMERIC(20) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uq_user_account_email" UNIQUE ("email")
+);
+CREATE INDEX "idx_name" ON "user_account" ("nickname", "age");
+`,
+		},
+		{
+			name:    "ok: mysql",
+			dialect: stst.SQLDialectMySQL,
+			want: "CREATE TABLE `user_account` (\n" +
+				"    `id` BIGINT NOT NULL AUTO_INCREMENT,\n" +
+				"    `created_at` DATETIME(6) NOT NULL,\n" +
+				"    `email` VARCHAR(320) NOT NULL,\n" +
+				"    `nickname` VARCHAR(255),\n" +
+				"    `age` BIGINT,\n" +
+				"    `is_active` BOOLEAN NOT NULL DEFAULT true,\n" +
+				"    `balance` BIGINT UNSIGNED NOT NULL,\n" +
+				"    PRIMARY KEY (`id`),\n" +
+				"    CONSTRAINT `uq_user_account_email` UNIQUE (`email`)\n" +
+				");\n" +
+				"CREATE INDEX `idx_name` ON `user_account` (`nickname`, `age`);\n",
+		},
+		{
+			name:    "ok: sqlite",
+			dialect: stst.SQLDialectSQLite,
+			want: `CREATE TABLE "user_account" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME NOT NULL,
+    "email" TEXT NOT NULL,
+    "nickname" TEXT,
+    "age" INTEGER,
+    "is_active" INTEGER NOT NULL DEFAULT true,
+    "balance" INTEGER NOT NULL,
+    CONSTRAINT "uq_user_account_email" UNIQUE ("email")
+);
+CREATE INDEX "idx_name" ON "user_account" ("nickname", "age");
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := stst.NewDDLGenerator(tt.dialect, schemas)
+			require.NoError(t, err)
+			got, err := g.Generate(schemas[1])
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDDLGenerate_ColumnName(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{field: "UserID", want: "user_id"},
+		{field: "UserIDs", want: "user_ids"},
+		{field: "IDs", want: "ids"},
+		{field: "URLs", want: "urls"},
+		{field: "IDsByName", want: "ids_by_name"},
+		{field: "HTTPServer", want: "http_server"},
+		{field: "Status", want: "status"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.field, func(t *testing.T) {
+			sc := &stst.Schema{
+				Name: "Sample",
+				Type: namedType("Sample"),
+				Fields: []*stst.Field{
+					{Name: tt.field, Type: basicType("string")},
+				},
+			}
+			g, err := stst.NewDDLGenerator(stst.SQLDialectPostgres, []*stst.Schema{sc})
+			require.NoError(t, err)
+			got, err := g.Generate(sc)
+			require.NoError(t, err)
+			assert.Equal(t, "CREATE TABLE \"sample\" (\n    \""+tt.want+"\" TEXT NOT NULL\n);\n", got)
+		})
+	}
+}
+
+func TestDDLGenerate_Error(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect stst.SQLDialect
+		fields  []*stst.Field
+		wantErr string
+	}{
+		{
+			name:    "ng: autoincrement with composite primary key in sqlite",
+			dialect: stst.SQLDialectSQLite,
+			fields: []*stst.Field{
+				{Name: "ID", Type: basicType("int64"), Tags: []*stst.Tag{tag("db", "id", "pk", "autoincrement")}},
+				{Name: "Rev", Type: basicType("int64"), Tags: []*stst.Tag{tag("db", "rev", "pk")}},
+			},
+			wantErr: "ddl: Sample: column id: autoincrement must be the only primary key column",
+		},
+		{
+			name:    "ng: autoincrement without primary key in sqlite",
+			dialect: stst.SQLDialectSQLite,
+			fields: []*stst.Field{
+				{Name: "Seq", Type: basicType("int64"), Tags: []*stst.Tag{tag("db", "seq", "autoincrement")}},
+			},
+			wantErr: "ddl: Sample: column seq: autoincrement must be the only primary key column",
+		},
+		{
+			name:    "ng: autoincrement of uint64 in postgres",
+			dialect: stst.SQLDialectPostgres,
+			fields: []*stst.Field{
+				{Name: "ID", Type: basicType("uint64"), Tags: []*stst.Tag{tag("db", "id", "pk", "autoincrement")}},
+			},
+			wantErr: "ddl: Sample: column id: autoincrement is not supported for NUMERIC(20)",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &stst.Schema{Name: "Sample", Type: namedType("Sample"), Fields: tt.fields}
+			g, err := stst.NewDDLGenerator(tt.dialect, []*stst.Schema{sc})
+			require.NoError(t, err)
+			_, err = g.Generate(sc)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
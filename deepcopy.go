@@ -0,0 +1,275 @@
+package stst
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// DeepCopyGenerator generates `DeepCopy()` and `DeepCopyInto(out)` methods of structs.
+	//
+	// Pointers, slices, arrays and maps are copied recursively, and structs in the Index are copied
+	// by their `DeepCopyInto` (so generate them for every package in the Index).
+	// Funcs, interfaces, type parameters and types out of the Index are copied by assignment.
+	// Fields tagged with `stst:"shallow"` are copied by assignment too.
+	DeepCopyGenerator struct {
+		idx         *Index
+		copyMethods map[UnderlyingType]string
+	}
+
+	// deepCopyCtx is context of copying values of a Schema.
+	deepCopyCtx struct {
+		p          *goTypePrinter
+		srcPkgID   string
+		typeParams map[string]bool
+	}
+)
+
+// NewDeepCopyGenerator returns DeepCopyGenerator.
+// The schemas are used to resolve named types of fields.
+//
+// The copyMethods is escape hatch for types which provide their own copy method.
+// It is map from type like `github.com/shopspring/decimal.Decimal` to method name like `Copy`,
+// and the method must return a copy of the same type.
+func NewDeepCopyGenerator(schemas []*Schema, copyMethods map[UnderlyingType]string) *DeepCopyGenerator {
+	return &DeepCopyGenerator{
+		idx:         NewIndex(schemas),
+		copyMethods: copyMethods,
+	}
+}
+
+// Generate returns Go source of deep copy methods for struct schemas.
+// The pkg should be the package of the schemas because deep copy methods are methods.
+func (g *DeepCopyGenerator) Generate(pkg GoPackage, schemas []*Schema) ([]byte, error) {
+	p := newGoTypePrinter(pkg)
+	var b strings.Builder
+	for _, sc := range schemas {
+		if !sc.IsStruct() || len(sc.TypePrefixes) > 0 {
+			continue
+		}
+		if err := g.deepCopy(&b, p, sc); err != nil {
+			return nil, fmt.Errorf("deepcopy: %s: %w", sc.Name, err)
+		}
+	}
+	out, err := p.file(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("deepcopy: %w", err)
+	}
+	return out, nil
+}
+
+func (g *DeepCopyGenerator) deepCopy(b *strings.Builder, p *goTypePrinter, sc *Schema) error {
+	_, args := typeParamsDecl(sc.TypeParams)
+	typ := sc.Name + args
+	ctx := &deepCopyCtx{
+		p:          p,
+		srcPkgID:   p.pkg.ID,
+		typeParams: typeParamNames(sc.TypeParams),
+	}
+	if sc.Type != nil && sc.Type.PkgID != "" {
+		ctx.srcPkgID = sc.Type.PkgID
+	}
+
+	fmt.Fprintf(b, "// DeepCopyInto copies the %s into out.\n", sc.Name)
+	fmt.Fprintf(b, "func (in *%s) DeepCopyInto(out *%s) {\n*out = *in\n", typ, typ)
+	if err := g.fields(b, ctx, sc.Fields); err != nil {
+		return err
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(b, "// DeepCopy returns deep copy of the %s.\n", sc.Name)
+	fmt.Fprintf(b, "func (in *%s) DeepCopy() *%s {\n", typ, typ)
+	fmt.Fprintf(b, "if in == nil {\nreturn nil\n}\nout := new(%s)\nin.DeepCopyInto(out)\nreturn out\n}\n\n", typ)
+	return nil
+}
+
+// fields writes statements to copy the fields of struct which `in` and `out` point to.
+func (g *DeepCopyGenerator) fields(b *strings.Builder, ctx *deepCopyCtx, fields []*Field) error {
+	for _, f := range fields {
+		opts, err := ststOptions(f)
+		if err != nil {
+			return err
+		}
+		if opts["shallow"] || !g.needsDeep(f, f.TypePrefixes, map[*Schema]bool{}) {
+			continue
+		}
+		fmt.Fprintf(b, "{\nin, out := &in.%s, &out.%s\n", f.Name, f.Name)
+		if err := g.value(b, ctx, f, f.TypePrefixes, map[*Schema]bool{}); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		b.WriteString("}\n")
+	}
+	return nil
+}
+
+// value writes statements to copy value which `in` and `out` point to.
+// The value is the Field with the prefixes, and `*out` is already assigned by `*in`.
+func (g *DeepCopyGenerator) value(b *strings.Builder, ctx *deepCopyCtx, f *Field, prefixes []TypePrefix, visiting map[*Schema]bool) error {
+	if !g.needsDeep(f, prefixes, map[*Schema]bool{}) {
+		return nil
+	}
+	if len(prefixes) > 0 {
+		rest := prefixes[1:]
+		elem := ctx.typeOf(f, rest)
+		switch prefixes[0].Kind() {
+		case TypePrefixKindPtr:
+			fmt.Fprintf(b, "if *in != nil {\n*out = new(%s)\n**out = **in\n", elem)
+			if g.needsDeep(f, rest, map[*Schema]bool{}) {
+				b.WriteString("in, out := *in, *out\n")
+				if err := g.value(b, ctx, f, rest, visiting); err != nil {
+					return err
+				}
+			}
+			b.WriteString("}\n")
+		case TypePrefixKindSlice:
+			fmt.Fprintf(b, "if *in != nil {\n*out = make([]%s, len(*in))\ncopy(*out, *in)\n", elem)
+			if g.needsDeep(f, rest, map[*Schema]bool{}) {
+				b.WriteString("for i := range *in {\nin, out := &(*in)[i], &(*out)[i]\n")
+				if err := g.value(b, ctx, f, rest, visiting); err != nil {
+					return err
+				}
+				b.WriteString("}\n")
+			}
+			b.WriteString("}\n")
+		case TypePrefixKindArray:
+			b.WriteString("for i := range *in {\nin, out := &(*in)[i], &(*out)[i]\n")
+			if err := g.value(b, ctx, f, rest, visiting); err != nil {
+				return err
+			}
+			b.WriteString("}\n")
+		default:
+			return fmt.Errorf("unknown type prefix: %s", prefixes[0])
+		}
+		return nil
+	}
+
+	switch {
+	case f.IsMap():
+		if f.Map == nil || f.Map.Key == nil || f.Map.Value == nil {
+			return fmt.Errorf("map without key or value")
+		}
+		val := f.Map.Value
+		fmt.Fprintf(b, "if *in != nil {\nm := make(%s, len(*in))\nfor key, val := range *in {\n", ctx.typeOf(f, nil))
+		if g.needsDeep(val, val.TypePrefixes, map[*Schema]bool{}) {
+			fmt.Fprintf(b, "in, out := &val, new(%s)\n*out = *in\n", ctx.typeOf(val, val.TypePrefixes))
+			if err := g.value(b, ctx, val, val.TypePrefixes, visiting); err != nil {
+				return err
+			}
+			b.WriteString("m[key] = *out\n")
+		} else {
+			b.WriteString("m[key] = val\n")
+		}
+		b.WriteString("}\n*out = m\n}\n")
+		return nil
+	case f.IsUntitledStruct:
+		if f.Schema == nil {
+			return nil
+		}
+		return g.fields(b, ctx, f.Schema.Fields)
+	case f.Type == nil:
+		return nil
+	}
+
+	t := f.Type
+	if method, ok := g.copyMethods[t.Underlying]; ok {
+		fmt.Fprintf(b, "*out = in.%s()\n", method)
+		return nil
+	}
+	sc := g.schema(t, ctx)
+	if sc == nil {
+		return nil
+	}
+	if sc.IsStruct() && len(sc.TypePrefixes) == 0 {
+		b.WriteString("in.DeepCopyInto(out)\n")
+		return nil
+	}
+	if visiting[sc] {
+		return fmt.Errorf("recursive type: %s", sc.Name)
+	}
+	visiting[sc] = true
+	defer delete(visiting, sc)
+
+	// named slice, map or pointer like `type Goods []*Good` is copied as its definition
+	def := &Field{
+		Type:         sc.Type,
+		Map:          sc.Map,
+		TypePrefixes: sc.TypePrefixes,
+	}
+	defCtx := *ctx
+	if sc.Type != nil && sc.Type.PkgID != "" && sc.Type.TypeName != sc.Name {
+		defCtx.srcPkgID = sc.Type.PkgID
+	}
+	return g.value(b, &defCtx, def, def.TypePrefixes, visiting)
+}
+
+// needsDeep returns whether the value of the Field with the prefixes has references to be copied.
+func (g *DeepCopyGenerator) needsDeep(f *Field, prefixes []TypePrefix, visiting map[*Schema]bool) bool {
+	if len(prefixes) > 0 {
+		switch prefixes[0].Kind() {
+		case TypePrefixKindPtr, TypePrefixKindSlice:
+			return true
+		}
+		return g.needsDeep(f, prefixes[1:], visiting)
+	}
+	switch {
+	case f.IsMap():
+		return true
+	case f.IsUntitledStruct:
+		if f.Schema == nil {
+			return false
+		}
+		for _, ff := range f.Schema.Fields {
+			if opts, _ := ststOptions(ff); opts["shallow"] {
+				continue
+			}
+			if g.needsDeep(ff, ff.TypePrefixes, visiting) {
+				return true
+			}
+		}
+		return false
+	case f.IsFunc() || f.IsUntitledInterface || f.Type == nil:
+		return false
+	}
+	if _, ok := g.copyMethods[f.Type.Underlying]; ok {
+		return true
+	}
+	sc := g.schema(f.Type, nil)
+	if sc == nil || visiting[sc] {
+		return false
+	}
+	if sc.IsStruct() && len(sc.TypePrefixes) == 0 {
+		return true
+	}
+	visiting[sc] = true
+	def := &Field{Type: sc.Type, Map: sc.Map}
+	return g.needsDeep(def, sc.TypePrefixes, visiting)
+}
+
+// schema returns the Schema of named type.
+// It returns nil for basic types, type parameters and types out of the Index.
+func (g *DeepCopyGenerator) schema(t *Type, ctx *deepCopyCtx) *Schema {
+	if sc, ok := g.idx.Lookup(t); ok {
+		return sc
+	}
+	if t.PkgID != "" || t.TypeName == string(t.Underlying) {
+		return nil
+	}
+	if ctx != nil && ctx.typeParams[t.TypeName] {
+		return nil
+	}
+	// named type in the same package like `type Status int`
+	for _, sc := range g.idx.Schemas() {
+		if sc.Name == t.TypeName && (sc.Type == nil || sc.Type.PkgID == "") {
+			return sc
+		}
+	}
+	return nil
+}
+
+// typeOf returns type expression of the Field with the prefixes.
+func (c *deepCopyCtx) typeOf(f *Field, prefixes []TypePrefix) string {
+	cp := *f
+	cp.IsVariadic = false
+	cp.TypePrefixes = prefixes
+	return c.p.field(&cp, c.srcPkgID, c.typeParams)
+}
@@ -0,0 +1,190 @@
+package stst_test
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deepCopySrc = `package data
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type SampleString string
+
+type Good struct {
+	Name      string
+	SamplePtr *SampleString
+}
+
+type Animal struct {
+	ID    string
+	Goods []*Good
+	Attrs map[string]int
+}
+
+type Goods []*Good
+
+type Zoo struct {
+	Name string
+	Animal
+	Goods  []*Good
+	timPtr *time.Time
+	tims   [2]time.Time
+	ptrs   [2]*int
+	Index  map[string]*Good
+	Named  Goods
+	Price  decimal.Decimal
+	Shared *Good ` + "`stst:\"shallow\"`" + `
+	Fn     func()
+	Meta   struct {
+		Note string
+		Tags []string
+	}
+}
+`
+
+const deepCopyDecimalSrc = `package decimal
+
+type Decimal struct {
+	value *int
+}
+
+func (d Decimal) Copy() Decimal {
+	return d
+}
+`
+
+func TestDeepCopyGenerate(t *testing.T) {
+	decimal := loadSource(t, "github.com/shopspring/decimal", deepCopyDecimalSrc)
+	schemas := stst.NewParser(loadSource(t, testPkg, deepCopySrc, decimal)).Parse()
+
+	g := stst.NewDeepCopyGenerator(schemas, map[stst.UnderlyingType]string{
+		"github.com/shopspring/decimal.Decimal": "Copy",
+	})
+	got, err := g.Generate(stst.GoPackage{Name: "data", ID: testPkg}, []*stst.Schema{findSchema(schemas, "Zoo")})
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "data_gen.go", got, parser.AllErrors)
+	require.NoError(t, err)
+
+	want := `func (in *Zoo) DeepCopyInto(out *Zoo) {
+	*out = *in
+	{
+		in, out := &in.Animal, &out.Animal
+		in.DeepCopyInto(out)
+	}
+	{
+		in, out := &in.Goods, &out.Goods
+		if *in != nil {
+			*out = make([]*Good, len(*in))
+			copy(*out, *in)
+			for i := range *in {
+				in, out := &(*in)[i], &(*out)[i]
+				if *in != nil {
+					*out = new(Good)
+					**out = **in
+					in, out := *in, *out
+					in.DeepCopyInto(out)
+				}
+			}
+		}
+	}
+	{
+		in, out := &in.timPtr, &out.timPtr
+		if *in != nil {
+			*out = new(time.Time)
+			**out = **in
+		}
+	}
+	{
+		in, out := &in.ptrs, &out.ptrs
+		for i := range *in {
+			in, out := &(*in)[i], &(*out)[i]
+			if *in != nil {
+				*out = new(int)
+				**out = **in
+			}
+		}
+	}
+	{
+		in, out := &in.Index, &out.Index
+		if *in != nil {
+			m := make(map[string]*Good, len(*in))
+			for key, val := range *in {
+				in, out := &val, new(*Good)
+				*out = *in
+				if *in != nil {
+					*out = new(Good)
+					**out = **in
+					in, out := *in, *out
+					in.DeepCopyInto(out)
+				}
+				m[key] = *out
+			}
+			*out = m
+		}
+	}
+	{
+		in, out := &in.Named, &out.Named
+		if *in != nil {
+			*out = make([]*Good, len(*in))
+			copy(*out, *in)
+			for i := range *in {
+				in, out := &(*in)[i], &(*out)[i]
+				if *in != nil {
+					*out = new(Good)
+					**out = **in
+					in, out := *in, *out
+					in.DeepCopyInto(out)
+				}
+			}
+		}
+	}
+	{
+		in, out := &in.Price, &out.Price
+		*out = in.Copy()
+	}
+	{
+		in, out := &in.Meta, &out.Meta
+		{
+			in, out := &in.Tags, &out.Tags
+			if *in != nil {
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+}
+
+// DeepCopy returns deep copy of the Zoo.
+func (in *Zoo) DeepCopy() *Zoo {
+	if in == nil {
+		return nil
+	}
+	out := new(Zoo)
+	in.DeepCopyInto(out)
+	return out
+}
+`
+	assert.Contains(t, string(got), want)
+}
+
+func TestDeepCopyGenerate_Generic(t *testing.T) {
+	schemas := parseSource(t, "package data\n\ntype Gene[T any] struct {\n\tOne  T\n\tMany []T\n}\n")
+	got, err := stst.NewDeepCopyGenerator(schemas, nil).Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+	require.NoError(t, err)
+
+	src := string(got)
+	assert.Contains(t, src, "func (in *Gene[T]) DeepCopyInto(out *Gene[T]) {")
+	assert.Contains(t, src, "*out = make([]T, len(*in))")
+	assert.Contains(t, src, "func (in *Gene[T]) DeepCopy() *Gene[T] {")
+	assert.NotContains(t, src, "&in.One")
+}
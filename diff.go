@@ -0,0 +1,401 @@
+package stst
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+type (
+	// ChangeKind is kind of SchemaChange.
+	ChangeKind string
+
+	// SchemaChange is a change of a type or its member between two versions of schemas.
+	SchemaChange struct {
+		Kind ChangeKind `json:"kind"`
+		// Schema is name of the type
+		Schema string `json:"schema"`
+		// Member is name of the field, method or constant, it is empty for changes of the type itself
+		Member string `json:"member,omitempty"`
+		// Old and New are descriptions of the before and the after like type expression
+		Old      string `json:"old,omitempty"`
+		New      string `json:"new,omitempty"`
+		Breaking bool   `json:"breaking"`
+	}
+
+	// SchemaDiff is difference between two versions of schemas.
+	//
+	// Only exported types and members are compared because others are not part of the API.
+	// Changes are classified as breaking if code using the old version may not compile or work with the new one.
+	//   - removing types, fields, methods and constants is breaking, and adding them is not
+	//   - changing kinds or definitions of types and types of fields is breaking
+	//   - changing or removing values of tags is breaking (serialized formats change), and adding tags is not
+	//   - changing signatures of methods or their receivers from value to pointer is breaking
+	//   - any change of method sets of interfaces is breaking (implementations or callers break)
+	SchemaDiff struct {
+		Changes []*SchemaChange `json:"changes"`
+	}
+
+	// differ compares schemas of two versions.
+	differ struct {
+		p      *goTypePrinter
+		oldIdx *Index
+		newIdx *Index
+		out    []*SchemaChange
+	}
+)
+
+const (
+	ChangeTypeAdded              ChangeKind = "type_added"
+	ChangeTypeRemoved            ChangeKind = "type_removed"
+	ChangeTypeKindChanged        ChangeKind = "type_kind_changed"
+	ChangeTypeChanged            ChangeKind = "type_changed"
+	ChangeTypeParamsChanged      ChangeKind = "type_params_changed"
+	ChangeFieldAdded             ChangeKind = "field_added"
+	ChangeFieldRemoved           ChangeKind = "field_removed"
+	ChangeFieldTypeChanged       ChangeKind = "field_type_changed"
+	ChangeFieldTagChanged        ChangeKind = "field_tag_changed"
+	ChangeMethodAdded            ChangeKind = "method_added"
+	ChangeMethodRemoved          ChangeKind = "method_removed"
+	ChangeMethodChanged          ChangeKind = "method_changed"
+	ChangeInterfaceMethodAdded   ChangeKind = "interface_method_added"
+	ChangeInterfaceMethodRemoved ChangeKind = "interface_method_removed"
+	ChangeInterfaceMethodChanged ChangeKind = "interface_method_changed"
+	ChangeConstAdded             ChangeKind = "const_added"
+	ChangeConstRemoved           ChangeKind = "const_removed"
+	ChangeConstChanged           ChangeKind = "const_changed"
+)
+
+// DiffSchemas returns changes from the old schemas to the new schemas.
+// Both should be parsed from the same package like two checkouts of a repository.
+// Changes are sorted by name of the type.
+func DiffSchemas(old, new []*Schema) *SchemaDiff {
+	d := &differ{
+		// no package so that types of the package are qualified in the same way in both versions
+		p:      newGoTypePrinter(GoPackage{}),
+		oldIdx: NewIndex(old),
+		newIdx: NewIndex(new),
+	}
+	olds, news := exportedSchemas(old), exportedSchemas(new)
+	names := map[string]struct{}{}
+	for n := range olds {
+		names[n] = struct{}{}
+	}
+	for n := range news {
+		names[n] = struct{}{}
+	}
+	for _, n := range sortedKeys(names) {
+		o, nw := olds[n], news[n]
+		switch {
+		case o == nil:
+			d.add(&SchemaChange{Kind: ChangeTypeAdded, Schema: n, New: d.kind(nw)})
+		case nw == nil:
+			d.add(&SchemaChange{Kind: ChangeTypeRemoved, Schema: n, Old: d.kind(o), Breaking: true})
+		default:
+			d.schema(o, nw)
+		}
+	}
+	return &SchemaDiff{Changes: d.out}
+}
+
+// HasBreaking returns whether the diff has breaking changes.
+func (d *SchemaDiff) HasBreaking() bool {
+	return len(d.Breaking()) > 0
+}
+
+// Breaking returns breaking changes.
+func (d *SchemaDiff) Breaking() []*SchemaChange {
+	var out []*SchemaChange
+	for _, c := range d.Changes {
+		if c.Breaking {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// String returns the change like `breaking: Good.Name: field_type_changed: string -> int`.
+func (c *SchemaChange) String() string {
+	var b strings.Builder
+	if c.Breaking {
+		b.WriteString("breaking: ")
+	} else {
+		b.WriteString("non-breaking: ")
+	}
+	b.WriteString(c.Schema)
+	if c.Member != "" {
+		b.WriteString("." + c.Member)
+	}
+	b.WriteString(": " + string(c.Kind))
+	switch {
+	case c.Old != "" && c.New != "":
+		fmt.Fprintf(&b, ": %s -> %s", c.Old, c.New)
+	case c.Old != "":
+		b.WriteString(": " + c.Old)
+	case c.New != "":
+		b.WriteString(": " + c.New)
+	}
+	return b.String()
+}
+
+func exportedSchemas(schemas []*Schema) map[string]*Schema {
+	out := make(map[string]*Schema, len(schemas))
+	for _, sc := range schemas {
+		if token.IsExported(sc.Name) {
+			out[sc.Name] = sc
+		}
+	}
+	return out
+}
+
+func (d *differ) add(c *SchemaChange) {
+	d.out = append(d.out, c)
+}
+
+func (d *differ) schema(o, n *Schema) {
+	if ok, nk := d.kind(o), d.kind(n); ok != nk {
+		d.add(&SchemaChange{Kind: ChangeTypeKindChanged, Schema: n.Name, Old: ok, New: nk, Breaking: true})
+		return
+	}
+	if od, nd := typeParamsString(o.TypeParams), typeParamsString(n.TypeParams); od != nd {
+		d.add(&SchemaChange{Kind: ChangeTypeParamsChanged, Schema: n.Name, Old: od, New: nd, Breaking: true})
+	}
+	switch {
+	case o.IsInterface:
+		d.interfaceMethods(o, n)
+	case o.IsStruct():
+		d.fields(o, n)
+	default:
+		if od, nd := d.definition(o), d.definition(n); od != nd {
+			d.add(&SchemaChange{Kind: ChangeTypeChanged, Schema: n.Name, Old: od, New: nd, Breaking: true})
+		}
+	}
+	d.methods(o, n)
+	d.consts(o, n)
+}
+
+// kind returns kind of the Schema like `struct` or `interface`.
+// Types defined as other types have the same kind `type` and their definitions are compared.
+func (d *differ) kind(sc *Schema) string {
+	switch {
+	case sc.IsInterface:
+		return "interface"
+	case sc.IsStruct():
+		return "struct"
+	}
+	return "type"
+}
+
+// definition returns type expression of the Schema defined as other type like `[]*Good`.
+func (d *differ) definition(sc *Schema) string {
+	f := &Field{Type: sc.Type, TypePrefixes: sc.TypePrefixes, Func: sc.Func, Map: sc.Map}
+	return d.p.field(f, "", typeParamNames(sc.TypeParams))
+}
+
+func (d *differ) fields(o, n *Schema) {
+	tps := typeParamNames(n.TypeParams)
+	olds, oldNames := exportedFields(o.Fields)
+	news, newNames := exportedFields(n.Fields)
+	for _, name := range oldNames {
+		of, nf := olds[name], news[name]
+		if nf == nil {
+			d.add(&SchemaChange{Kind: ChangeFieldRemoved, Schema: n.Name, Member: name, Old: d.p.field(of, "", tps), Breaking: true})
+			continue
+		}
+		if ot, nt := d.p.field(of, "", tps), d.p.field(nf, "", tps); ot != nt || of.IsEmbedded != nf.IsEmbedded {
+			d.add(&SchemaChange{Kind: ChangeFieldTypeChanged, Schema: n.Name, Member: name, Old: ot, New: nt, Breaking: true})
+		}
+		if c := tagChange(of.Tags, nf.Tags); c != nil {
+			c.Schema, c.Member = n.Name, name
+			d.add(c)
+		}
+	}
+	for _, name := range newNames {
+		if olds[name] == nil {
+			d.add(&SchemaChange{Kind: ChangeFieldAdded, Schema: n.Name, Member: name, New: d.p.field(news[name], "", tps)})
+		}
+	}
+}
+
+// exportedFields returns exported fields by name and their names in order.
+func exportedFields(fields []*Field) (map[string]*Field, []string) {
+	out := make(map[string]*Field, len(fields))
+	var names []string
+	for _, f := range fields {
+		if !f.IsExported() {
+			continue
+		}
+		out[f.Name] = f
+		names = append(names, f.Name)
+	}
+	return out, names
+}
+
+// tagChange returns change of tags, it is breaking if values of existing keys are changed or removed.
+func tagChange(old, new []*Tag) *SchemaChange {
+	o, n := tagLiteralOrEmpty(old), tagLiteralOrEmpty(new)
+	if o == n {
+		return nil
+	}
+	c := &SchemaChange{Kind: ChangeFieldTagChanged, Old: o, New: n}
+	values := make(map[string]string, len(new))
+	for _, t := range new {
+		values[t.Key] = t.RawValue
+	}
+	for _, t := range old {
+		if v, ok := values[t.Key]; !ok || v != t.RawValue {
+			c.Breaking = true
+		}
+	}
+	return c
+}
+
+func tagLiteralOrEmpty(tags []*Tag) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return tagLiteral(tags)
+}
+
+func (d *differ) methods(o, n *Schema) {
+	olds, news := exportedMethods(o.Methods), exportedMethods(n.Methods)
+	for _, name := range sortedKeys(olds) {
+		om, nm := olds[name], news[name]
+		if nm == nil {
+			d.add(&SchemaChange{Kind: ChangeMethodRemoved, Schema: n.Name, Member: name, Old: d.method(om), Breaking: true})
+			continue
+		}
+		os, ns := d.method(om), d.method(nm)
+		if os == ns {
+			continue
+		}
+		// value receiver to pointer receiver removes the method from the method set of the value
+		breaking := d.p.signature(om.Func, "", typeParamNames(o.TypeParams)) != d.p.signature(nm.Func, "", typeParamNames(n.TypeParams)) ||
+			!om.IsPointerReceiver && nm.IsPointerReceiver
+		d.add(&SchemaChange{Kind: ChangeMethodChanged, Schema: n.Name, Member: name, Old: os, New: ns, Breaking: breaking})
+	}
+	for _, name := range sortedKeys(news) {
+		if olds[name] == nil {
+			d.add(&SchemaChange{Kind: ChangeMethodAdded, Schema: n.Name, Member: name, New: d.method(news[name])})
+		}
+	}
+}
+
+func exportedMethods(methods []*Method) map[string]*Method {
+	out := make(map[string]*Method, len(methods))
+	for _, m := range methods {
+		if token.IsExported(m.Name) && m.Func != nil {
+			out[m.Name] = m
+		}
+	}
+	return out
+}
+
+// method returns method like `(*) Name(v int) error`, `(*)` means pointer receiver.
+func (d *differ) method(m *Method) string {
+	recv := ""
+	if m.IsPointerReceiver {
+		recv = "(*) "
+	}
+	return recv + m.Name + d.p.signature(m.Func, "", nil)
+}
+
+func (d *differ) interfaceMethods(o, n *Schema) {
+	olds := d.methodSet(d.oldIdx, o, map[*Schema]bool{})
+	news := d.methodSet(d.newIdx, n, map[*Schema]bool{})
+	for _, name := range sortedKeys(olds) {
+		os, ok := news[name]
+		switch {
+		case !ok:
+			d.add(&SchemaChange{Kind: ChangeInterfaceMethodRemoved, Schema: n.Name, Member: name, Old: olds[name], Breaking: true})
+		case os != olds[name]:
+			d.add(&SchemaChange{Kind: ChangeInterfaceMethodChanged, Schema: n.Name, Member: name, Old: olds[name], New: os, Breaking: true})
+		}
+	}
+	for _, name := range sortedKeys(news) {
+		if _, ok := olds[name]; !ok {
+			d.add(&SchemaChange{Kind: ChangeInterfaceMethodAdded, Schema: n.Name, Member: name, New: news[name], Breaking: true})
+		}
+	}
+}
+
+// methodSet returns signatures of methods of the interface including ones of embedded interfaces.
+// Embedded types which are not in the Index are kept as they are like `io.Reader`.
+func (d *differ) methodSet(idx *Index, sc *Schema, seen map[*Schema]bool) map[string]string {
+	out := map[string]string{}
+	if seen[sc] {
+		return out
+	}
+	seen[sc] = true
+	tps := typeParamNames(sc.TypeParams)
+	for _, f := range sc.Fields {
+		if f.IsFunc() && !f.IsEmbedded {
+			out[f.Name] = f.Name + d.p.signature(f.Func, "", tps)
+			continue
+		}
+		if emb := lookupLocal(idx, f.Type); emb != nil && emb.IsInterface {
+			for name, sig := range d.methodSet(idx, emb, seen) {
+				out[name] = sig
+			}
+			continue
+		}
+		// type set like `~int | ~string` or external interface
+		s := d.p.field(f, "", tps)
+		out[s] = s
+	}
+	return out
+}
+
+// lookupLocal returns the Schema of the Type from the Index including types defined as other types.
+func lookupLocal(idx *Index, t *Type) *Schema {
+	if t == nil {
+		return nil
+	}
+	if sc, ok := idx.Lookup(t); ok {
+		return sc
+	}
+	if t.PkgID != "" {
+		return nil
+	}
+	for _, sc := range idx.Schemas() {
+		if sc.Name == t.TypeName && !isSelfType(sc) {
+			return sc
+		}
+	}
+	return nil
+}
+
+func (d *differ) consts(o, n *Schema) {
+	olds := make(map[string]*Const, len(o.Consts))
+	for _, c := range o.Consts {
+		if token.IsExported(c.Name) {
+			olds[c.Name] = c
+		}
+	}
+	news := make(map[string]*Const, len(n.Consts))
+	for _, c := range n.Consts {
+		if token.IsExported(c.Name) {
+			news[c.Name] = c
+		}
+	}
+	for _, c := range o.Consts {
+		nc, ok := news[c.Name]
+		switch {
+		case olds[c.Name] == nil:
+		case !ok:
+			d.add(&SchemaChange{Kind: ChangeConstRemoved, Schema: n.Name, Member: c.Name, Old: c.Value, Breaking: true})
+		case nc.Value != c.Value:
+			d.add(&SchemaChange{Kind: ChangeConstChanged, Schema: n.Name, Member: c.Name, Old: c.Value, New: nc.Value, Breaking: true})
+		}
+	}
+	for _, c := range n.Consts {
+		if news[c.Name] != nil && olds[c.Name] == nil {
+			d.add(&SchemaChange{Kind: ChangeConstAdded, Schema: n.Name, Member: c.Name, New: c.Value})
+		}
+	}
+}
+
+func typeParamsString(tps []*TypeParam) string {
+	decl, _ := typeParamsDecl(tps)
+	return decl
+}
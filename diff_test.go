@@ -0,0 +1,133 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+)
+
+const diffOldSrc = `package data
+
+type (
+	Good struct {
+		Name  string ` + "`json:\"name\"`" + `
+		Price int    ` + "`json:\"price\"`" + `
+		Note  string
+		Stock int
+		cache string
+	}
+
+	Goods []*Good
+
+	Status int
+
+	Reader interface {
+		Read(id string) int
+	}
+
+	ReadCloser interface {
+		Reader
+	}
+
+	Removed struct{}
+
+	Kind struct{}
+
+	unexported struct{}
+)
+
+const (
+	StatusActive   Status = 1
+	StatusInactive Status = 2
+)
+
+func (g Good) Total() int { return g.Price }
+
+func (g Good) Reset() {}
+
+func (g *Good) Label() {}
+
+func (g Good) Close() {}
+`
+
+const diffNewSrc = `package data
+
+type (
+	Good struct {
+		Name  string ` + "`json:\"name\" db:\"name\"`" + `
+		Price int64  ` + "`json:\"amount\"`" + `
+		Stock int
+		Tags  []string
+		cache int
+	}
+
+	Goods []Good
+
+	Status int
+
+	Reader interface {
+		Read(id string) int64
+		Close() error
+	}
+
+	ReadCloser interface {
+		Reader
+	}
+
+	Added struct{}
+
+	Kind interface{}
+)
+
+const (
+	StatusActive  Status = 10
+	StatusDeleted Status = 3
+)
+
+func (g Good) Total() int64 { return g.Price }
+
+func (g *Good) Reset() {}
+
+func (g Good) Label() {}
+
+func (g Good) String() string { return g.Name }
+`
+
+func TestDiffSchemas(t *testing.T) {
+	old := parseSource(t, diffOldSrc)
+	new := parseSource(t, diffNewSrc)
+
+	got := stst.DiffSchemas(old, new)
+	assert.Equal(t, []*stst.SchemaChange{
+		{Kind: stst.ChangeTypeAdded, Schema: "Added", New: "struct"},
+		{Kind: stst.ChangeFieldTagChanged, Schema: "Good", Member: "Name", Old: "`json:\"name\"`", New: "`json:\"name\" db:\"name\"`"},
+		{Kind: stst.ChangeFieldTypeChanged, Schema: "Good", Member: "Price", Old: "int", New: "int64", Breaking: true},
+		{Kind: stst.ChangeFieldTagChanged, Schema: "Good", Member: "Price", Old: "`json:\"price\"`", New: "`json:\"amount\"`", Breaking: true},
+		{Kind: stst.ChangeFieldRemoved, Schema: "Good", Member: "Note", Old: "string", Breaking: true},
+		{Kind: stst.ChangeFieldAdded, Schema: "Good", Member: "Tags", New: "[]string"},
+		{Kind: stst.ChangeMethodRemoved, Schema: "Good", Member: "Close", Old: "Close()", Breaking: true},
+		{Kind: stst.ChangeMethodChanged, Schema: "Good", Member: "Label", Old: "(*) Label()", New: "Label()"},
+		{Kind: stst.ChangeMethodChanged, Schema: "Good", Member: "Reset", Old: "Reset()", New: "(*) Reset()", Breaking: true},
+		{Kind: stst.ChangeMethodChanged, Schema: "Good", Member: "Total", Old: "Total() int", New: "Total() int64", Breaking: true},
+		{Kind: stst.ChangeMethodAdded, Schema: "Good", Member: "String", New: "String() string"},
+		{Kind: stst.ChangeTypeChanged, Schema: "Goods", Old: "[]*data.Good", New: "[]data.Good", Breaking: true},
+		{Kind: stst.ChangeTypeKindChanged, Schema: "Kind", Old: "struct", New: "interface", Breaking: true},
+		{Kind: stst.ChangeInterfaceMethodChanged, Schema: "ReadCloser", Member: "Read", Old: "Read(string) int", New: "Read(string) int64", Breaking: true},
+		{Kind: stst.ChangeInterfaceMethodAdded, Schema: "ReadCloser", Member: "Close", New: "Close() error", Breaking: true},
+		{Kind: stst.ChangeInterfaceMethodChanged, Schema: "Reader", Member: "Read", Old: "Read(string) int", New: "Read(string) int64", Breaking: true},
+		{Kind: stst.ChangeInterfaceMethodAdded, Schema: "Reader", Member: "Close", New: "Close() error", Breaking: true},
+		{Kind: stst.ChangeTypeRemoved, Schema: "Removed", Old: "struct", Breaking: true},
+		{Kind: stst.ChangeConstChanged, Schema: "Status", Member: "StatusActive", Old: "1", New: "10", Breaking: true},
+		{Kind: stst.ChangeConstRemoved, Schema: "Status", Member: "StatusInactive", Old: "2", Breaking: true},
+		{Kind: stst.ChangeConstAdded, Schema: "Status", Member: "StatusDeleted", New: "3"},
+	}, got.Changes)
+	assert.True(t, got.HasBreaking())
+	assert.Len(t, got.Breaking(), 15)
+	assert.Equal(t, "breaking: Good.Price: field_type_changed: int -> int64", got.Changes[2].String())
+	assert.Equal(t, "non-breaking: Added: type_added: struct", got.Changes[0].String())
+
+	same := stst.DiffSchemas(old, parseSource(t, diffOldSrc))
+	assert.Empty(t, same.Changes)
+	assert.False(t, same.HasBreaking())
+}
@@ -0,0 +1,130 @@
+package stst
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+type (
+	// ParallelParser parses packages concurrently with bounded workers.
+	ParallelParser struct {
+		// Workers is the max number of packages parsed at the same time, runtime.GOMAXPROCS(0) if it is 0
+		Workers int
+		// Positions and Layout are passed to Parser
+		Positions bool
+		Layout    bool
+		// Progress is called after each package is parsed.
+		// Calls are sequential, so it does not need to be safe for concurrent use.
+		Progress func(ParseProgress)
+	}
+
+	// ParseProgress is passed to ParallelParser.Progress.
+	ParseProgress struct {
+		// Package is path of the parsed package
+		Package string
+		// Done is the number of parsed packages including this one
+		Done  int
+		Total int
+	}
+
+	// ParsedPackage is schemas of a package parsed by ParallelParser.
+	ParsedPackage struct {
+		Path    string
+		Schemas []*Schema
+	}
+
+	// ParseResult is result of ParallelParser.
+	ParseResult struct {
+		// Packages are in the same order as the given packages regardless of the order of parsing
+		Packages []*ParsedPackage
+		// Index has schemas of all packages in order of Packages.
+		// It is safe for concurrent use because Index is not changed after NewIndex.
+		Index *Index
+	}
+)
+
+// Parse parses the packages and returns their schemas.
+// It stops parsing packages not started yet and returns the error when ctx is done or parsing a package fails.
+// Panics while parsing (like packages loaded without types) are returned as errors.
+func (pp *ParallelParser) Parse(ctx context.Context, pkgs []*packages.Package) (*ParseResult, error) {
+	workers := pp.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(pkgs) {
+		workers = len(pkgs)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	out := make([]*ParsedPackage, len(pkgs))
+	jobs := make(chan int)
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		done     int
+		firstErr error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				schemas, err := pp.parse(pkgs[i])
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				out[i] = &ParsedPackage{Path: pkgs[i].PkgPath, Schemas: schemas}
+				done++
+				if pp.Progress != nil {
+					pp.Progress(ParseProgress{Package: pkgs[i].PkgPath, Done: done, Total: len(pkgs)})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+send:
+	for i := range pkgs {
+		select {
+		case <-cctx.Done():
+			break send
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var all []*Schema
+	for _, p := range out {
+		all = append(all, p.Schemas...)
+	}
+	return &ParseResult{Packages: out, Index: NewIndex(all)}, nil
+}
+
+func (pp *ParallelParser) parse(pkg *packages.Package) (schemas []*Schema, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse: %s: %v", pkg.PkgPath, r)
+		}
+	}()
+	p := NewParser(pkg)
+	p.Positions = pp.Positions
+	p.Layout = pp.Layout
+	return p.Parse(), nil
+}
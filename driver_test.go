@@ -0,0 +1,90 @@
+package stst_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+func driverPackages(t *testing.T, n int) []*packages.Package {
+	pkgs := make([]*packages.Package, n)
+	for i := range pkgs {
+		src := fmt.Sprintf("package p%d\n\ntype T%d struct {\n\tID int64\n\tNext *T%d\n}\n", i, i, i)
+		pkgs[i] = loadSource(t, fmt.Sprintf("example.com/p%d", i), src)
+	}
+	return pkgs
+}
+
+func TestParallelParser(t *testing.T) {
+	pkgs := driverPackages(t, 8)
+	var progress []stst.ParseProgress
+	pp := &stst.ParallelParser{
+		Workers: 3,
+		Layout:  true,
+		Progress: func(p stst.ParseProgress) {
+			progress = append(progress, p)
+		},
+	}
+	got, err := pp.Parse(context.Background(), pkgs)
+	require.NoError(t, err)
+
+	require.Len(t, got.Packages, len(pkgs))
+	for i, p := range got.Packages {
+		assert.Equal(t, fmt.Sprintf("example.com/p%d", i), p.Path)
+		require.Len(t, p.Schemas, 1)
+		assert.Equal(t, fmt.Sprintf("T%d", i), p.Schemas[0].Name)
+		assert.Equal(t, int64(16), p.Schemas[0].Size)
+	}
+
+	require.Len(t, progress, len(pkgs))
+	seen := map[string]bool{}
+	for i, p := range progress {
+		assert.Equal(t, i+1, p.Done)
+		assert.Equal(t, len(pkgs), p.Total)
+		seen[p.Package] = true
+	}
+	assert.Len(t, seen, len(pkgs))
+
+	// Index is read concurrently
+	var wg sync.WaitGroup
+	for _, p := range got.Packages {
+		p := p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sc, ok := got.Index.Lookup(p.Schemas[0].Fields[1].Type)
+			assert.True(t, ok)
+			assert.Same(t, p.Schemas[0], sc)
+		}()
+	}
+	wg.Wait()
+
+	// same result as sequential parsing
+	seq, err := (&stst.ParallelParser{Workers: 1, Layout: true}).Parse(context.Background(), pkgs)
+	require.NoError(t, err)
+	assert.Equal(t, seq.Packages, got.Packages)
+}
+
+func TestParallelParser_Error(t *testing.T) {
+	pkgs := driverPackages(t, 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := (&stst.ParallelParser{}).Parse(ctx, pkgs)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// package without types
+	broken := &packages.Package{PkgPath: "example.com/broken", Syntax: pkgs[0].Syntax}
+	_, err = (&stst.ParallelParser{Workers: 2}).Parse(context.Background(), append(pkgs, broken))
+	assert.ErrorContains(t, err, "example.com/broken")
+
+	got, err := (&stst.ParallelParser{}).Parse(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, got.Packages)
+}
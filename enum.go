@@ -0,0 +1,220 @@
+package stst
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// EnumNaming is how names of enum constants are converted to their string representations.
+	EnumNaming string
+
+	// EnumGenerator generates methods of enum types (types with constants) like stringer.
+	//
+	//   - `String()` returns the name of the value, or `Type(value)` for undefined values
+	//   - `Parse<Type>(s)` returns the value of the name
+	//   - `Values()` returns defined values in order of declaration
+	//   - `MarshalText()` and `UnmarshalText()` use the names
+	//
+	// The name of each constant is converted by EnumNaming.
+	// It can be overridden by a comment of the constant like `//stst:name active`.
+	// Constants with the same value are aliases, String returns the first one and Parse accepts all of them.
+	EnumGenerator struct {
+		naming EnumNaming
+		// trimPrefix trims the type name from names of constants like `StatusActive` to `Active`
+		trimPrefix bool
+	}
+
+	enumValue struct {
+		c    *Const
+		name string
+	}
+)
+
+const (
+	// EnumNamingAsIs uses names of constants as they are like `StatusActive`
+	EnumNamingAsIs EnumNaming = ""
+	// EnumNamingSnake uses snake case like `status_active`
+	EnumNamingSnake EnumNaming = "snake"
+	// EnumNamingKebab uses kebab case like `status-active`
+	EnumNamingKebab EnumNaming = "kebab"
+)
+
+const enumNameDirective = "//stst:name"
+
+// NewEnumGenerator returns EnumGenerator.
+// If trimPrefix is true, the type name is trimmed from names of constants before the naming is applied.
+func NewEnumGenerator(naming EnumNaming, trimPrefix bool) (*EnumGenerator, error) {
+	switch naming {
+	case EnumNamingAsIs, EnumNamingSnake, EnumNamingKebab:
+	default:
+		return nil, fmt.Errorf("unknown enum naming: %s", naming)
+	}
+	return &EnumGenerator{
+		naming:     naming,
+		trimPrefix: trimPrefix,
+	}, nil
+}
+
+// Generate returns Go source of methods for enum schemas.
+// The pkg should be the package of the schemas because they are methods.
+// Methods which the type already has are not generated.
+func (g *EnumGenerator) Generate(pkg GoPackage, schemas []*Schema) ([]byte, error) {
+	p := newGoTypePrinter(pkg)
+	var b strings.Builder
+	for _, sc := range schemas {
+		if !sc.IsEnum() {
+			continue
+		}
+		if err := g.enum(&b, p, sc); err != nil {
+			return nil, fmt.Errorf("enum: %s: %w", sc.Name, err)
+		}
+	}
+	out, err := p.file(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("enum: %w", err)
+	}
+	return out, nil
+}
+
+func (g *EnumGenerator) enum(b *strings.Builder, p *goTypePrinter, sc *Schema) error {
+	if sc.Type == nil || len(sc.TypePrefixes) > 0 || len(sc.TypeParams) > 0 {
+		return fmt.Errorf("enum must be defined as basic type")
+	}
+	kind, ok := enumKind(sc.Type.Underlying)
+	if !ok {
+		return fmt.Errorf("enum of %s is not supported", sc.Type.Underlying)
+	}
+	values, err := g.values(sc)
+	if err != nil {
+		return err
+	}
+
+	// the first constant of each value, aliases are not in String and Values
+	var uniques []*enumValue
+	seen := map[string]bool{}
+	for _, v := range values {
+		if !seen[v.c.Value] {
+			seen[v.c.Value] = true
+			uniques = append(uniques, v)
+		}
+	}
+
+	if _, ok := sc.Method("String"); !ok {
+		fmt.Fprintf(b, "// String returns the name of the %s.\n", sc.Name)
+		fmt.Fprintf(b, "func (x %s) String() string {\nswitch x {\n", sc.Name)
+		for _, v := range uniques {
+			fmt.Fprintf(b, "case %s:\nreturn %q\n", v.c.Name, v.name)
+		}
+		strconv := p.qualify("strconv")
+		switch kind {
+		case "int":
+			fmt.Fprintf(b, "}\nreturn \"%s(\" + %sFormatInt(int64(x), 10) + \")\"\n}\n\n", sc.Name, strconv)
+		case "uint":
+			fmt.Fprintf(b, "}\nreturn \"%s(\" + %sFormatUint(uint64(x), 10) + \")\"\n}\n\n", sc.Name, strconv)
+		case "string":
+			fmt.Fprintf(b, "}\nreturn \"%s(\" + %sQuote(string(x)) + \")\"\n}\n\n", sc.Name, strconv)
+		}
+	}
+
+	parse := "Parse" + sc.Name
+	fmt.Fprintf(b, "// %s returns the %s of the name.\n", parse, sc.Name)
+	fmt.Fprintf(b, "func %s(s string) (%s, error) {\nswitch s {\n", parse, sc.Name)
+	parsed := map[string]bool{}
+	for _, v := range values {
+		if parsed[v.name] {
+			continue
+		}
+		parsed[v.name] = true
+		fmt.Fprintf(b, "case %q:\nreturn %s, nil\n", v.name, v.c.Name)
+	}
+	zero := "0"
+	if kind == "string" {
+		zero = `""`
+	}
+	fmt.Fprintf(b, "}\nreturn %s, %sErrorf(\"invalid %s: %%q\", s)\n}\n\n", zero, p.qualify("fmt"), sc.Name)
+
+	if _, ok := sc.Method("Values"); !ok {
+		names := make([]string, len(uniques))
+		for i, v := range uniques {
+			names[i] = v.c.Name
+		}
+		fmt.Fprintf(b, "// Values returns all values of the %s.\n", sc.Name)
+		fmt.Fprintf(b, "func (%s) Values() []%s {\nreturn []%s{%s}\n}\n\n", sc.Name, sc.Name, sc.Name, strings.Join(names, ", "))
+	}
+
+	if _, ok := sc.Method("MarshalText"); !ok {
+		fmt.Fprintf(b, "// MarshalText returns the name of the %s.\n", sc.Name)
+		fmt.Fprintf(b, "func (x %s) MarshalText() ([]byte, error) {\nreturn []byte(x.String()), nil\n}\n\n", sc.Name)
+	}
+	if _, ok := sc.Method("UnmarshalText"); !ok {
+		fmt.Fprintf(b, "// UnmarshalText sets the %s of the name.\n", sc.Name)
+		fmt.Fprintf(b, "func (x *%s) UnmarshalText(text []byte) error {\nv, err := %s(string(text))\nif err != nil {\nreturn err\n}\n*x = v\nreturn nil\n}\n\n", sc.Name, parse)
+	}
+	return nil
+}
+
+// values returns constants with their names.
+// Different values must not have the same name.
+func (g *EnumGenerator) values(sc *Schema) ([]*enumValue, error) {
+	out := make([]*enumValue, 0, len(sc.Consts))
+	byName := map[string]*Const{}
+	for _, c := range sc.Consts {
+		name, err := g.name(sc, c)
+		if err != nil {
+			return nil, fmt.Errorf("const %s: %w", c.Name, err)
+		}
+		if other, ok := byName[name]; ok && other.Value != c.Value {
+			return nil, fmt.Errorf("const %s: name %q is used by %s", c.Name, name, other.Name)
+		}
+		byName[name] = c
+		out = append(out, &enumValue{c: c, name: name})
+	}
+	return out, nil
+}
+
+// name returns the string representation of the constant.
+func (g *EnumGenerator) name(sc *Schema, c *Const) (string, error) {
+	for _, line := range append(append([]string{}, c.Doc...), c.Comment...) {
+		if !strings.HasPrefix(line, enumNameDirective) {
+			continue
+		}
+		rest := strings.TrimPrefix(line, enumNameDirective)
+		if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			// other directive like `//stst:names`
+			continue
+		}
+		name := strings.TrimSpace(rest)
+		if name == "" {
+			return "", fmt.Errorf("empty name in %s", enumNameDirective)
+		}
+		return name, nil
+	}
+
+	name := c.Name
+	if g.trimPrefix {
+		if trimmed := strings.TrimLeft(strings.TrimPrefix(name, sc.Name), "_"); trimmed != "" {
+			name = trimmed
+		}
+	}
+	switch g.naming {
+	case EnumNamingSnake:
+		name = toSnakeCase(name)
+	case EnumNamingKebab:
+		name = toKebabCase(name)
+	}
+	return name, nil
+}
+
+// enumKind returns kind of the underlying type of enum, which is int, uint or string.
+func enumKind(u UnderlyingType) (string, bool) {
+	switch u {
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		return "int", true
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		return "uint", true
+	case "string":
+		return "string", true
+	}
+	return "", false
+}
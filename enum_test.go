@@ -0,0 +1,173 @@
+package stst_test
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const enumSrc = `package data
+
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	// active status
+	StatusActive
+	StatusInactive //stst:name disabled
+
+	// alias
+	StatusEnabled = StatusActive
+)
+
+type Color string
+
+const (
+	ColorRed       Color = "red"
+	ColorLightBlue Color = "light_blue"
+)
+
+func (c Color) String() string { return string(c) }
+
+type Good struct{}
+`
+
+func TestEnumGenerate(t *testing.T) {
+	g, err := stst.NewEnumGenerator(stst.EnumNamingSnake, true)
+	require.NoError(t, err)
+	got, err := g.Generate(stst.GoPackage{Name: "data", ID: testPkg}, parseSource(t, enumSrc))
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "data_gen.go", got, parser.AllErrors)
+	require.NoError(t, err)
+
+	src := string(got)
+	for _, want := range []string{
+		"func (x Status) String() string {\n\tswitch x {\n\tcase StatusUnknown:\n\t\treturn \"unknown\"\n\tcase StatusActive:\n\t\treturn \"active\"\n\tcase StatusInactive:\n\t\treturn \"disabled\"\n\t}\n\treturn \"Status(\" + strconv.FormatInt(int64(x), 10) + \")\"\n}",
+		"func ParseStatus(s string) (Status, error) {\n\tswitch s {\n\tcase \"unknown\":\n\t\treturn StatusUnknown, nil\n\tcase \"active\":\n\t\treturn StatusActive, nil\n\tcase \"disabled\":\n\t\treturn StatusInactive, nil\n\tcase \"enabled\":\n\t\treturn StatusEnabled, nil\n\t}\n\treturn 0, fmt.Errorf(\"invalid Status: %q\", s)\n}",
+		"func (Status) Values() []Status {\n\treturn []Status{StatusUnknown, StatusActive, StatusInactive}\n}",
+		"func (x Status) MarshalText() ([]byte, error) {\n\treturn []byte(x.String()), nil\n}",
+		"func (x *Status) UnmarshalText(text []byte) error {\n\tv, err := ParseStatus(string(text))",
+		"func ParseColor(s string) (Color, error) {\n\tswitch s {\n\tcase \"red\":\n\t\treturn ColorRed, nil\n\tcase \"light_blue\":\n\t\treturn ColorLightBlue, nil\n\t}\n\treturn \"\", fmt.Errorf(\"invalid Color: %q\", s)\n}",
+	} {
+		assert.Contains(t, src, want)
+	}
+	for _, notWant := range []string{
+		"func (x Color) String()",
+		"Good",
+	} {
+		assert.NotContains(t, src, notWant)
+	}
+}
+
+func TestEnumGenerate_Naming(t *testing.T) {
+	tests := []struct {
+		name       string
+		naming     stst.EnumNaming
+		trimPrefix bool
+		want       string
+	}{
+		{
+			name:   "as is",
+			naming: stst.EnumNamingAsIs,
+			want:   "case \"StatusNotFound\":",
+		},
+		{
+			name:   "snake",
+			naming: stst.EnumNamingSnake,
+			want:   "case \"status_not_found\":",
+		},
+		{
+			name:   "kebab",
+			naming: stst.EnumNamingKebab,
+			want:   "case \"status-not-found\":",
+		},
+		{
+			name:   "snake with plural initialism",
+			naming: stst.EnumNamingSnake,
+			want:   "case \"status_invalid_ids\":",
+		},
+		{
+			name:   "kebab with plural initialism",
+			naming: stst.EnumNamingKebab,
+			want:   "case \"status-invalid-ids\":",
+		},
+		{
+			name:       "trimmed prefix",
+			naming:     stst.EnumNamingAsIs,
+			trimPrefix: true,
+			want:       "case \"NotFound\":",
+		},
+		{
+			name:       "trimmed prefix and kebab",
+			naming:     stst.EnumNamingKebab,
+			trimPrefix: true,
+			want:       "case \"not-found\":",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &stst.Schema{
+				Name:   "Status",
+				Type:   basicType("int"),
+				Consts: []*stst.Const{{Name: "StatusNotFound", Value: "404"}, {Name: "StatusInvalidIDs", Value: "400"}, {Name: "Status", Value: "0"}},
+			}
+			g, err := stst.NewEnumGenerator(tt.naming, tt.trimPrefix)
+			require.NoError(t, err)
+			got, err := g.Generate(stst.GoPackage{Name: "data", ID: testPkg}, []*stst.Schema{sc})
+			require.NoError(t, err)
+			assert.Contains(t, string(got), tt.want)
+		})
+	}
+
+	_, err := stst.NewEnumGenerator("camel", false)
+	assert.Error(t, err)
+}
+
+func TestEnumGenerate_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		sc   *stst.Schema
+	}{
+		{
+			name: "float",
+			sc: &stst.Schema{
+				Name:   "Rate",
+				Type:   basicType("float64"),
+				Consts: []*stst.Const{{Name: "RateHalf", Value: "0.5"}},
+			},
+		},
+		{
+			name: "name conflict",
+			sc: &stst.Schema{
+				Name: "Status",
+				Type: basicType("int"),
+				Consts: []*stst.Const{
+					{Name: "StatusActive", Value: "1"},
+					{Name: "StatusInactive", Value: "2", Comment: []string{"//stst:name StatusActive"}},
+				},
+			},
+		},
+		{
+			name: "empty name",
+			sc: &stst.Schema{
+				Name:   "Status",
+				Type:   basicType("int"),
+				Consts: []*stst.Const{{Name: "StatusActive", Value: "1", Doc: []string{"//stst:name"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := stst.NewEnumGenerator(stst.EnumNamingAsIs, false)
+			require.NoError(t, err)
+			_, err = g.Generate(stst.GoPackage{Name: "data", ID: testPkg}, []*stst.Schema{tt.sc})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,456 @@
+package stst
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// EqualGenerator generates `Equal(other) bool` and `Hash() uint64` methods of structs.
+	//
+	// Fields are compared one by one. Pointers are followed, and slices, arrays and maps are compared
+	// element by element (nil and empty are treated as equal).
+	// Named types are compared by their `Equal` and `Hash` methods if they have them.
+	// Funcs and fields tagged with `stst:"noeq"` are ignored.
+	// Interfaces, type parameters and structs without `Equal` are compared by reflect.DeepEqual
+	// and they are not hashed.
+	//
+	// Hash is stable (it does not depend on iteration order of maps), and equal values have the same hash.
+	EqualGenerator struct {
+		idx *Index
+	}
+
+	equalCtx struct {
+		p          *goTypePrinter
+		typeParams map[string]bool
+		// targets are structs whose Equal and Hash are generated
+		targets map[*Schema]bool
+	}
+
+	equalKind int
+)
+
+const (
+	equalKindSkip equalKind = iota
+	equalKindBasic
+	equalKindDeep
+	equalKindMethod
+	equalKindDef
+)
+
+// methods of well-known types, `%s` are the values
+var equalKnownTypes = map[UnderlyingType]struct {
+	equal string
+	hash  string
+}{
+	"time.Time": {equal: "%s.Equal(%s)", hash: "uint64(%s.UnixNano())"},
+}
+
+// NewEqualGenerator returns EqualGenerator.
+// The schemas are used to resolve named types of fields and their methods.
+func NewEqualGenerator(schemas []*Schema) *EqualGenerator {
+	return &EqualGenerator{
+		idx: NewIndex(schemas),
+	}
+}
+
+// Generate returns Go source of Equal and Hash for struct schemas.
+// The pkg should be the package of the schemas because they are methods.
+// Methods which the struct already has are not generated.
+func (g *EqualGenerator) Generate(pkg GoPackage, schemas []*Schema) ([]byte, error) {
+	ctx := &equalCtx{
+		p:       newGoTypePrinter(pkg),
+		targets: map[*Schema]bool{},
+	}
+	for _, sc := range schemas {
+		if sc.IsStruct() && len(sc.TypePrefixes) == 0 {
+			ctx.targets[sc] = true
+		}
+	}
+
+	var b strings.Builder
+	for _, sc := range schemas {
+		if !ctx.targets[sc] {
+			continue
+		}
+		if err := g.methods(&b, ctx, sc); err != nil {
+			return nil, fmt.Errorf("equal: %s: %w", sc.Name, err)
+		}
+	}
+	out, err := ctx.p.file(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("equal: %w", err)
+	}
+	return out, nil
+}
+
+func (g *EqualGenerator) methods(b *strings.Builder, ctx *equalCtx, sc *Schema) error {
+	_, args := typeParamsDecl(sc.TypeParams)
+	typ := sc.Name + args
+	ctx.typeParams = typeParamNames(sc.TypeParams)
+
+	var fields []*Field
+	for _, f := range sc.Fields {
+		opts, err := ststOptions(f)
+		if err != nil {
+			return err
+		}
+		if opts["noeq"] || f.Name == "_" {
+			continue
+		}
+		fields = append(fields, f)
+	}
+
+	_, hasEqual := sc.Method("Equal")
+	if !hasEqual {
+		fmt.Fprintf(b, "// Equal returns whether the %s and other are equal field by field.\n", sc.Name)
+		fmt.Fprintf(b, "func (x *%s) Equal(other *%s) bool {\n", typ, typ)
+		b.WriteString("if x == nil || other == nil {\nreturn x == other\n}\n")
+		for _, f := range fields {
+			if err := g.equal(b, ctx, f, f.TypePrefixes, "x."+f.Name, "other."+f.Name, 0, map[*Schema]bool{}); err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+		b.WriteString("return true\n}\n\n")
+	}
+
+	// Hash is not generated for custom Equal because it can not be consistent with the Equal
+	if _, ok := sc.Method("Hash"); !ok && !hasEqual {
+		fmt.Fprintf(b, "// Hash returns hash of the %s. Equal values have the same hash.\n", sc.Name)
+		fmt.Fprintf(b, "func (x *%s) Hash() uint64 {\n", typ)
+		b.WriteString("if x == nil {\nreturn 0\n}\n")
+		fmt.Fprintf(b, "h := %sNew64a()\n", ctx.p.qualify("hash/fnv"))
+		for _, f := range fields {
+			if err := g.hash(b, ctx, f, f.TypePrefixes, "x."+f.Name, "h", 0, map[*Schema]bool{}); err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+		b.WriteString("return h.Sum64()\n}\n\n")
+	}
+	return nil
+}
+
+// equal writes statements which return false if the values x and y are not equal.
+func (g *EqualGenerator) equal(b *strings.Builder, ctx *equalCtx, f *Field, prefixes []TypePrefix, x, y string, depth int, visiting map[*Schema]bool) error {
+	if len(prefixes) > 0 {
+		rest := prefixes[1:]
+		var inner strings.Builder
+		switch prefixes[0].Kind() {
+		case TypePrefixKindPtr:
+			if err := g.equal(&inner, ctx, f, rest, "(*"+x+")", "(*"+y+")", depth+1, visiting); err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if (%s == nil) != (%s == nil) {\nreturn false\n}\n", x, y)
+			if inner.Len() > 0 {
+				fmt.Fprintf(b, "if %s != nil {\n%s}\n", x, inner.String())
+			}
+		case TypePrefixKindSlice, TypePrefixKindArray:
+			i := fmt.Sprintf("i%d", depth)
+			if err := g.equal(&inner, ctx, f, rest, x+"["+i+"]", y+"["+i+"]", depth+1, visiting); err != nil {
+				return err
+			}
+			if prefixes[0].Kind() == TypePrefixKindSlice {
+				fmt.Fprintf(b, "if len(%s) != len(%s) {\nreturn false\n}\n", x, y)
+			}
+			if inner.Len() > 0 {
+				fmt.Fprintf(b, "for %s := range %s {\n%s}\n", i, x, inner.String())
+			}
+		default:
+			return fmt.Errorf("unknown type prefix: %s", prefixes[0])
+		}
+		return nil
+	}
+
+	switch {
+	case f.IsMap():
+		if f.Map == nil || f.Map.Key == nil || f.Map.Value == nil {
+			return fmt.Errorf("map without key or value")
+		}
+		k, vx, vy, ok := fmt.Sprintf("k%d", depth), fmt.Sprintf("vx%d", depth), fmt.Sprintf("vy%d", depth), fmt.Sprintf("ok%d", depth)
+		var inner strings.Builder
+		if err := g.equal(&inner, ctx, f.Map.Value, f.Map.Value.TypePrefixes, vx, vy, depth+1, visiting); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "if len(%s) != len(%s) {\nreturn false\n}\n", x, y)
+		if inner.Len() == 0 {
+			fmt.Fprintf(b, "for %s := range %s {\nif _, %s := %s[%s]; !%s {\nreturn false\n}\n}\n", k, x, ok, y, k, ok)
+			return nil
+		}
+		fmt.Fprintf(b, "for %s, %s := range %s {\n%s, %s := %s[%s]\nif !%s {\nreturn false\n}\n%s}\n", k, vx, x, vy, ok, y, k, ok, inner.String())
+		return nil
+	case f.IsUntitledStruct:
+		if f.Schema == nil {
+			return nil
+		}
+		for _, ff := range f.Schema.Fields {
+			if opts, _ := ststOptions(ff); opts["noeq"] || ff.Name == "_" {
+				continue
+			}
+			if err := g.equal(b, ctx, ff, ff.TypePrefixes, x+"."+ff.Name, y+"."+ff.Name, depth, visiting); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	kind, sc := g.kind(ctx, f)
+	switch kind {
+	case equalKindBasic:
+		fmt.Fprintf(b, "if %s != %s {\nreturn false\n}\n", x, y)
+	case equalKindDeep:
+		fmt.Fprintf(b, "if !%sDeepEqual(%s, %s) {\nreturn false\n}\n", ctx.p.qualify("reflect"), x, y)
+	case equalKindMethod:
+		if known, ok := equalKnownTypes[f.Type.Underlying]; ok {
+			fmt.Fprintf(b, "if !"+known.equal+" {\nreturn false\n}\n", x, y)
+			return nil
+		}
+		arg := y
+		if m, ok := sc.Method("Equal"); !ok || len(m.Func.Args[0].TypePrefixes) > 0 {
+			// generated Equal receives pointer
+			arg = addrOf(y)
+		}
+		fmt.Fprintf(b, "if !%s.Equal(%s) {\nreturn false\n}\n", derefTrimmed(x), arg)
+	case equalKindDef:
+		if visiting[sc] {
+			return fmt.Errorf("recursive type: %s", sc.Name)
+		}
+		visiting[sc] = true
+		defer delete(visiting, sc)
+		def := &Field{Type: sc.Type, Map: sc.Map}
+		return g.equal(b, ctx, def, sc.TypePrefixes, x, y, depth, visiting)
+	}
+	return nil
+}
+
+// hash writes statements which write the value x to the hash h.
+func (g *EqualGenerator) hash(b *strings.Builder, ctx *equalCtx, f *Field, prefixes []TypePrefix, x, h string, depth int, visiting map[*Schema]bool) error {
+	if len(prefixes) > 0 {
+		rest := prefixes[1:]
+		var inner strings.Builder
+		switch prefixes[0].Kind() {
+		case TypePrefixKindPtr:
+			if err := g.hash(&inner, ctx, f, rest, "(*"+x+")", h, depth+1, visiting); err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if %s == nil {\n%s.Write([]byte{0})\n} else {\n%s.Write([]byte{1})\n%s}\n", x, h, h, inner.String())
+		case TypePrefixKindSlice, TypePrefixKindArray:
+			i := fmt.Sprintf("i%d", depth)
+			if err := g.hash(&inner, ctx, f, rest, x+"["+i+"]", h, depth+1, visiting); err != nil {
+				return err
+			}
+			ctx.write(b, h, fmt.Sprintf("uint64(len(%s))", x))
+			if inner.Len() > 0 {
+				fmt.Fprintf(b, "for %s := range %s {\n%s}\n", i, x, inner.String())
+			}
+		default:
+			return fmt.Errorf("unknown type prefix: %s", prefixes[0])
+		}
+		return nil
+	}
+
+	switch {
+	case f.IsMap():
+		if f.Map == nil || f.Map.Key == nil || f.Map.Value == nil {
+			return fmt.Errorf("map without key or value")
+		}
+		// entries are hashed separately and summed up not to depend on the order
+		k, v, hh, sum := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth), fmt.Sprintf("h%d", depth+1), fmt.Sprintf("sum%d", depth)
+		var key, val strings.Builder
+		if err := g.hash(&key, ctx, f.Map.Key, f.Map.Key.TypePrefixes, k, hh, depth+1, visiting); err != nil {
+			return err
+		}
+		if err := g.hash(&val, ctx, f.Map.Value, f.Map.Value.TypePrefixes, v, hh, depth+1, visiting); err != nil {
+			return err
+		}
+		ctx.write(b, h, fmt.Sprintf("uint64(len(%s))", x))
+		if key.Len() == 0 && val.Len() == 0 {
+			return nil
+		}
+		if key.Len() == 0 {
+			k = "_"
+		}
+		if val.Len() == 0 {
+			v = "_"
+		}
+		fmt.Fprintf(b, "{\nvar %s uint64\nfor %s, %s := range %s {\n%s := %sNew64a()\n%s%s%s += %s.Sum64()\n}\n", sum, k, v, x, hh, ctx.p.qualify("hash/fnv"), key.String(), val.String(), sum, hh)
+		ctx.write(b, h, sum)
+		b.WriteString("}\n")
+		return nil
+	case f.IsUntitledStruct:
+		if f.Schema == nil {
+			return nil
+		}
+		for _, ff := range f.Schema.Fields {
+			if opts, _ := ststOptions(ff); opts["noeq"] || ff.Name == "_" {
+				continue
+			}
+			if err := g.hash(b, ctx, ff, ff.TypePrefixes, x+"."+ff.Name, h, depth, visiting); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	kind, sc := g.kind(ctx, f)
+	switch kind {
+	case equalKindBasic:
+		u := f.Type.Underlying
+		if sc != nil && sc.Type != nil {
+			u = sc.Type.Underlying
+		}
+		g.hashBasic(b, ctx, u, x, h)
+	case equalKindMethod:
+		if known, ok := equalKnownTypes[f.Type.Underlying]; ok {
+			ctx.write(b, h, fmt.Sprintf(known.hash, x))
+			return nil
+		}
+		if !g.hasHash(ctx, sc) {
+			return nil
+		}
+		ctx.write(b, h, fmt.Sprintf("%s.Hash()", derefTrimmed(x)))
+	case equalKindDef:
+		if visiting[sc] {
+			return fmt.Errorf("recursive type: %s", sc.Name)
+		}
+		visiting[sc] = true
+		defer delete(visiting, sc)
+		def := &Field{Type: sc.Type, Map: sc.Map}
+		return g.hash(b, ctx, def, sc.TypePrefixes, x, h, depth, visiting)
+	}
+	return nil
+}
+
+// hashBasic writes the value x of the basic type to the hash h.
+// -0 of floats is hashed as 0 because they are equal by `==`.
+func (g *EqualGenerator) hashBasic(b *strings.Builder, ctx *equalCtx, u UnderlyingType, x, h string) {
+	switch u {
+	case "string":
+		ctx.write(b, h, fmt.Sprintf("uint64(len(%s))", x))
+		fmt.Fprintf(b, "%sWriteString(%s, string(%s))\n", ctx.p.qualify("io"), h, x)
+	case "bool":
+		ctx.write(b, h, fmt.Sprintf("bool(%s)", x))
+	case "float32", "float64":
+		fmt.Fprintf(b, "{\nv := float64(%s)\nif v == 0 {\nv = 0\n}\n", x)
+		ctx.write(b, h, fmt.Sprintf("%sFloat64bits(v)", ctx.p.qualify("math")))
+		b.WriteString("}\n")
+	case "complex64", "complex128":
+		math := ctx.p.qualify("math")
+		fmt.Fprintf(b, "{\nre, im := real(complex128(%s)), imag(complex128(%s))\nif re == 0 {\nre = 0\n}\nif im == 0 {\nim = 0\n}\n", x, x)
+		ctx.write(b, h, fmt.Sprintf("%sFloat64bits(re)", math))
+		ctx.write(b, h, fmt.Sprintf("%sFloat64bits(im)", math))
+		b.WriteString("}\n")
+	default:
+		ctx.write(b, h, fmt.Sprintf("uint64(%s)", x))
+	}
+}
+
+// kind returns how to compare the value of the Field without prefixes.
+// The Schema is returned for named types in the Index.
+func (g *EqualGenerator) kind(ctx *equalCtx, f *Field) (equalKind, *Schema) {
+	if f.IsFunc() || f.Type == nil {
+		return equalKindSkip, nil
+	}
+	if f.IsUntitledInterface {
+		return equalKindDeep, nil
+	}
+	t := f.Type
+	if _, ok := equalKnownTypes[t.Underlying]; ok {
+		return equalKindMethod, nil
+	}
+	if ctx.typeParams[t.TypeName] {
+		return equalKindDeep, nil
+	}
+
+	sc, ok := g.idx.Lookup(t)
+	if !ok && t.PkgID == "" && t.TypeName != string(t.Underlying) {
+		// named type in the same package like `type Status int`
+		for _, s := range g.idx.Schemas() {
+			if s.Name == t.TypeName && !isSelfType(s) {
+				sc = s
+				break
+			}
+		}
+	}
+	if sc != nil {
+		if m, ok := sc.Method("Equal"); ok {
+			if isEqualMethod(m) {
+				return equalKindMethod, sc
+			}
+			return equalKindDeep, sc
+		}
+		if ctx.targets[sc] {
+			return equalKindMethod, sc
+		}
+		if sc.IsInterface || sc.IsStruct() && len(sc.TypePrefixes) == 0 {
+			return equalKindDeep, sc
+		}
+		if sc.Map == nil && len(sc.TypePrefixes) == 0 {
+			if sc.Type != nil && sc.Type.IsBasic() && !isInterfaceType(sc.Type.Underlying) {
+				return equalKindBasic, sc
+			}
+			return equalKindDeep, sc
+		}
+		return equalKindDef, sc
+	}
+
+	if t.IsBasic() && !isInterfaceType(t.Underlying) {
+		return equalKindBasic, nil
+	}
+	return equalKindDeep, nil
+}
+
+// hasHash returns whether the Schema has Hash method (including generated one).
+func (g *EqualGenerator) hasHash(ctx *equalCtx, sc *Schema) bool {
+	if m, ok := sc.Method("Hash"); ok {
+		return isHashMethod(m)
+	}
+	_, hasEqual := sc.Method("Equal")
+	return ctx.targets[sc] && !hasEqual
+}
+
+// addrOf returns expression of address of the x like `&x`.
+func addrOf(x string) string {
+	if trimmed := derefTrimmed(x); trimmed != x {
+		return trimmed
+	}
+	return "&" + x
+}
+
+// derefTrimmed returns `x` for `(*x)`, the method can be called with pointer.
+func derefTrimmed(x string) string {
+	if strings.HasPrefix(x, "(*") && strings.HasSuffix(x, ")") {
+		return x[2 : len(x)-1]
+	}
+	return x
+}
+
+// write writes statement to write the value to the hash h.
+func (c *equalCtx) write(b *strings.Builder, h, value string) {
+	binary := c.p.qualify("encoding/binary")
+	fmt.Fprintf(b, "%sWrite(%s, %sLittleEndian, %s)\n", binary, h, binary, value)
+}
+
+// isEqualMethod returns whether the method is like `Equal(other T) bool` or `Equal(other *T) bool`.
+func isEqualMethod(m *Method) bool {
+	if m.Func == nil || len(m.Func.Args) != 1 || len(m.Func.Results) != 1 {
+		return false
+	}
+	arg, res := m.Func.Args[0], m.Func.Results[0]
+	if len(arg.TypePrefixes) > 1 || (len(arg.TypePrefixes) == 1 && arg.TypePrefixes[0] != TypePrefixPtr) {
+		return false
+	}
+	return res.Type != nil && res.Type.Underlying == "bool" && len(res.TypePrefixes) == 0
+}
+
+// isHashMethod returns whether the method is like `Hash() uint64`.
+func isHashMethod(m *Method) bool {
+	if m.Func == nil || len(m.Func.Args) != 0 || len(m.Func.Results) != 1 {
+		return false
+	}
+	res := m.Func.Results[0]
+	return res.Type != nil && res.Type.Underlying == "uint64" && len(res.TypePrefixes) == 0
+}
+
+func isInterfaceType(u UnderlyingType) bool {
+	return u == "error" || u == "any" || u == "interface{}"
+}
@@ -0,0 +1,97 @@
+package stst_test
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const equalSrc = `package data
+
+import "time"
+
+type SampleString string
+
+type Good struct {
+	Name      string
+	SamplePtr *SampleString
+}
+
+type Status int
+
+// Item has its own Equal
+type Item struct {
+	Name string
+}
+
+func (x *Item) Equal(other *Item) bool {
+	return x.Name == other.Name
+}
+
+type Order struct {
+	ID     int64
+	Status Status
+	Good   *Good
+	Items  []*Item
+	At     time.Time
+	Labels map[string]string
+	Extra  any
+	Price  float64
+	Wave   complex64
+	Fn     func()
+	Cache  string ` + "`stst:\"noeq\"`" + `
+}
+`
+
+func TestEqualGenerate(t *testing.T) {
+	schemas := parseSource(t, equalSrc)
+
+	var targets []*stst.Schema
+	for _, name := range []string{"Good", "Item", "Order"} {
+		targets = append(targets, findSchema(schemas, name))
+	}
+	got, err := stst.NewEqualGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, targets)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "data_gen.go", got, parser.AllErrors)
+	require.NoError(t, err)
+
+	src := string(got)
+	for _, want := range []string{
+		"func (x *Order) Equal(other *Order) bool {\n\tif x == nil || other == nil {\n\t\treturn x == other\n\t}",
+		"\tif x.ID != other.ID {\n\t\treturn false\n\t}",
+		"\tif x.Status != other.Status {\n\t\treturn false\n\t}",
+		"\tif (x.Good == nil) != (other.Good == nil) {\n\t\treturn false\n\t}\n\tif x.Good != nil {\n\t\tif !x.Good.Equal(other.Good) {",
+		// existing Equal of Item is used
+		"\t\t\tif !x.Items[i0].Equal(other.Items[i0]) {",
+		"\tif !x.At.Equal(other.At) {",
+		"\tfor k0, vx0 := range x.Labels {\n\t\tvy0, ok0 := other.Labels[k0]\n\t\tif !ok0 {\n\t\t\treturn false\n\t\t}\n\t\tif vx0 != vy0 {",
+		"\tif !reflect.DeepEqual(x.Extra, other.Extra) {",
+		"func (x *Order) Hash() uint64 {",
+		"\th := fnv.New64a()\n\tbinary.Write(h, binary.LittleEndian, uint64(x.ID))",
+		"\t\tbinary.Write(h, binary.LittleEndian, x.Good.Hash())",
+		"\tbinary.Write(h, binary.LittleEndian, uint64(x.At.UnixNano()))",
+		// -0 has the same hash as 0
+		"\t{\n\t\tv := float64(x.Price)\n\t\tif v == 0 {\n\t\t\tv = 0\n\t\t}\n\t\tbinary.Write(h, binary.LittleEndian, math.Float64bits(v))\n\t}",
+		"\t{\n\t\tre, im := real(complex128(x.Wave)), imag(complex128(x.Wave))\n\t\tif re == 0 {\n\t\t\tre = 0\n\t\t}\n\t\tif im == 0 {\n\t\t\tim = 0\n\t\t}\n" +
+			"\t\tbinary.Write(h, binary.LittleEndian, math.Float64bits(re))\n\t\tbinary.Write(h, binary.LittleEndian, math.Float64bits(im))\n\t}",
+		// map is hashed regardless of the order
+		"\t\tvar sum0 uint64\n\t\tfor k0, v0 := range x.Labels {\n\t\t\th1 := fnv.New64a()",
+		"func (x *Good) Equal(other *Good) bool {",
+		"func (x *Good) Hash() uint64 {",
+	} {
+		assert.Contains(t, src, want)
+	}
+	for _, notWant := range []string{
+		// Item has its own Equal, so Hash can not be generated consistently
+		"func (x *Item)",
+		"Items[i0].Hash()",
+		"Fn",
+		"Cache",
+	} {
+		assert.NotContains(t, src, notWant)
+	}
+}
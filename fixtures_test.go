@@ -0,0 +1,141 @@
+package stst_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+const testPkg = "github.com/maru44/stst/tests/data"
+
+func namedType(name string) *stst.Type {
+	t := &stst.Type{
+		Underlying: stst.UnderlyingType(testPkg + "." + name),
+		TypeName:   name,
+	}
+	t.SetPackage()
+	return t
+}
+
+func basicType(name string) *stst.Type {
+	return &stst.Type{
+		Underlying: stst.UnderlyingType(name),
+		TypeName:   name,
+	}
+}
+
+func timeType() *stst.Type {
+	t := &stst.Type{
+		Underlying: "time.Time",
+		TypeName:   "Time",
+	}
+	t.SetPackage()
+	return t
+}
+
+func tag(key string, values ...string) *stst.Tag {
+	raw := ""
+	for i, v := range values {
+		if i != 0 {
+			raw += ","
+		}
+		raw += v
+	}
+	return &stst.Tag{
+		Key:      key,
+		Values:   values,
+		RawValue: raw,
+	}
+}
+
+func findSchema(schemas []*stst.Schema, name string) *stst.Schema {
+	for _, sc := range schemas {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+// loadSource returns the package of the source type checked without packages.Load.
+// Imports of deps are resolved to them.
+func loadSource(t *testing.T, path, src string, deps ...*packages.Package) *packages.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "model.go", src, parser.ParseComments)
+	require.NoError(t, err)
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Implicits:  map[ast.Node]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+		Scopes:     map[ast.Node]*types.Scope{},
+	}
+	sizes := types.SizesFor("gc", "amd64")
+	imp := newSourceImporter()
+	for _, dep := range deps {
+		imp.deps[dep.PkgPath] = dep.Types
+	}
+	conf := types.Config{Importer: imp, Sizes: sizes}
+	pkg, err := conf.Check(path, fset, []*ast.File{f}, info)
+	require.NoError(t, err)
+	return &packages.Package{
+		ID:         path,
+		Name:       pkg.Name(),
+		PkgPath:    path,
+		Fset:       fset,
+		Syntax:     []*ast.File{f},
+		Types:      pkg,
+		TypesInfo:  info,
+		TypesSizes: sizes,
+	}
+}
+
+// parseSource returns schemas parsed from the source of the package testPkg.
+func parseSource(t *testing.T, src string) []*stst.Schema {
+	t.Helper()
+	return stst.NewParser(loadSource(t, testPkg, src)).Parse()
+}
+
+// typeCheckGenerated type checks the generated source with the source of the package testPkg.
+func typeCheckGenerated(t *testing.T, src string, generated []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, f := range []struct{ name, src string }{{"model.go", src}, {"model_gen.go", string(generated)}} {
+		file, err := parser.ParseFile(fset, f.name, f.src, parser.ParseComments)
+		require.NoError(t, err)
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: newSourceImporter()}
+	_, err := conf.Check(testPkg, fset, files, nil)
+	require.NoError(t, err)
+}
+
+// sourceImporter imports packages loaded from sources, or standard packages.
+type sourceImporter struct {
+	deps map[string]*types.Package
+	std  types.Importer
+}
+
+func newSourceImporter() *sourceImporter {
+	return &sourceImporter{
+		deps: map[string]*types.Package{},
+		std:  importer.Default(),
+	}
+}
+
+func (imp *sourceImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := imp.deps[path]; ok {
+		return pkg, nil
+	}
+	return imp.std.Import(path)
+}
@@ -0,0 +1,256 @@
+package stst
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// GoPackage is the package where generated Go code is written.
+	GoPackage struct {
+		// Name is package name like `data`
+		Name string
+		// ID is package path like `github.com/maru44/stst/tests/data`
+		ID string
+	}
+
+	// goTypePrinter prints type expression of Field for generated Go code.
+	// Packages used in the expressions are recorded as imports.
+	goTypePrinter struct {
+		pkg     GoPackage
+		imports map[string]string
+		names   map[string]string
+	}
+)
+
+// `xxx/yy.ZZZ` in type string
+var qualifiedNameReg = regexp.MustCompile(`[A-Za-z0-9_\-./]*[A-Za-z0-9_]\.[A-Za-z_][A-Za-z0-9_]*`)
+
+func newGoTypePrinter(pkg GoPackage) *goTypePrinter {
+	return &goTypePrinter{
+		pkg:     pkg,
+		imports: map[string]string{},
+		names:   map[string]string{},
+	}
+}
+
+// addImport records the import and returns its name.
+func (p *goTypePrinter) addImport(pkgID string) string {
+	if name, ok := p.imports[pkgID]; ok {
+		return name
+	}
+	base := path.Base(pkgID)
+	if isMajorVersion(base) && strings.Contains(pkgID, "/") {
+		base = path.Base(path.Dir(pkgID))
+	}
+	base = strings.NewReplacer("-", "", ".", "").Replace(base)
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := p.names[name]; !ok && name != p.pkg.Name {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	p.imports[pkgID] = name
+	p.names[name] = pkgID
+	return name
+}
+
+// qualify returns `name.` for the package, or empty string if it is the package of generated code.
+func (p *goTypePrinter) qualify(pkgID string) string {
+	if pkgID == "" || pkgID == p.pkg.ID {
+		return ""
+	}
+	return p.addImport(pkgID) + "."
+}
+
+// field returns type expression of the Field.
+// The srcPkgID is package where the Field is defined. It is used to qualify named types
+// which do not have package in its Type (like `type Status int`).
+// The typeParams are names of type parameters in the scope.
+func (p *goTypePrinter) field(f *Field, srcPkgID string, typeParams map[string]bool) string {
+	prefixes := f.TypePrefixes
+	var b strings.Builder
+	if f.IsVariadic && len(prefixes) > 0 {
+		b.WriteString("...")
+		prefixes = prefixes[1:]
+	}
+	for _, pref := range prefixes {
+		b.WriteString(string(pref))
+	}
+	b.WriteString(p.base(f, srcPkgID, typeParams))
+	return b.String()
+}
+
+func (p *goTypePrinter) base(f *Field, srcPkgID string, typeParams map[string]bool) string {
+	switch {
+	case f.IsFunc():
+		return "func" + p.signature(f.Func, srcPkgID, typeParams)
+	case f.IsMap():
+		if f.Map == nil || f.Map.Key == nil || f.Map.Value == nil {
+			return "map[any]any"
+		}
+		return "map[" + p.field(f.Map.Key, srcPkgID, typeParams) + "]" + p.field(f.Map.Value, srcPkgID, typeParams)
+	case f.IsUntitledStruct:
+		var fields []string
+		if f.Schema != nil {
+			for _, ff := range f.Schema.Fields {
+				s := p.field(ff, srcPkgID, typeParams)
+				if !ff.IsEmbedded {
+					s = ff.Name + " " + s
+				}
+				if len(ff.Tags) > 0 {
+					s += " " + tagLiteral(ff.Tags)
+				}
+				fields = append(fields, s)
+			}
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
+	case f.IsUntitledInterface:
+		var methods []string
+		if f.Schema != nil {
+			for _, m := range f.Schema.Fields {
+				if m.IsFunc() {
+					methods = append(methods, m.Name+p.signature(m.Func, srcPkgID, typeParams))
+					continue
+				}
+				methods = append(methods, p.field(m, srcPkgID, typeParams))
+			}
+		}
+		return "interface{" + strings.Join(methods, "; ") + "}"
+	case f.Type != nil:
+		return p.typeName(f.Type, srcPkgID, typeParams)
+	}
+	return "any"
+}
+
+func (p *goTypePrinter) typeName(t *Type, srcPkgID string, typeParams map[string]bool) string {
+	if t.PkgID != "" {
+		args := strings.TrimPrefix(string(t.Underlying), string(t.Underlying.withoutTypeArgs()))
+		return p.qualify(t.PkgID) + t.TypeName + p.qualifyTypeString(args)
+	}
+	if _, ok := basicTypes[UnderlyingType(t.TypeName)]; ok || typeParams[t.TypeName] || t.TypeName == string(t.Underlying) {
+		return t.TypeName
+	}
+	// named type defined in the source package
+	return p.qualify(srcPkgID) + t.TypeName
+}
+
+// signature returns `(args) results` of the Func.
+func (p *goTypePrinter) signature(fn *Func, srcPkgID string, typeParams map[string]bool) string {
+	args := make([]string, len(fn.Args))
+	for i, a := range fn.Args {
+		args[i] = p.field(a, srcPkgID, typeParams)
+	}
+	results := make([]string, len(fn.Results))
+	for i, r := range fn.Results {
+		results[i] = p.field(r, srcPkgID, typeParams)
+	}
+	out := "(" + strings.Join(args, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		out += " " + results[0]
+	default:
+		out += " (" + strings.Join(results, ", ") + ")"
+	}
+	return out
+}
+
+// qualifyTypeString replaces package paths in type string like `[]xxx/yy.ZZZ` with the import names.
+func (p *goTypePrinter) qualifyTypeString(s string) string {
+	return qualifiedNameReg.ReplaceAllStringFunc(s, func(m string) string {
+		i := strings.LastIndex(m, ".")
+		return p.qualify(m[:i]) + m[i+1:]
+	})
+}
+
+// file returns formatted Go source with package clause and imports.
+func (p *goTypePrinter) file(body string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by stst. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", p.pkg.Name)
+	if len(p.imports) > 0 {
+		ids := make([]string, 0, len(p.imports))
+		for id := range p.imports {
+			ids = append(ids, id)
+		}
+		// standard packages first
+		sort.Slice(ids, func(i, j int) bool {
+			si, sj := isStdPackage(ids[i]), isStdPackage(ids[j])
+			if si != sj {
+				return si
+			}
+			return ids[i] < ids[j]
+		})
+		b.WriteString("import (\n")
+		for i, id := range ids {
+			if i > 0 && isStdPackage(ids[i-1]) && !isStdPackage(id) {
+				b.WriteString("\n")
+			}
+			name := p.imports[id]
+			if name == path.Base(id) {
+				fmt.Fprintf(&b, "\t%q\n", id)
+				continue
+			}
+			fmt.Fprintf(&b, "\t%s %q\n", name, id)
+		}
+		b.WriteString(")\n\n")
+	}
+	b.WriteString(body)
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return out, nil
+}
+
+// typeParamsDecl returns `[T any, K comparable]` and `[T, K]` of the type parameters.
+func typeParamsDecl(tps []*TypeParam) (decl string, args string) {
+	if len(tps) == 0 {
+		return "", ""
+	}
+	decls := make([]string, len(tps))
+	names := make([]string, len(tps))
+	for i, tp := range tps {
+		decls[i] = tp.Name + " " + tp.Constraint
+		names[i] = tp.Name
+	}
+	return "[" + strings.Join(decls, ", ") + "]", "[" + strings.Join(names, ", ") + "]"
+}
+
+func typeParamNames(tps []*TypeParam) map[string]bool {
+	out := make(map[string]bool, len(tps))
+	for _, tp := range tps {
+		out[tp.Name] = true
+	}
+	return out
+}
+
+func tagLiteral(tags []*Tag) string {
+	parts := make([]string, len(tags))
+	for i, t := range tags {
+		parts[i] = t.Key + ":" + strconv.Quote(t.RawValue)
+	}
+	return "`" + strings.Join(parts, " ") + "`"
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// isStdPackage returns true for package of standard library like `time` or `net/http`.
+func isStdPackage(pkgID string) bool {
+	first, _, _ := strings.Cut(pkgID, "/")
+	return !strings.Contains(first, ".")
+}
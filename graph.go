@@ -0,0 +1,384 @@
+package stst
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	// DependencyGraph is graph of named types referenced by schemas.
+	// Types are referenced by fields (including embedded ones), elements of slices and arrays,
+	// keys and values of maps, arguments and results of funcs, fields of untitled structs,
+	// type arguments of generic types and definitions like `type Goods []*Good`.
+	// Methods declared with the types are not dependencies.
+	DependencyGraph struct {
+		nodes []*GraphNode
+		byID  map[string]*GraphNode
+		edges []*GraphEdge
+		out   map[*GraphNode][]*GraphEdge
+		in    map[*GraphNode][]*GraphEdge
+	}
+
+	// GraphNode is a named type in DependencyGraph.
+	GraphNode struct {
+		// ID is package path and name like `github.com/maru44/stst/tests/data.Good`.
+		// It is only the name for Schema whose package is unknown (not parsed by Parser and not referenced).
+		ID    string
+		Name  string
+		PkgID string
+		// Schema is nil for types which are not in the schemas (like `time.Time`)
+		Schema *Schema
+	}
+
+	// GraphEdge is reference from a type to another type.
+	GraphEdge struct {
+		From *GraphNode
+		To   *GraphNode
+		// Fields are paths of fields referencing the type like `Goods` or `Meta.Note`.
+		// It is empty if the type is referenced only by the definition.
+		Fields []string
+	}
+
+	graphBuilder struct {
+		g        *DependencyGraph
+		idx      *Index
+		bySchema map[*Schema]*GraphNode
+		byType   map[UnderlyingType]*GraphNode
+		edges    map[[2]*GraphNode]*GraphEdge
+		// nodes and edgeList are in order of creation to sort them stably
+		nodes    []*GraphNode
+		edgeList []*GraphEdge
+	}
+)
+
+// NewDependencyGraph returns DependencyGraph of the schemas and types referenced by them.
+func NewDependencyGraph(schemas []*Schema) *DependencyGraph {
+	b := &graphBuilder{
+		g: &DependencyGraph{
+			byID: map[string]*GraphNode{},
+			out:  map[*GraphNode][]*GraphEdge{},
+			in:   map[*GraphNode][]*GraphEdge{},
+		},
+		idx:      NewIndex(schemas),
+		bySchema: map[*Schema]*GraphNode{},
+		byType:   map[UnderlyingType]*GraphNode{},
+		edges:    map[[2]*GraphNode]*GraphEdge{},
+	}
+	for _, sc := range schemas {
+		b.schemaNode(sc, nil)
+	}
+	for _, sc := range schemas {
+		from := b.bySchema[sc]
+		tps := typeParamNames(sc.TypeParams)
+		b.field(from, &Field{Type: sc.Type, TypePrefixes: sc.TypePrefixes, Func: sc.Func, Map: sc.Map}, "", tps, sc)
+		for _, f := range sc.Fields {
+			b.field(from, f, f.Name, tps, sc)
+		}
+	}
+	return b.build()
+}
+
+// Nodes returns all nodes in order of their IDs.
+func (g *DependencyGraph) Nodes() []*GraphNode {
+	return g.nodes
+}
+
+// Edges returns all edges in order of IDs of their nodes.
+func (g *DependencyGraph) Edges() []*GraphEdge {
+	return g.edges
+}
+
+// Node returns the node which has the ID.
+func (g *DependencyGraph) Node(id string) (*GraphNode, bool) {
+	n, ok := g.byID[id]
+	return n, ok
+}
+
+// NodesNamed returns nodes which have the name (like `Good`) in any package.
+func (g *DependencyGraph) NodesNamed(name string) []*GraphNode {
+	var out []*GraphNode
+	for _, n := range g.nodes {
+		if n.Name == name {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Dependencies returns edges to types referenced by the node.
+func (g *DependencyGraph) Dependencies(n *GraphNode) []*GraphEdge {
+	return g.out[n]
+}
+
+// Dependents returns edges from types referencing the node.
+func (g *DependencyGraph) Dependents(n *GraphNode) []*GraphEdge {
+	return g.in[n]
+}
+
+// Cycles returns groups of types which reference each other (strongly connected components),
+// including types referencing themselves like `type Tree struct { Children []*Tree }`.
+// Nodes in a group and the groups are in order of IDs.
+func (g *DependencyGraph) Cycles() [][]*GraphNode {
+	var (
+		out     [][]*GraphNode
+		stack   []*GraphNode
+		counter int
+		index   = map[*GraphNode]int{}
+		low     = map[*GraphNode]int{}
+		onStack = map[*GraphNode]bool{}
+	)
+	// Tarjan's algorithm
+	var visit func(n *GraphNode)
+	visit = func(n *GraphNode) {
+		index[n], low[n] = counter, counter
+		counter++
+		stack = append(stack, n)
+		onStack[n] = true
+		for _, e := range g.out[n] {
+			if _, ok := index[e.To]; !ok {
+				visit(e.To)
+				if low[e.To] < low[n] {
+					low[n] = low[e.To]
+				}
+			} else if onStack[e.To] && index[e.To] < low[n] {
+				low[n] = index[e.To]
+			}
+		}
+		if low[n] != index[n] {
+			return
+		}
+		var comp []*GraphNode
+		for {
+			m := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[m] = false
+			comp = append(comp, m)
+			if m == n {
+				break
+			}
+		}
+		if len(comp) > 1 || g.hasEdge(n, n) {
+			sort.Slice(comp, func(i, j int) bool { return comp[i].ID < comp[j].ID })
+			out = append(out, comp)
+		}
+	}
+	for _, n := range g.nodes {
+		if _, ok := index[n]; !ok {
+			visit(n)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][0].ID < out[j][0].ID })
+	return out
+}
+
+func (g *DependencyGraph) hasEdge(from, to *GraphNode) bool {
+	for _, e := range g.out[from] {
+		if e.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// DOT returns the graph in Graphviz DOT language.
+// Types which are not in the schemas are dashed, and edges are labeled with the fields.
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph stst {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box];\n")
+	for _, n := range g.nodes {
+		fmt.Fprintf(&b, "\t%q [label=%q", n.ID, n.Label())
+		if n.Schema == nil {
+			b.WriteString(", style=dashed")
+		}
+		b.WriteString("];\n")
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "\t%q -> %q", e.From.ID, e.To.ID)
+		if len(e.Fields) > 0 {
+			fmt.Fprintf(&b, " [label=%q]", strings.Join(e.Fields, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid returns the graph as Mermaid flowchart.
+// Types which are not in the schemas have class `external`, and edges are labeled with the fields.
+func (g *DependencyGraph) Mermaid() string {
+	ids := make(map[*GraphNode]string, len(g.nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	var externals []string
+	for i, n := range g.nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "\t%s[\"%s\"]\n", ids[n], mermaidEscape(n.Label()))
+		if n.Schema == nil {
+			externals = append(externals, ids[n])
+		}
+	}
+	for _, e := range g.edges {
+		if len(e.Fields) > 0 {
+			fmt.Fprintf(&b, "\t%s -->|\"%s\"| %s\n", ids[e.From], mermaidEscape(strings.Join(e.Fields, ", ")), ids[e.To])
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s --> %s\n", ids[e.From], ids[e.To])
+	}
+	if len(externals) > 0 {
+		b.WriteString("\tclassDef external stroke-dasharray: 5 5\n")
+		fmt.Fprintf(&b, "\tclass %s external\n", strings.Join(externals, ","))
+	}
+	return b.String()
+}
+
+// Label returns the name qualified by package name like `data.Good`.
+func (n *GraphNode) Label() string {
+	if n.PkgID == "" {
+		return n.Name
+	}
+	_, pkPlusName := UnderlyingType(n.PkgID + "." + n.Name).pk()
+	return pkPlusName
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+// schemaNode returns node of the Schema.
+// The t is a reference to the Schema, which is used to know the package of Schema defined as other type.
+func (b *graphBuilder) schemaNode(sc *Schema, t *Type) *GraphNode {
+	n, ok := b.bySchema[sc]
+	if !ok {
+		n = &GraphNode{Name: sc.Name, PkgID: sc.PkgID, Schema: sc}
+		if isSelfType(sc) {
+			n.PkgID = sc.Type.PkgID
+			b.byType[sc.Type.Underlying.withoutTypeArgs()] = n
+		} else if sc.PkgID != "" {
+			b.byType[UnderlyingType(sc.PkgID+"."+sc.Name)] = n
+		}
+		b.bySchema[sc] = n
+		b.nodes = append(b.nodes, n)
+	}
+	if n.PkgID == "" && t != nil && t.PkgID != "" {
+		n.PkgID = t.PkgID
+		b.byType[t.Underlying.withoutTypeArgs()] = n
+	}
+	return n
+}
+
+// typeNode returns node of the named Type, or nil if it is not named type (like `int` or type parameters).
+func (b *graphBuilder) typeNode(t *Type) *GraphNode {
+	if t == nil || t.PkgID == "" {
+		return nil
+	}
+	u := t.Underlying.withoutTypeArgs()
+	if n, ok := b.byType[u]; ok {
+		return n
+	}
+	if sc := lookupLocal(b.idx, t); sc != nil {
+		return b.schemaNode(sc, t)
+	}
+	n := &GraphNode{Name: t.TypeName, PkgID: t.PkgID}
+	b.byType[u] = n
+	b.nodes = append(b.nodes, n)
+	return n
+}
+
+// field adds edges from the node to types referenced by the Field.
+func (b *graphBuilder) field(from *GraphNode, f *Field, path string, typeParams map[string]bool, self *Schema) {
+	switch {
+	case f.IsFunc():
+		for _, a := range f.Func.Args {
+			b.field(from, a, path, typeParams, self)
+		}
+		for _, r := range f.Func.Results {
+			b.field(from, r, path, typeParams, self)
+		}
+	case f.IsMap():
+		if f.Map.Key != nil {
+			b.field(from, f.Map.Key, path, typeParams, self)
+		}
+		if f.Map.Value != nil {
+			b.field(from, f.Map.Value, path, typeParams, self)
+		}
+	case f.Schema != nil:
+		// untitled struct or interface
+		for _, ff := range f.Schema.Fields {
+			b.field(from, ff, path+"."+ff.Name, typeParams, self)
+		}
+	case f.Type != nil:
+		if typeParams[f.Type.TypeName] && f.Type.PkgID == "" {
+			return
+		}
+		// the Type of struct is itself
+		if path == "" && f.Type == self.Type && isSelfType(self) {
+			return
+		}
+		if to := b.typeNode(f.Type); to != nil {
+			b.edge(from, to, path)
+		}
+		// type arguments like `Gene[xxx/yy.ZZZ]`
+		u := f.Type.Underlying
+		args := strings.TrimPrefix(string(u), string(u.withoutTypeArgs()))
+		for _, m := range qualifiedNameReg.FindAllString(args, -1) {
+			t := &Type{Underlying: UnderlyingType(m), TypeName: m[strings.LastIndex(m, ".")+1:]}
+			t.SetPackage()
+			if to := b.typeNode(t); to != nil {
+				b.edge(from, to, path)
+			}
+		}
+	}
+}
+
+func (b *graphBuilder) edge(from, to *GraphNode, path string) {
+	key := [2]*GraphNode{from, to}
+	e, ok := b.edges[key]
+	if !ok {
+		e = &GraphEdge{From: from, To: to}
+		b.edges[key] = e
+		b.edgeList = append(b.edgeList, e)
+	}
+	if path == "" {
+		return
+	}
+	for _, p := range e.Fields {
+		if p == path {
+			return
+		}
+	}
+	e.Fields = append(e.Fields, path)
+}
+
+func (b *graphBuilder) build() *DependencyGraph {
+	g := b.g
+	for _, n := range b.nodes {
+		n.ID = n.Name
+		if n.PkgID != "" {
+			n.ID = n.PkgID + "." + n.Name
+		}
+	}
+	// nodes with the same ID (schemas of unknown packages) are in order of the schemas
+	g.nodes = b.nodes
+	sort.SliceStable(g.nodes, func(i, j int) bool { return g.nodes[i].ID < g.nodes[j].ID })
+	for _, n := range g.nodes {
+		if _, ok := g.byID[n.ID]; !ok {
+			g.byID[n.ID] = n
+		}
+	}
+
+	g.edges = b.edgeList
+	sort.SliceStable(g.edges, func(i, j int) bool {
+		if g.edges[i].From.ID != g.edges[j].From.ID {
+			return g.edges[i].From.ID < g.edges[j].From.ID
+		}
+		return g.edges[i].To.ID < g.edges[j].To.ID
+	})
+	for _, e := range g.edges {
+		g.out[e.From] = append(g.out[e.From], e)
+		g.in[e.To] = append(g.in[e.To], e)
+	}
+	return g
+}
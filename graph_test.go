@@ -0,0 +1,180 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const graphSrc = `package data
+
+import "time"
+
+type Good struct {
+	Name      string
+	SamplePtr *string
+}
+
+type Person struct {
+	Name string
+	Good
+}
+
+type Animal struct {
+	ID      string
+	Goods   []*Good
+	GoodPtr *Good
+	Born    time.Time
+	Attrs   map[string]int
+	Fn      func(v any)
+}
+
+type Goods []*Good
+
+type Tree struct {
+	Children []*Tree
+}
+
+type A struct {
+	B *B
+}
+
+type B struct {
+	Handler func(a *A)
+	Goods   Goods
+}
+
+type Page[T any] struct {
+	Items []T
+}
+
+type Shop struct {
+	Animals Page[Animal]
+	Stock   map[string]*Good
+	Meta    struct {
+		Opened time.Time
+	}
+}
+`
+
+func TestDependencyGraph(t *testing.T) {
+	g := stst.NewDependencyGraph(parseSource(t, graphSrc))
+
+	id := func(name string) string { return testPkg + "." + name }
+	type edge struct{ from, to, fields string }
+	var edges []edge
+	for _, e := range g.Edges() {
+		var fields string
+		for i, f := range e.Fields {
+			if i > 0 {
+				fields += ","
+			}
+			fields += f
+		}
+		edges = append(edges, edge{e.From.Label(), e.To.Label(), fields})
+	}
+	assert.Equal(t, []edge{
+		{"data.A", "data.B", "B"},
+		{"data.Animal", "data.Good", "Goods,GoodPtr"},
+		{"data.Animal", "time.Time", "Born"},
+		{"data.B", "data.A", "Handler"},
+		{"data.B", "data.Goods", "Goods"},
+		{"data.Goods", "data.Good", ""},
+		{"data.Person", "data.Good", "Good"},
+		{"data.Shop", "data.Animal", "Animals"},
+		{"data.Shop", "data.Good", "Stock"},
+		{"data.Shop", "data.Page", "Animals"},
+		{"data.Shop", "time.Time", "Meta.Opened"},
+		{"data.Tree", "data.Tree", "Children"},
+	}, edges)
+
+	// schema defined as other type gets the package from the reference
+	goods, ok := g.Node(id("Goods"))
+	require.True(t, ok)
+	assert.NotNil(t, goods.Schema)
+	tm, ok := g.Node("time.Time")
+	require.True(t, ok)
+	assert.Nil(t, tm.Schema)
+
+	good := g.NodesNamed("Good")
+	require.Len(t, good, 1)
+	var users []string
+	for _, e := range g.Dependents(good[0]) {
+		users = append(users, e.From.Name)
+	}
+	assert.Equal(t, []string{"Animal", "Goods", "Person", "Shop"}, users)
+	assert.Len(t, g.Dependencies(good[0]), 0)
+
+	var cycles [][]string
+	for _, c := range g.Cycles() {
+		var names []string
+		for _, n := range c {
+			names = append(names, n.Name)
+		}
+		cycles = append(cycles, names)
+	}
+	assert.Equal(t, [][]string{{"A", "B"}, {"Tree"}}, cycles)
+}
+
+func TestDependencyGraph_Export(t *testing.T) {
+	g := stst.NewDependencyGraph([]*stst.Schema{
+		{
+			Name: "Animal",
+			Type: namedType("Animal"),
+			Fields: []*stst.Field{
+				{Name: "Born", Type: timeType()},
+				{Name: "Good", Type: namedType("Good"), IsEmbedded: true},
+			},
+		},
+		{Name: "Good", Type: namedType("Good")},
+		{Name: "Goods", Type: namedType("Good"), TypePrefixes: []stst.TypePrefix{stst.TypePrefixSlice}},
+	})
+
+	assert.Equal(t, `digraph stst {
+	rankdir=LR;
+	node [shape=box];
+	"Goods" [label="Goods"];
+	"github.com/maru44/stst/tests/data.Animal" [label="data.Animal"];
+	"github.com/maru44/stst/tests/data.Good" [label="data.Good"];
+	"time.Time" [label="time.Time", style=dashed];
+	"Goods" -> "github.com/maru44/stst/tests/data.Good";
+	"github.com/maru44/stst/tests/data.Animal" -> "github.com/maru44/stst/tests/data.Good" [label="Good"];
+	"github.com/maru44/stst/tests/data.Animal" -> "time.Time" [label="Born"];
+}
+`, g.DOT())
+
+	assert.Equal(t, `flowchart LR
+	n0["Goods"]
+	n1["data.Animal"]
+	n2["data.Good"]
+	n3["time.Time"]
+	n0 --> n2
+	n1 -->|"Good"| n2
+	n1 -->|"Born"| n3
+	classDef external stroke-dasharray: 5 5
+	class n3 external
+`, g.Mermaid())
+}
+
+func TestDependencyGraph_SameName(t *testing.T) {
+	// Goods are not referenced, so their packages are only known by Parser
+	a := stst.NewParser(loadSource(t, "example.com/a", "package a\n\ntype Good struct{}\n\ntype Goods []*Good\n")).Parse()
+	b := stst.NewParser(loadSource(t, "example.com/b", "package b\n\ntype Goods []string\n")).Parse()
+	g := stst.NewDependencyGraph(append(a, b...))
+
+	var ids []string
+	for _, n := range g.Nodes() {
+		ids = append(ids, n.ID)
+	}
+	assert.Equal(t, []string{"example.com/a.Good", "example.com/a.Goods", "example.com/b.Goods"}, ids)
+	assert.Len(t, g.NodesNamed("Goods"), 2)
+
+	goods, ok := g.Node("example.com/b.Goods")
+	require.True(t, ok)
+	assert.Same(t, b[0], goods.Schema)
+	assert.Equal(t, "b.Goods", goods.Label())
+	require.Len(t, g.Edges(), 1)
+	assert.Equal(t, "example.com/a.Goods", g.Edges()[0].From.ID)
+}
@@ -0,0 +1,335 @@
+package stst
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// GraphQLGenerator generates GraphQL SDL from Schema.
+	//
+	//   - struct becomes object type, or input type if its name ends with `Input`
+	//   - interface becomes interface, methods become fields (error results are ignored)
+	//   - type with constants becomes enum
+	//
+	// Fields of input types and arguments of methods can not be object types or interfaces.
+	// Pointer is nullable and others are non-null.
+	// Field name is the name in `graphql` tag or lower camel cased name of the field,
+	// and fields tagged with `graphql:"-"` are excluded.
+	GraphQLGenerator struct {
+		idx *Index
+
+		scalars map[string]struct{}
+		extra   []string
+	}
+)
+
+const graphqlTagKey = "graphql"
+
+var graphqlScalars = map[UnderlyingType]string{
+	"time.Time": "Time",
+	"any":       "Any",
+}
+
+// NewGraphQLGenerator returns GraphQLGenerator.
+// The schemas are used to resolve named types referenced by fields.
+func NewGraphQLGenerator(schemas []*Schema) *GraphQLGenerator {
+	return &GraphQLGenerator{
+		idx: NewIndex(schemas),
+	}
+}
+
+// Generate returns SDL of the schemas.
+// Custom scalars used by the schemas are declared at the top.
+func (g *GraphQLGenerator) Generate(schemas []*Schema) (string, error) {
+	g.scalars = map[string]struct{}{}
+	g.extra = nil
+
+	var defs []string
+	for _, sc := range schemas {
+		def, err := g.schema(sc)
+		if err != nil {
+			return "", fmt.Errorf("graphql: %s: %w", sc.Name, err)
+		}
+		if def != "" {
+			defs = append(defs, def)
+		}
+	}
+	defs = append(defs, g.extra...)
+
+	var b strings.Builder
+	for _, s := range sortedKeys(g.scalars) {
+		fmt.Fprintf(&b, "scalar %s\n", s)
+	}
+	if len(g.scalars) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Join(defs, "\n"))
+	return b.String(), nil
+}
+
+func (g *GraphQLGenerator) schema(sc *Schema) (string, error) {
+	switch {
+	case sc.IsEnum():
+		var b strings.Builder
+		fmt.Fprintf(&b, "enum %s {\n", sc.Name)
+		for _, c := range sc.Consts {
+			name := strings.TrimPrefix(c.Name, sc.Name)
+			if name == "" {
+				name = c.Name
+			}
+			fmt.Fprintf(&b, "  %s\n", strings.ToUpper(toSnakeCase(name)))
+		}
+		b.WriteString("}\n")
+		return b.String(), nil
+	case sc.IsInterface:
+		var b strings.Builder
+		fmt.Fprintf(&b, "interface %s {\n", sc.Name)
+		if err := g.methods(&b, sc.Fields, map[*Schema]bool{sc: true}); err != nil {
+			return "", err
+		}
+		b.WriteString("}\n")
+		return b.String(), nil
+	case sc.IsStruct() && len(sc.TypePrefixes) == 0:
+		kind := "type"
+		if strings.HasSuffix(sc.Name, "Input") {
+			kind = "input"
+		}
+		return g.object(kind, sc.Name, sc.Fields)
+	}
+	return "", nil
+}
+
+func (g *GraphQLGenerator) object(kind, name string, fields []*Field) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s {\n", kind, name)
+	if err := g.fields(&b, kind, name, fields, 0); err != nil {
+		return "", err
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+func (g *GraphQLGenerator) fields(b *strings.Builder, kind, parent string, fields []*Field, depth int) error {
+	for _, f := range fields {
+		var tag *Tag
+		if t, ok := f.Tag(graphqlTagKey); ok {
+			tag = t
+		}
+		if tag != nil && tag.Name() == "-" {
+			continue
+		}
+		if f.IsEmbedded && (tag == nil || tag.Name() == "") {
+			// fields of embedded struct are promoted
+			if emb, ok := g.idx.Lookup(f.Type); ok && emb.IsStruct() {
+				if depth > 10 {
+					return fmt.Errorf("too deep embedding: %s", emb.Name)
+				}
+				if err := g.fields(b, kind, parent, emb.Fields, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() || f.IsFunc() {
+			continue
+		}
+
+		name := toLowerCamelCase(f.Name)
+		if tag != nil && tag.Name() != "" {
+			name = tag.Name()
+		}
+		typ, err := g.fieldType(kind, parent, f)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		fmt.Fprintf(b, "  %s: %s\n", name, typ)
+	}
+	return nil
+}
+
+// methods writes methods of interface as fields.
+// Methods of embedded interfaces are expanded.
+func (g *GraphQLGenerator) methods(b *strings.Builder, fields []*Field, visiting map[*Schema]bool) error {
+	for _, f := range fields {
+		if f.IsEmbedded {
+			emb, ok := g.idx.Lookup(f.Type)
+			if !ok {
+				emb = g.localSchema(f.Type)
+			}
+			if emb == nil || !emb.IsInterface || visiting[emb] {
+				continue
+			}
+			visiting[emb] = true
+			if err := g.methods(b, emb.Fields, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() || !f.IsFunc() {
+			continue
+		}
+
+		var results []*Field
+		for _, r := range f.Func.Results {
+			if r.Type != nil && r.Type.Underlying == "error" && len(r.TypePrefixes) == 0 {
+				continue
+			}
+			results = append(results, r)
+		}
+		if len(results) != 1 {
+			// method which does not return single value can not be field
+			continue
+		}
+		typ, err := g.fieldType("type", "", results[0])
+		if err != nil {
+			return fmt.Errorf("method %s: %w", f.Name, err)
+		}
+
+		var args []string
+		for _, a := range f.Func.Args {
+			if a.Type != nil && a.Type.Underlying == "context.Context" {
+				continue
+			}
+			at, err := g.fieldType("input", "", a)
+			if err != nil {
+				return fmt.Errorf("method %s: %w", f.Name, err)
+			}
+			args = append(args, fmt.Sprintf("%s: %s", a.Name, at))
+		}
+		name := toLowerCamelCase(f.Name)
+		if len(args) > 0 {
+			name += "(" + strings.Join(args, ", ") + ")"
+		}
+		fmt.Fprintf(b, "  %s: %s\n", name, typ)
+	}
+	return nil
+}
+
+func (g *GraphQLGenerator) fieldType(kind, parent string, f *Field) (string, error) {
+	prefixes := f.TypePrefixes
+	var base string
+	switch {
+	case f.IsMap():
+		base = g.scalar("Map")
+	case f.IsUntitledStruct:
+		if parent == "" {
+			return "", fmt.Errorf("untitled struct is not supported")
+		}
+		// untitled struct becomes a type named after the parent and the field
+		base = parent + f.Name
+		var fields []*Field
+		if f.Schema != nil {
+			fields = f.Schema.Fields
+		}
+		def, err := g.object(kind, base, fields)
+		if err != nil {
+			return "", err
+		}
+		g.extra = append(g.extra, def)
+	case f.Type != nil:
+		if n := len(prefixes); n > 0 && prefixes[n-1] == TypePrefixSlice && isByte(f.Type) {
+			base = "String"
+			prefixes = prefixes[:n-1]
+			break
+		}
+		var err error
+		base, err = g.typeName(kind, f)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported type")
+	}
+
+	out := base
+	nullable := false
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		switch prefixes[i].Kind() {
+		case TypePrefixKindPtr:
+			nullable = true
+		case TypePrefixKindSlice, TypePrefixKindArray:
+			if !nullable {
+				out += "!"
+			}
+			out = "[" + out + "]"
+			nullable = false
+		}
+	}
+	if !nullable {
+		out += "!"
+	}
+	return out, nil
+}
+
+// typeName returns name of the type of the field.
+// Objects and interfaces can not be used in input positions (fields of input types and arguments).
+func (g *GraphQLGenerator) typeName(kind string, f *Field) (string, error) {
+	t := f.Type
+	if s, ok := graphqlScalars[t.Underlying]; ok {
+		return g.scalar(s), nil
+	}
+	if t.IsBasic() {
+		if t.TypeName != string(t.Underlying) {
+			// named basic type in the same package like `type Status int`
+			if sc := g.localSchema(t); sc != nil && sc.IsEnum() {
+				return sc.Name, nil
+			}
+		}
+		if f.Name == "ID" && t.Underlying == "string" {
+			return "ID", nil
+		}
+		return graphqlBasicType(t.Underlying)
+	}
+	sc, ok := g.idx.Lookup(t)
+	if !ok {
+		return "", fmt.Errorf("unknown type: %s", t.Underlying)
+	}
+	if sc.IsEnum() {
+		return sc.Name, nil
+	}
+	if sc.IsInterface || (sc.IsStruct() && len(sc.TypePrefixes) == 0) {
+		if kind == "input" && (sc.IsInterface || !strings.HasSuffix(sc.Name, "Input")) {
+			return "", fmt.Errorf("%s is not input type", sc.Name)
+		}
+		return sc.Name, nil
+	}
+	if sc.Type != nil && sc.Type.IsBasic() && len(sc.TypePrefixes) == 0 {
+		return graphqlBasicType(sc.Type.Underlying)
+	}
+	return "", fmt.Errorf("unsupported type: %s", t.Underlying)
+}
+
+func (g *GraphQLGenerator) scalar(name string) string {
+	g.scalars[name] = struct{}{}
+	return name
+}
+
+// localSchema returns the Schema whose name is TypeName of the Type.
+// It is used for types in the same package which do not have package in Type.
+func (g *GraphQLGenerator) localSchema(t *Type) *Schema {
+	if t == nil {
+		return nil
+	}
+	for _, sc := range g.idx.Schemas() {
+		if sc.Name == t.TypeName {
+			return sc
+		}
+	}
+	return nil
+}
+
+func graphqlBasicType(u UnderlyingType) (string, error) {
+	switch u {
+	case "string":
+		return "String", nil
+	case "bool":
+		return "Boolean", nil
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
+		return "Int", nil
+	case "float32", "float64":
+		return "Float", nil
+	}
+	return "", fmt.Errorf("unsupported type: %s", u)
+}
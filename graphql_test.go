@@ -0,0 +1,169 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const graphQLSrc = `package data
+
+import "time"
+
+type SampleString string
+
+type Good struct {
+	Name      string
+	SamplePtr *SampleString
+}
+
+type Status int
+
+const (
+	StatusActive Status = iota
+	StatusInactive
+)
+
+type User struct {
+	ID        string
+	Status    Status
+	Goods     []*Good
+	Best      *Good ` + "`graphql:\"bestGood\"`" + `
+	CreatedAt time.Time
+	Profile   struct {
+		Bio string
+	}
+	Password string ` + "`graphql:\"-\"`" + `
+}
+
+type CreateUserInput struct {
+	Name string
+	Age  *int
+}
+
+type Named interface {
+	Name() string
+}
+
+type Finder interface {
+	Find(id string) (*Good, error)
+	Named
+	Close()
+}
+`
+
+func TestGraphQLGenerate(t *testing.T) {
+	schemas := parseSource(t, graphQLSrc)
+
+	g := stst.NewGraphQLGenerator(schemas)
+	var targets []*stst.Schema
+	for _, name := range []string{"Status", "Good", "User", "CreateUserInput", "Finder"} {
+		targets = append(targets, findSchema(schemas, name))
+	}
+	got, err := g.Generate(targets)
+	require.NoError(t, err)
+
+	want := `scalar Time
+
+enum Status {
+  ACTIVE
+  INACTIVE
+}
+
+type Good {
+  name: String!
+  samplePtr: String
+}
+
+type User {
+  id: ID!
+  status: Status!
+  goods: [Good]!
+  bestGood: Good
+  createdAt: Time!
+  profile: UserProfile!
+}
+
+input CreateUserInput {
+  name: String!
+  age: Int
+}
+
+interface Finder {
+  find(id: String!): Good
+  name: String!
+}
+
+type UserProfile {
+  bio: String!
+}
+`
+	assert.Equal(t, want, got)
+}
+
+func TestGraphQLGenerate_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{
+			name: "object in input",
+			src: `type UpdateUserInput struct {
+	Good Good
+}`,
+			err: "graphql: UpdateUserInput: field Good: Good is not input type",
+		},
+		{
+			name: "interface in input",
+			src: `type UpdateUserInput struct {
+	Named []Named
+}`,
+			err: "graphql: UpdateUserInput: field Named: Named is not input type",
+		},
+		{
+			name: "object in argument",
+			src: `type Saver interface {
+	Save(good *Good) bool
+}`,
+			err: "graphql: Saver: method Save: Good is not input type",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			schemas := parseSource(t, graphQLSrc+"\n"+tt.src+"\n")
+			sc := schemas[len(schemas)-1]
+			_, err := stst.NewGraphQLGenerator(schemas).Generate([]*stst.Schema{sc})
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestGraphQLGenerate_FieldName(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{field: "Name", want: "name"},
+		{field: "UserID", want: "userID"},
+		{field: "ID", want: "id"},
+		{field: "IDs", want: "ids"},
+		{field: "URLs", want: "urls"},
+		{field: "IDsByName", want: "idsByName"},
+		{field: "HTTPServer", want: "httpServer"},
+		{field: "URLString", want: "urlString"},
+		{field: "A", want: "a"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.field, func(t *testing.T) {
+			schemas := parseSource(t, "package data\n\ntype Sample struct {\n\t"+tt.field+" int\n}\n")
+			got, err := stst.NewGraphQLGenerator(schemas).Generate(schemas)
+			require.NoError(t, err)
+			assert.Equal(t, "type Sample {\n  "+tt.want+": Int!\n}\n", got)
+		})
+	}
+}
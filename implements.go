@@ -0,0 +1,268 @@
+package stst
+
+import "sort"
+
+type (
+	// Implementation is a type implementing an interface.
+	Implementation struct {
+		Interface *Schema
+		Type      *Schema
+		// Pointer is true if only the pointer of the Type implements the Interface
+		// because some of the methods have pointer receivers
+		Pointer bool
+	}
+
+	// ImplementationNearMiss is a type which does not implement an interface only because of one method.
+	ImplementationNearMiss struct {
+		Interface *Schema
+		Type      *Schema
+		// Missing is name of the method which the Type does not have
+		Missing string
+		// Mismatch is the method which the Type has with other signature
+		Mismatch *MethodMismatch
+	}
+
+	// MethodMismatch is a method whose signature differs from the interface.
+	MethodMismatch struct {
+		Name string
+		// Want and Got are signatures like `(ctx context.Context) error`
+		Want string
+		Got  string
+	}
+
+	// ImplementationReport is result of FindImplementations.
+	ImplementationReport struct {
+		Implementations []*Implementation
+		NearMisses      []*ImplementationNearMiss
+	}
+
+	implFinder struct {
+		idx *Index
+		// p is shared so that packages with the same name are printed differently in signatures
+		p *goTypePrinter
+	}
+
+	// implMethod is a method in the method set of a type.
+	implMethod struct {
+		sig string
+		// ptr is true if the method is only in the method set of the pointer
+		ptr bool
+	}
+)
+
+// FindImplementations returns which types implement which interfaces in the schemas by value or by pointer,
+// and near misses which lack one method or have one method with other signature.
+// Methods promoted from embedded fields are included in the same way as Go.
+//
+// Interfaces without methods, generic interfaces and interfaces embedding types which are not in the schemas
+// (like `io.Reader`) are skipped because their method sets can not be known.
+// Only types other than interfaces are checked as implementations.
+func FindImplementations(schemas []*Schema) *ImplementationReport {
+	f := &implFinder{
+		idx: NewIndex(schemas),
+		p:   newGoTypePrinter(GoPackage{}),
+	}
+	type intf struct {
+		sc      *Schema
+		methods map[string]string
+	}
+	var intfs []*intf
+	var types []*Schema
+	for _, sc := range schemas {
+		if !sc.IsInterface {
+			types = append(types, sc)
+			continue
+		}
+		if len(sc.TypeParams) > 0 {
+			continue
+		}
+		ms, ok := f.interfaceMethods(sc, map[*Schema]bool{})
+		if !ok || len(ms) == 0 {
+			continue
+		}
+		intfs = append(intfs, &intf{sc: sc, methods: ms})
+	}
+	sort.SliceStable(intfs, func(i, j int) bool { return schemaID(intfs[i].sc) < schemaID(intfs[j].sc) })
+	sort.SliceStable(types, func(i, j int) bool { return schemaID(types[i]) < schemaID(types[j]) })
+
+	out := &ImplementationReport{}
+	for _, t := range types {
+		ms := f.methodSet(t)
+		for _, it := range intfs {
+			var (
+				pointer  bool
+				missing  []string
+				mismatch []*MethodMismatch
+				shared   bool
+			)
+			for _, name := range sortedKeys(it.methods) {
+				want := it.methods[name]
+				got, ok := ms[name]
+				switch {
+				case !ok:
+					missing = append(missing, name)
+					continue
+				case got.sig != want:
+					mismatch = append(mismatch, &MethodMismatch{Name: name, Want: want, Got: got.sig})
+				case got.ptr:
+					pointer = true
+				}
+				shared = true
+			}
+			switch {
+			case len(missing)+len(mismatch) == 0:
+				out.Implementations = append(out.Implementations, &Implementation{Interface: it.sc, Type: t, Pointer: pointer})
+			case len(missing)+len(mismatch) > 1 || !shared:
+				// types which have nothing of the interface are not near misses
+			case len(missing) == 1:
+				out.NearMisses = append(out.NearMisses, &ImplementationNearMiss{Interface: it.sc, Type: t, Missing: missing[0]})
+			default:
+				out.NearMisses = append(out.NearMisses, &ImplementationNearMiss{Interface: it.sc, Type: t, Mismatch: mismatch[0]})
+			}
+		}
+	}
+	sort.SliceStable(out.Implementations, func(i, j int) bool {
+		return schemaID(out.Implementations[i].Interface) < schemaID(out.Implementations[j].Interface)
+	})
+	sort.SliceStable(out.NearMisses, func(i, j int) bool {
+		return schemaID(out.NearMisses[i].Interface) < schemaID(out.NearMisses[j].Interface)
+	})
+	return out
+}
+
+// Implementers returns implementations of the interface.
+func (r *ImplementationReport) Implementers(intf *Schema) []*Implementation {
+	var out []*Implementation
+	for _, impl := range r.Implementations {
+		if impl.Interface == intf {
+			out = append(out, impl)
+		}
+	}
+	return out
+}
+
+// Implements returns implementations by the type.
+func (r *ImplementationReport) Implements(typ *Schema) []*Implementation {
+	var out []*Implementation
+	for _, impl := range r.Implementations {
+		if impl.Type == typ {
+			out = append(out, impl)
+		}
+	}
+	return out
+}
+
+// String returns the near miss like `data.User does not implement data.Store: missing method Close`.
+func (m *ImplementationNearMiss) String() string {
+	s := schemaLabel(m.Type) + " does not implement " + schemaLabel(m.Interface) + ": "
+	if m.Mismatch != nil {
+		return s + "wrong signature of " + m.Mismatch.Name + ": want " + m.Mismatch.Want + ", got " + m.Mismatch.Got
+	}
+	return s + "missing method " + m.Missing
+}
+
+// schemaID returns package path and name of the Schema, or only the name if the package is unknown.
+func schemaID(sc *Schema) string {
+	if isSelfType(sc) {
+		return sc.Type.PkgID + "." + sc.Name
+	}
+	return sc.Name
+}
+
+// schemaLabel returns name of the Schema qualified by package name like `data.User`.
+func schemaLabel(sc *Schema) string {
+	if !isSelfType(sc) {
+		return sc.Name
+	}
+	_, pkPlusName := UnderlyingType(schemaID(sc)).pk()
+	return pkPlusName
+}
+
+// interfaceMethods returns signatures of methods of the interface by names including ones of embedded interfaces.
+// It returns false if it embeds types which are not interfaces in the schemas.
+func (f *implFinder) interfaceMethods(sc *Schema, seen map[*Schema]bool) (map[string]string, bool) {
+	out := map[string]string{}
+	if seen[sc] {
+		return out, true
+	}
+	seen[sc] = true
+	ok := true
+	for _, field := range sc.Fields {
+		if field.IsFunc() && !field.IsEmbedded {
+			out[field.Name] = f.p.signature(field.Func, "", nil)
+			continue
+		}
+		emb := lookupLocal(f.idx, field.Type)
+		if emb == nil || !emb.IsInterface {
+			ok = false
+			continue
+		}
+		ms, embOK := f.interfaceMethods(emb, seen)
+		ok = ok && embOK
+		for name, sig := range ms {
+			out[name] = sig
+		}
+	}
+	return out, ok
+}
+
+// methodSet returns methods of the type including promoted ones.
+// Selectors at the shallowest depth win, and the same names at the same depth are ambiguous and dropped.
+func (f *implFinder) methodSet(sc *Schema) map[string]*implMethod {
+	type embedded struct {
+		sc *Schema
+		// ptr is true if the type is reached through pointer
+		ptr bool
+	}
+	out := map[string]*implMethod{}
+	resolved := map[string]bool{}
+	visited := map[*Schema]bool{sc: true}
+	current := []embedded{{sc: sc}}
+	for len(current) > 0 {
+		count := map[string]int{}
+		found := map[string]*implMethod{}
+		var next []embedded
+		for _, e := range current {
+			for _, m := range e.sc.Methods {
+				count[m.Name]++
+				found[m.Name] = &implMethod{sig: f.p.signature(m.Func, "", nil), ptr: m.IsPointerReceiver && !e.ptr}
+			}
+			if e.sc.IsInterface {
+				ms, _ := f.interfaceMethods(e.sc, map[*Schema]bool{})
+				for name, sig := range ms {
+					count[name]++
+					found[name] = &implMethod{sig: sig}
+				}
+				continue
+			}
+			if !e.sc.IsStruct() || len(e.sc.TypePrefixes) > 0 {
+				continue
+			}
+			for _, field := range e.sc.Fields {
+				// fields shadow methods of the same names
+				count[field.Name]++
+				if !field.IsEmbedded {
+					continue
+				}
+				emb := lookupLocal(f.idx, field.Type)
+				if emb == nil || visited[emb] {
+					continue
+				}
+				visited[emb] = true
+				ptr := e.ptr || len(field.TypePrefixes) > 0 && field.TypePrefixes[0] == TypePrefixPtr
+				next = append(next, embedded{sc: emb, ptr: ptr})
+			}
+		}
+		for name, c := range count {
+			if resolved[name] {
+				continue
+			}
+			resolved[name] = true
+			if m, ok := found[name]; ok && c == 1 {
+				out[name] = m
+			}
+		}
+		current = next
+	}
+	return out
+}
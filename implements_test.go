@@ -0,0 +1,101 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+)
+
+const implSrc = `package data
+
+import "io"
+
+type Closer interface {
+	Close() error
+}
+
+type Store interface {
+	Closer
+	Get(id string) (*Animal, error)
+}
+
+type ReadCloser interface {
+	io.Reader
+	Closer
+}
+
+type Any interface{}
+
+type MemStore struct{}
+
+func (s MemStore) Get(id string) (*Animal, error) { return nil, nil }
+
+func (s *MemStore) Close() error { return nil }
+
+type Base struct{}
+
+func (b Base) Get(id string) (*Animal, error) { return nil, nil }
+
+func (b *Base) Close() error { return nil }
+
+type FileStore struct {
+	*Base
+}
+
+type Broken struct{}
+
+func (b Broken) Get(id int) (*Animal, error) { return nil, nil }
+
+func (b Broken) Close() error { return nil }
+
+type Ambiguous struct {
+	Base
+	Closer
+}
+
+type Status int
+
+func (s Status) Close() error { return nil }
+
+type Animal struct{}
+`
+
+func TestFindImplementations(t *testing.T) {
+	schemas := parseSource(t, implSrc)
+	got := stst.FindImplementations(schemas)
+
+	var impls []string
+	for _, impl := range got.Implementations {
+		s := impl.Type.Name + " implements " + impl.Interface.Name
+		if impl.Pointer {
+			s = "*" + s
+		}
+		impls = append(impls, s)
+	}
+	assert.Equal(t, []string{
+		"Status implements Closer",
+		"*Base implements Closer",
+		"Broken implements Closer",
+		"FileStore implements Closer",
+		"*MemStore implements Closer",
+		"*Base implements Store",
+		"FileStore implements Store",
+		"*MemStore implements Store",
+	}, impls)
+
+	var misses []string
+	for _, m := range got.NearMisses {
+		misses = append(misses, m.String())
+	}
+	assert.Equal(t, []string{
+		"Status does not implement data.Store: missing method Get",
+		"data.Ambiguous does not implement data.Store: missing method Close",
+		"data.Broken does not implement data.Store: wrong signature of Get: want (string) (*data.Animal, error), got (int) (*data.Animal, error)",
+	}, misses)
+
+	closer := findSchema(schemas, "Closer")
+	assert.Len(t, got.Implementers(closer), 5)
+	assert.Len(t, got.Implements(findSchema(schemas, "FileStore")), 2)
+	assert.Empty(t, got.Implements(findSchema(schemas, "Animal")))
+}
@@ -0,0 +1,83 @@
+package stst
+
+import "strings"
+
+type (
+	// Index is used to look up Schema which is referenced by Type.
+	// It is safe for concurrent use by multiple goroutines because it is not changed after NewIndex.
+	Index struct {
+		schemas      []*Schema
+		byUnderlying map[UnderlyingType]*Schema
+		byName       map[string][]*Schema
+	}
+)
+
+// NewIndex returns Index of the schemas.
+func NewIndex(schemas []*Schema) *Index {
+	idx := &Index{
+		schemas:      schemas,
+		byUnderlying: make(map[UnderlyingType]*Schema, len(schemas)),
+		byName:       make(map[string][]*Schema, len(schemas)),
+	}
+	for _, sc := range schemas {
+		if isSelfType(sc) {
+			idx.byUnderlying[sc.Type.Underlying.withoutTypeArgs()] = sc
+		}
+		idx.byName[sc.Name] = append(idx.byName[sc.Name], sc)
+	}
+	return idx
+}
+
+// Schemas returns all schemas in the Index.
+func (i *Index) Schemas() []*Schema {
+	return i.schemas
+}
+
+// Lookup returns the Schema defining the Type.
+// Schema defined as other type (like `type IntSample int` or `type Goods []*Good`) does not have
+// its own package and name in its Type, so it is looked up by its name and PkgID.
+func (i *Index) Lookup(t *Type) (*Schema, bool) {
+	if t == nil || t.PkgID == "" {
+		return nil, false
+	}
+	if sc, ok := i.byUnderlying[t.Underlying.withoutTypeArgs()]; ok {
+		return sc, true
+	}
+	var found *Schema
+	for _, sc := range i.byName[t.TypeName] {
+		if sc.PkgID != t.PkgID || isSelfType(sc) {
+			continue
+		}
+		if found != nil {
+			return nil, false
+		}
+		found = sc
+	}
+	return found, found != nil
+}
+
+// LookupName returns the Schema which has the name in the package of the PkgID.
+// It is used for named types which do not have their package in Type like `type Status int`.
+func (i *Index) LookupName(pkgID, name string) (*Schema, bool) {
+	for _, sc := range i.byName[name] {
+		if sc.PkgID == pkgID {
+			return sc, true
+		}
+	}
+	return nil, false
+}
+
+// isSelfType returns whether the Type of the Schema is the Schema itself.
+// It is false for Schema defined as other type like `type Goods []*Good`.
+func isSelfType(sc *Schema) bool {
+	return sc.Type != nil && sc.Type.PkgID != "" && sc.Type.TypeName == sc.Name
+}
+
+// withoutTypeArgs removes type arguments (or type parameters) of generic type.
+// `xxx/yy.ZZZ[T any]` and `xxx/yy.ZZZ[int]` become `xxx/yy.ZZZ`.
+func (u UnderlyingType) withoutTypeArgs() UnderlyingType {
+	if i := strings.Index(string(u), "["); i > 0 {
+		return u[:i]
+	}
+	return u
+}
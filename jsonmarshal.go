@@ -0,0 +1,1419 @@
+package stst
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type (
+	// JSONMarshalerGenerator generates reflection-free `MarshalJSON` and `UnmarshalJSON` methods of structs
+	// which behave the same as encoding/json.
+	//
+	// `json` tags (names, `-`, omitempty and string option), promotion of fields of embedded structs
+	// and pointers, slices, arrays and maps are supported.
+	// Structs which have their own methods are skipped, and named types with `MarshalJSON`, `UnmarshalJSON`,
+	// `MarshalText` or `UnmarshalText` (`Schema.Methods`) are encoded by the methods.
+	// Interfaces, type parameters and types out of the Index are encoded by encoding/json.
+	JSONMarshalerGenerator struct {
+		idx *Index
+	}
+
+	jsonCtx struct {
+		p          *goTypePrinter
+		srcPkgID   string
+		typeParams map[string]bool
+		// marshal and unmarshal are structs whose methods are generated
+		marshal   map[*Schema]bool
+		unmarshal map[*Schema]bool
+		// nonNil is expression which is known not to be nil by omitempty
+		nonNil string
+		// assignsErr is set when statements assign `err` declared at the top of appendJSON
+		assignsErr *bool
+	}
+
+	// jsonField is field of JSON object, fields of embedded structs are promoted.
+	jsonField struct {
+		name  string
+		field *Field
+		// path is selector of the field like `Base.ID`
+		path string
+		// ptrs are embedded pointers in the path
+		ptrs      []*jsonEmbeddedPtr
+		depth     int
+		tagged    bool
+		omitempty bool
+		quoted    bool
+	}
+
+	// jsonEmbeddedPtr is embedded pointer to struct which must be non-nil to access promoted fields.
+	jsonEmbeddedPtr struct {
+		// path is selector of the pointer like `Base`
+		path  string
+		field *Field
+	}
+
+	// jsonType is resolved type of value.
+	jsonType struct {
+		kind string
+		bits int
+		sc   *Schema
+		// marshal and unmarshal are names of methods to encode and decode the value
+		marshal   string
+		unmarshal string
+	}
+)
+
+// types which are encoded by their own methods
+var jsonKnownTypes = map[UnderlyingType]*jsonType{
+	"time.Time":     {kind: "struct", marshal: "MarshalJSON", unmarshal: "UnmarshalJSON"},
+	"time.Duration": {kind: "int", bits: 64},
+}
+
+// NewJSONMarshalerGenerator returns JSONMarshalerGenerator.
+// The schemas are used to resolve named types of fields, embedded structs and their methods.
+func NewJSONMarshalerGenerator(schemas []*Schema) *JSONMarshalerGenerator {
+	return &JSONMarshalerGenerator{
+		idx: NewIndex(schemas),
+	}
+}
+
+// Generate returns Go source of `MarshalJSON` and `UnmarshalJSON` for struct schemas.
+// The pkg should be the package of the schemas because they are methods.
+//
+// Helpers of the generated methods (like `jsonDecoder`) are declared in the source,
+// so generate them of a package into one file.
+func (g *JSONMarshalerGenerator) Generate(pkg GoPackage, schemas []*Schema) ([]byte, error) {
+	ctx := &jsonCtx{
+		p:         newGoTypePrinter(pkg),
+		marshal:   map[*Schema]bool{},
+		unmarshal: map[*Schema]bool{},
+	}
+	for _, sc := range schemas {
+		if !sc.IsStruct() || len(sc.TypePrefixes) > 0 {
+			continue
+		}
+		if _, ok := sc.Method("MarshalJSON"); !ok {
+			ctx.marshal[sc] = true
+		}
+		if _, ok := sc.Method("UnmarshalJSON"); !ok {
+			ctx.unmarshal[sc] = true
+		}
+	}
+	if len(ctx.marshal) == 0 && len(ctx.unmarshal) == 0 {
+		return ctx.p.file("")
+	}
+	// packages of helpers take precedence over others
+	for _, id := range jsonHelperImports {
+		ctx.p.addImport(id)
+	}
+
+	var b strings.Builder
+	for _, sc := range schemas {
+		if !ctx.marshal[sc] && !ctx.unmarshal[sc] {
+			continue
+		}
+		if err := g.methods(&b, ctx, sc); err != nil {
+			return nil, fmt.Errorf("json marshaler: %s: %w", sc.Name, err)
+		}
+	}
+	b.WriteString(jsonHelpers)
+
+	out, err := ctx.p.file(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("json marshaler: %w", err)
+	}
+	return out, nil
+}
+
+func (g *JSONMarshalerGenerator) methods(b *strings.Builder, ctx *jsonCtx, sc *Schema) error {
+	_, args := typeParamsDecl(sc.TypeParams)
+	typ := sc.Name + args
+	ctx.srcPkgID = ctx.p.pkg.ID
+	if sc.Type != nil && sc.Type.PkgID != "" {
+		ctx.srcPkgID = sc.Type.PkgID
+	}
+	ctx.typeParams = typeParamNames(sc.TypeParams)
+
+	fields, err := g.fields(ctx, sc.Fields)
+	if err != nil {
+		return err
+	}
+
+	if ctx.marshal[sc] {
+		var body strings.Builder
+		assignsErr := false
+		ctx.assignsErr = &assignsErr
+		if err := g.encodeObject(&body, ctx, fields, "x."); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "// MarshalJSON encodes the %s as JSON.\n", sc.Name)
+		fmt.Fprintf(b, "func (x %s) MarshalJSON() ([]byte, error) {\nreturn x.appendJSON(make([]byte, 0, 128))\n}\n\n", typ)
+		fmt.Fprintf(b, "func (x *%s) appendJSON(buf []byte) ([]byte, error) {\n", typ)
+		if assignsErr {
+			b.WriteString("var err error\n")
+		}
+		fmt.Fprintf(b, "%sreturn buf, nil\n}\n\n", body.String())
+	}
+
+	if ctx.unmarshal[sc] {
+		var body strings.Builder
+		if err := g.decodeObject(&body, ctx, fields, "x.", "d", 0); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "// UnmarshalJSON decodes JSON into the %s.\n", sc.Name)
+		fmt.Fprintf(b, "func (x *%s) UnmarshalJSON(data []byte) error {\n", typ)
+		b.WriteString("d := &jsonDecoder{data: data}\nif err := x.decodeJSON(d); err != nil {\nreturn err\n}\nreturn d.end()\n}\n\n")
+		fmt.Fprintf(b, "func (x *%s) decodeJSON(d *jsonDecoder) error {\n", typ)
+		fmt.Fprintf(b, "if ok, err := d.null(); err != nil || ok {\nreturn err\n}\n%sreturn nil\n}\n\n", body.String())
+	}
+	return nil
+}
+
+// fields returns fields of JSON object in the order of encoding/json.
+func (g *JSONMarshalerGenerator) fields(ctx *jsonCtx, fields []*Field) ([]*jsonField, error) {
+	var all []*jsonField
+	if err := g.collect(ctx, fields, "", nil, 0, map[*Schema]bool{}, &all); err != nil {
+		return nil, err
+	}
+
+	// the field with the shallowest depth (and tagged one) dominates others with the same name
+	byName := map[string][]*jsonField{}
+	for _, f := range all {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	var out []*jsonField
+	for _, f := range all {
+		if f == dominantJSONField(byName[f.name]) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func (g *JSONMarshalerGenerator) collect(ctx *jsonCtx, fields []*Field, path string, ptrs []*jsonEmbeddedPtr, depth int, visiting map[*Schema]bool, out *[]*jsonField) error {
+	for _, f := range fields {
+		var tag *Tag
+		if t, ok := f.Tag(jsonTagKey); ok {
+			tag = t
+		}
+		if tag != nil && tag.Name() == "-" && len(tag.Values) == 1 {
+			continue
+		}
+		name := ""
+		if tag != nil && isValidJSONName(tag.Name()) {
+			name = tag.Name()
+		}
+
+		if f.IsEmbedded && name == "" {
+			emb, isPtr, err := g.embedded(ctx, f)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if emb != nil {
+				if visiting[emb] {
+					continue
+				}
+				visiting[emb] = true
+				embPtrs := ptrs
+				if isPtr {
+					embPtrs = append(append([]*jsonEmbeddedPtr{}, ptrs...), &jsonEmbeddedPtr{path: path + f.Name, field: f})
+				}
+				if err := g.collect(ctx, emb.Fields, path+f.Name+".", embPtrs, depth+1, visiting, out); err != nil {
+					return err
+				}
+				delete(visiting, emb)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if f.IsFunc() {
+			return fmt.Errorf("field %s: func can not be encoded", f.Name)
+		}
+
+		jf := &jsonField{
+			name:   name,
+			field:  f,
+			path:   path + f.Name,
+			ptrs:   ptrs,
+			depth:  depth,
+			tagged: name != "",
+		}
+		if name == "" {
+			jf.name = f.Name
+		}
+		if tag != nil {
+			jf.omitempty = tag.HasOption("omitempty")
+			if tag.HasOption("string") {
+				// string option is only for scalar values (and pointer to them)
+				prefixes := f.TypePrefixes
+				if len(prefixes) == 1 && prefixes[0].Kind() == TypePrefixKindPtr {
+					prefixes = nil
+				}
+				if len(prefixes) == 0 {
+					jt, err := g.typeOf(ctx, f, nil)
+					if err != nil {
+						return fmt.Errorf("field %s: %w", f.Name, err)
+					}
+					switch jt.kind {
+					case "string", "bool", "int", "uint", "float":
+						jf.quoted = jt.marshal == "" && jt.unmarshal == ""
+					}
+				}
+			}
+		}
+		*out = append(*out, jf)
+	}
+	return nil
+}
+
+// embedded returns the struct embedded by the Field, or nil if the Field is not struct.
+func (g *JSONMarshalerGenerator) embedded(ctx *jsonCtx, f *Field) (*Schema, bool, error) {
+	isPtr := len(f.TypePrefixes) == 1 && f.TypePrefixes[0].Kind() == TypePrefixKindPtr
+	if (len(f.TypePrefixes) > 0 && !isPtr) || f.Type == nil {
+		return nil, false, nil
+	}
+	if f.Type.IsBasic() {
+		return nil, false, nil
+	}
+	sc := g.schema(ctx, f.Type)
+	if sc == nil {
+		return nil, false, fmt.Errorf("embedded %s is not in the schemas", f.Type.TypeName)
+	}
+	if !sc.IsStruct() || len(sc.TypePrefixes) > 0 {
+		return nil, false, nil
+	}
+	for _, m := range []string{"MarshalJSON", "UnmarshalJSON", "MarshalText", "UnmarshalText"} {
+		if _, ok := sc.Method(m); ok {
+			return nil, false, fmt.Errorf("embedded %s has %s", sc.Name, m)
+		}
+	}
+	return sc, isPtr, nil
+}
+
+// dominantJSONField returns the field which is encoded among the fields with the same name.
+// It returns nil if no field dominates.
+func dominantJSONField(fields []*jsonField) *jsonField {
+	var top []*jsonField
+	for _, f := range fields {
+		switch {
+		case len(top) == 0 || f.depth < top[0].depth:
+			top = []*jsonField{f}
+		case f.depth == top[0].depth:
+			top = append(top, f)
+		}
+	}
+	if len(top) == 1 {
+		return top[0]
+	}
+	var tagged []*jsonField
+	for _, f := range top {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0]
+	}
+	return nil
+}
+
+// isValidJSONName returns whether the name in json tag is valid in the same way as encoding/json.
+func isValidJSONName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+		case !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c > 0x7f):
+			return false
+		}
+	}
+	return true
+}
+
+func (g *JSONMarshalerGenerator) encodeObject(b *strings.Builder, ctx *jsonCtx, fields []*jsonField, x string) error {
+	b.WriteString("buf = append(buf, '{')\n")
+	for _, f := range fields {
+		key, err := json.Marshal(f.name)
+		if err != nil {
+			return err
+		}
+		var conds []string
+		for _, ptr := range f.ptrs {
+			conds = append(conds, x+ptr.path+" != nil")
+		}
+		fctx := ctx
+		if f.omitempty {
+			cond, err := g.nonEmpty(ctx, f.field, x+f.path)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.field.Name, err)
+			}
+			if cond != "" {
+				conds = append(conds, cond)
+				cp := *ctx
+				cp.nonNil = x + f.path
+				fctx = &cp
+			}
+		}
+
+		var inner strings.Builder
+		fmt.Fprintf(&inner, "buf = append(buf, %q...)\n", string(key)+":")
+		if err := g.encode(&inner, fctx, f.field, f.field.TypePrefixes, x+f.path, f.quoted, 0); err != nil {
+			return fmt.Errorf("field %s: %w", f.field.Name, err)
+		}
+		inner.WriteString("buf = append(buf, ',')\n")
+		if len(conds) > 0 {
+			fmt.Fprintf(b, "if %s {\n%s}\n", strings.Join(conds, " && "), inner.String())
+			continue
+		}
+		b.WriteString(inner.String())
+	}
+	b.WriteString("buf = jsonClose(buf, '}')\n")
+	return nil
+}
+
+// nonEmpty returns condition which is true if the value is not empty for omitempty.
+// It returns empty string if the value is never empty like struct.
+func (g *JSONMarshalerGenerator) nonEmpty(ctx *jsonCtx, f *Field, x string) (string, error) {
+	jt, err := g.typeOf(ctx, f, f.TypePrefixes)
+	if err != nil {
+		return "", err
+	}
+	switch jt.kind {
+	case "ptr", "iface":
+		return x + " != nil", nil
+	case "slice", "bytes", "array", "map":
+		return "len(" + x + ") != 0", nil
+	case "string":
+		return "len(" + x + ") != 0", nil
+	case "bool":
+		return x, nil
+	case "int", "uint", "float":
+		return x + " != 0", nil
+	case "struct", "untitled":
+		return "", nil
+	}
+	return "", fmt.Errorf("omitempty is not supported for the type")
+}
+
+// encode writes statements to append JSON of the value x which is the Field with the prefixes.
+func (g *JSONMarshalerGenerator) encode(b *strings.Builder, ctx *jsonCtx, f *Field, prefixes []TypePrefix, x string, quoted bool, depth int) error {
+	jt, err := g.typeOf(ctx, f, prefixes)
+	if err != nil {
+		return err
+	}
+	if jt.marshal != "" {
+		fmt.Fprintf(b, "{\nraw, err := %s.%s()\nif err != nil {\nreturn nil, err\n}\n", derefTrimmed(x), jt.marshal)
+		if jt.marshal == "MarshalText" {
+			b.WriteString("buf = jsonAppendString(buf, string(raw))\n}\n")
+		} else {
+			b.WriteString("if buf, err = jsonAppendRaw(buf, raw); err != nil {\nreturn nil, err\n}\n}\n")
+		}
+		return nil
+	}
+
+	switch jt.kind {
+	case "ptr":
+		nullable := ctx.openNull(b, x)
+		if err := g.encode(b, ctx, f, prefixes[1:], "(*"+x+")", quoted, depth); err != nil {
+			return err
+		}
+		ctx.closeNull(b, nullable)
+	case "string":
+		s := conv(ctx.typeOf(f, prefixes), "string", x)
+		if quoted {
+			fmt.Fprintf(b, "buf = jsonAppendString(buf, string(jsonAppendString(nil, %s)))\n", s)
+			return nil
+		}
+		fmt.Fprintf(b, "buf = jsonAppendString(buf, %s)\n", s)
+	case "bool", "int", "uint", "float":
+		if quoted {
+			b.WriteString("buf = append(buf, '\"')\n")
+		}
+		switch jt.kind {
+		case "bool":
+			fmt.Fprintf(b, "buf = %sAppendBool(buf, %s)\n", ctx.p.qualify("strconv"), conv(ctx.typeOf(f, prefixes), "bool", x))
+		case "int":
+			fmt.Fprintf(b, "buf = %sAppendInt(buf, %s, 10)\n", ctx.p.qualify("strconv"), conv(ctx.typeOf(f, prefixes), "int64", x))
+		case "uint":
+			fmt.Fprintf(b, "buf = %sAppendUint(buf, %s, 10)\n", ctx.p.qualify("strconv"), conv(ctx.typeOf(f, prefixes), "uint64", x))
+		case "float":
+			*ctx.assignsErr = true
+			fmt.Fprintf(b, "if buf, err = jsonAppendFloat(buf, %s, %d); err != nil {\nreturn nil, err\n}\n", conv(ctx.typeOf(f, prefixes), "float64", x), jt.bits)
+		}
+		if quoted {
+			b.WriteString("buf = append(buf, '\"')\n")
+		}
+	case "bytes":
+		nullable := ctx.openNull(b, x)
+		fmt.Fprintf(b, "buf = jsonAppendBase64(buf, %s)\n", x)
+		ctx.closeNull(b, nullable)
+	case "slice", "array":
+		nullable := jt.kind == "slice" && ctx.openNull(b, x)
+		f, prefixes, ctx := g.resolve(ctx, f, prefixes, jt)
+		i := fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(b, "buf = append(buf, '[')\nfor %s := range %s {\n", i, x)
+		if err := g.encode(b, ctx, f, prefixes[1:], x+"["+i+"]", false, depth+1); err != nil {
+			return err
+		}
+		b.WriteString("buf = append(buf, ',')\n}\nbuf = jsonClose(buf, ']')\n")
+		ctx.closeNull(b, nullable)
+	case "map":
+		f, _, ctx := g.resolve(ctx, f, prefixes, jt)
+		if f.Map == nil || f.Map.Key == nil || f.Map.Value == nil {
+			return fmt.Errorf("map without key or value")
+		}
+		kt, err := g.typeOf(ctx, f.Map.Key, f.Map.Key.TypePrefixes)
+		if err != nil {
+			return err
+		}
+		keys, k, v := fmt.Sprintf("keys%d", depth), fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		keyType := ctx.typeOf(f.Map.Key, f.Map.Key.TypePrefixes)
+		strconv := ctx.p.qualify("strconv")
+		var encKey, lookup string
+		switch {
+		case kt.marshal != "" || kt.unmarshal != "":
+			return fmt.Errorf("map key with %s%s is not supported", kt.marshal, kt.unmarshal)
+		case kt.kind == "string":
+			encKey = conv(keyType, "string", k)
+			lookup = conv("string", keyType, k)
+		case kt.kind == "int":
+			encKey = fmt.Sprintf("%sFormatInt(%s, 10)", strconv, conv(keyType, "int64", k))
+			lookup = conv("int64", keyType, "jsonParseInt("+k+")")
+		case kt.kind == "uint":
+			encKey = fmt.Sprintf("%sFormatUint(%s, 10)", strconv, conv(keyType, "uint64", k))
+			lookup = conv("uint64", keyType, "jsonParseUint("+k+")")
+		default:
+			return fmt.Errorf("map key of %s is not supported", kt.kind)
+		}
+		nullable := ctx.openNull(b, x)
+		fmt.Fprintf(b, "%s := make([]string, 0, len(%s))\nfor %s := range %s {\n%s = append(%s, %s)\n}\n", keys, x, k, x, keys, keys, encKey)
+		fmt.Fprintf(b, "%sStrings(%s)\nbuf = append(buf, '{')\n", ctx.p.qualify("sort"), keys)
+		fmt.Fprintf(b, "for _, %s := range %s {\nbuf = jsonAppendString(buf, %s)\nbuf = append(buf, ':')\n%s := %s[%s]\n", k, keys, k, v, x, lookup)
+		if err := g.encode(b, ctx, f.Map.Value, f.Map.Value.TypePrefixes, v, false, depth+1); err != nil {
+			return err
+		}
+		b.WriteString("buf = append(buf, ',')\n}\nbuf = jsonClose(buf, '}')\n")
+		ctx.closeNull(b, nullable)
+	case "struct":
+		if ctx.marshal[jt.sc] {
+			*ctx.assignsErr = true
+			fmt.Fprintf(b, "if buf, err = %s.appendJSON(buf); err != nil {\nreturn nil, err\n}\n", derefTrimmed(x))
+			return nil
+		}
+		return g.encodeFallback(b, ctx, x)
+	case "untitled":
+		fields, err := g.fields(ctx, f.Schema.Fields)
+		if err != nil {
+			return err
+		}
+		return g.encodeObject(b, ctx, fields, x+".")
+	case "iface", "other":
+		return g.encodeFallback(b, ctx, x)
+	default:
+		return fmt.Errorf("%s can not be encoded", jt.kind)
+	}
+	return nil
+}
+
+// encodeFallback writes statements to append JSON of the value x by encoding/json.
+func (g *JSONMarshalerGenerator) encodeFallback(b *strings.Builder, ctx *jsonCtx, x string) error {
+	fmt.Fprintf(b, "{\nraw, err := %sMarshal(%s)\nif err != nil {\nreturn nil, err\n}\nbuf = append(buf, raw...)\n}\n", ctx.p.qualify("encoding/json"), x)
+	return nil
+}
+
+// decodeObject writes statements to decode JSON object into the fields.
+// The d is name of jsonDecoder and null is already consumed.
+func (g *JSONMarshalerGenerator) decodeObject(b *strings.Builder, ctx *jsonCtx, fields []*jsonField, x, d string, depth int) error {
+	first, key := fmt.Sprintf("first%d", depth), fmt.Sprintf("key%d", depth)
+	if depth == 0 {
+		first, key = "first", "key"
+	}
+	fmt.Fprintf(b, "if err := %s.expect('{'); err != nil {\nreturn err\n}\n", d)
+	fmt.Fprintf(b, "for %s := true; ; %s = false {\n", first, first)
+	fmt.Fprintf(b, "if more, err := %s.next('}', %s); err != nil {\nreturn err\n} else if !more {\nbreak\n}\n", d, first)
+	fmt.Fprintf(b, "%s, err := %s.key()\nif err != nil {\nreturn err\n}\n", key, d)
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = fmt.Sprintf("%q", f.name)
+	}
+	fmt.Fprintf(b, "switch jsonFoldName(%s, %s) {\n", key, strings.Join(names, ", "))
+	for i, f := range fields {
+		fmt.Fprintf(b, "case %s:\n", names[i])
+		for _, ptr := range f.ptrs {
+			fmt.Fprintf(b, "if %s%s == nil {\n%s%s = new(%s)\n}\n", x, ptr.path, x, ptr.path, ctx.typeOf(ptr.field, nil))
+		}
+		if err := g.decode(b, ctx, f.field, f.field.TypePrefixes, x+f.path, d, f.quoted, depth); err != nil {
+			return fmt.Errorf("field %s: %w", f.field.Name, err)
+		}
+	}
+	fmt.Fprintf(b, "default:\nif err := %s.skip(); err != nil {\nreturn err\n}\n}\n}\n", d)
+	return nil
+}
+
+// decode writes statements to decode JSON into the value x which is the Field with the prefixes.
+func (g *JSONMarshalerGenerator) decode(b *strings.Builder, ctx *jsonCtx, f *Field, prefixes []TypePrefix, x, d string, quoted bool, depth int) error {
+	jt, err := g.typeOf(ctx, f, prefixes)
+	if err != nil {
+		return err
+	}
+	switch jt.unmarshal {
+	case "UnmarshalJSON":
+		fmt.Fprintf(b, "if raw, err := %s.raw(); err != nil {\nreturn err\n} else if err := %s.UnmarshalJSON(raw); err != nil {\nreturn err\n}\n", d, derefTrimmed(x))
+		return nil
+	case "UnmarshalText":
+		fmt.Fprintf(b, "if v, ok, err := %s.string(); err != nil {\nreturn err\n} else if ok {\nif err := %s.UnmarshalText([]byte(v)); err != nil {\nreturn err\n}\n}\n", d, derefTrimmed(x))
+		return nil
+	}
+
+	if quoted && jt.kind != "ptr" {
+		q := fmt.Sprintf("q%d", depth)
+		fmt.Fprintf(b, "if %s, ok, err := %s.quoted(); err != nil {\nreturn err\n} else if ok {\n", q, d)
+		if err := g.decode(b, ctx, f, prefixes, x, q, false, depth); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "if err := %s.end(); err != nil {\nreturn err\n}\n}\n", q)
+		return nil
+	}
+
+	switch jt.kind {
+	case "ptr":
+		fmt.Fprintf(b, "if ok, err := %s.null(); err != nil {\nreturn err\n} else if ok {\n%s = nil\n} else {\n", d, x)
+		fmt.Fprintf(b, "if %s == nil {\n%s = new(%s)\n}\n", x, x, ctx.typeOf(f, prefixes[1:]))
+		if err := g.decode(b, ctx, f, prefixes[1:], "(*"+x+")", d, quoted, depth); err != nil {
+			return err
+		}
+		b.WriteString("}\n")
+	case "string":
+		fmt.Fprintf(b, "if v, ok, err := %s.string(); err != nil {\nreturn err\n} else if ok {\n%s = %s\n}\n", d, unparen(x), conv("string", ctx.typeOf(f, prefixes), "v"))
+	case "bool":
+		fmt.Fprintf(b, "if v, ok, err := %s.bool(); err != nil {\nreturn err\n} else if ok {\n%s = %s\n}\n", d, unparen(x), conv("bool", ctx.typeOf(f, prefixes), "v"))
+	case "int", "uint", "float":
+		fmt.Fprintf(b, "if v, ok, err := %s.%s(%d); err != nil {\nreturn err\n} else if ok {\n%s = %s\n}\n", d, jt.kind, jt.bits, unparen(x), conv(map[string]string{"int": "int64", "uint": "uint64", "float": "float64"}[jt.kind], ctx.typeOf(f, prefixes), "v"))
+	case "bytes":
+		fmt.Fprintf(b, "if v, err := %s.bytes(); err != nil {\nreturn err\n} else {\n%s = %s\n}\n", d, unparen(x), conv("[]byte", ctx.typeOf(f, prefixes), "v"))
+	case "slice":
+		typ := ctx.typeOf(f, prefixes)
+		rf, rprefixes, rctx := g.resolve(ctx, f, prefixes, jt)
+		i, v := fmt.Sprintf("first%d", depth+1), fmt.Sprintf("v%d", depth)
+		fmt.Fprintf(b, "if ok, err := %s.null(); err != nil {\nreturn err\n} else if ok {\n%s = nil\n} else {\n", d, x)
+		fmt.Fprintf(b, "if err := %s.expect('['); err != nil {\nreturn err\n}\n", d)
+		fmt.Fprintf(b, "if %s == nil {\n%s = %s{}\n} else {\n%s = %s[:0]\n}\n", x, x, typ, x, x)
+		fmt.Fprintf(b, "for %s := true; ; %s = false {\n", i, i)
+		fmt.Fprintf(b, "if more, err := %s.next(']', %s); err != nil {\nreturn err\n} else if !more {\nbreak\n}\n", d, i)
+		fmt.Fprintf(b, "var %s %s\n", v, rctx.typeOf(rf, rprefixes[1:]))
+		if err := g.decode(b, rctx, rf, rprefixes[1:], v, d, false, depth+1); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s = append(%s, %s)\n}\n}\n", x, x, v)
+	case "array":
+		rf, rprefixes, rctx := g.resolve(ctx, f, prefixes, jt)
+		first, i := fmt.Sprintf("first%d", depth+1), fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(b, "if ok, err := %s.null(); err != nil {\nreturn err\n} else if !ok {\n", d)
+		fmt.Fprintf(b, "if err := %s.expect('['); err != nil {\nreturn err\n}\n%s := 0\n", d, i)
+		fmt.Fprintf(b, "for %s := true; ; %s = false {\n", first, first)
+		fmt.Fprintf(b, "if more, err := %s.next(']', %s); err != nil {\nreturn err\n} else if !more {\nbreak\n}\n", d, first)
+		fmt.Fprintf(b, "if %s >= len(%s) {\nif err := %s.skip(); err != nil {\nreturn err\n}\ncontinue\n}\n", i, x, d)
+		if err := g.decode(b, rctx, rf, rprefixes[1:], x+"["+i+"]", d, false, depth+1); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s++\n}\n", i)
+		fmt.Fprintf(b, "for ; %s < len(%s); %s++ {\nvar zero %s\n%s[%s] = zero\n}\n}\n", i, x, i, rctx.typeOf(rf, rprefixes[1:]), x, i)
+	case "map":
+		typ := ctx.typeOf(f, prefixes)
+		rf, _, rctx := g.resolve(ctx, f, prefixes, jt)
+		if rf.Map == nil || rf.Map.Key == nil || rf.Map.Value == nil {
+			return fmt.Errorf("map without key or value")
+		}
+		kt, err := g.typeOf(rctx, rf.Map.Key, rf.Map.Key.TypePrefixes)
+		if err != nil {
+			return err
+		}
+		first, k, v := fmt.Sprintf("first%d", depth+1), fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		keyType := rctx.typeOf(rf.Map.Key, rf.Map.Key.TypePrefixes)
+		var key string
+		switch {
+		case kt.marshal != "" || kt.unmarshal != "":
+			return fmt.Errorf("map key with %s%s is not supported", kt.marshal, kt.unmarshal)
+		case kt.kind == "string":
+			key = conv("string", keyType, k)
+		case kt.kind == "int" || kt.kind == "uint":
+			key = conv(map[string]string{"int": "int64", "uint": "uint64"}[kt.kind], keyType, "n")
+		default:
+			return fmt.Errorf("map key of %s is not supported", kt.kind)
+		}
+		fmt.Fprintf(b, "if ok, err := %s.null(); err != nil {\nreturn err\n} else if ok {\n%s = nil\n} else {\n", d, x)
+		fmt.Fprintf(b, "if err := %s.expect('{'); err != nil {\nreturn err\n}\n", d)
+		fmt.Fprintf(b, "if %s == nil {\n%s = make(%s)\n}\n", x, x, typ)
+		fmt.Fprintf(b, "for %s := true; ; %s = false {\n", first, first)
+		fmt.Fprintf(b, "if more, err := %s.next('}', %s); err != nil {\nreturn err\n} else if !more {\nbreak\n}\n", d, first)
+		fmt.Fprintf(b, "%s, err := %s.key()\nif err != nil {\nreturn err\n}\n", k, d)
+		if kt.kind != "string" {
+			fmt.Fprintf(b, "n, err := %sParse%s(%s, 10, %d)\nif err != nil {\nreturn err\n}\n",
+				ctx.p.qualify("strconv"), map[string]string{"int": "Int", "uint": "Uint"}[kt.kind], k, kt.bits)
+		}
+		fmt.Fprintf(b, "var %s %s\n", v, rctx.typeOf(rf.Map.Value, rf.Map.Value.TypePrefixes))
+		if err := g.decode(b, rctx, rf.Map.Value, rf.Map.Value.TypePrefixes, v, d, false, depth+1); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s[%s] = %s\n}\n}\n", x, key, v)
+	case "struct":
+		if ctx.unmarshal[jt.sc] {
+			fmt.Fprintf(b, "if err := %s.decodeJSON(%s); err != nil {\nreturn err\n}\n", derefTrimmed(x), d)
+			return nil
+		}
+		return g.decodeFallback(b, ctx, x, d)
+	case "untitled":
+		fields, err := g.fields(ctx, f.Schema.Fields)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "if ok, err := %s.null(); err != nil {\nreturn err\n} else if !ok {\n", d)
+		if err := g.decodeObject(b, ctx, fields, x+".", d, depth+1); err != nil {
+			return err
+		}
+		b.WriteString("}\n")
+	case "iface", "other":
+		return g.decodeFallback(b, ctx, x, d)
+	default:
+		return fmt.Errorf("%s can not be decoded", jt.kind)
+	}
+	return nil
+}
+
+// decodeFallback writes statements to decode JSON into the value x by encoding/json.
+func (g *JSONMarshalerGenerator) decodeFallback(b *strings.Builder, ctx *jsonCtx, x, d string) error {
+	fmt.Fprintf(b, "if raw, err := %s.raw(); err != nil {\nreturn err\n} else if err := %sUnmarshal(raw, &%s); err != nil {\nreturn err\n}\n",
+		d, ctx.p.qualify("encoding/json"), x)
+	return nil
+}
+
+// typeOf returns resolved type of the Field with the prefixes.
+func (g *JSONMarshalerGenerator) typeOf(ctx *jsonCtx, f *Field, prefixes []TypePrefix) (*jsonType, error) {
+	if len(prefixes) > 0 {
+		switch prefixes[0].Kind() {
+		case TypePrefixKindPtr:
+			return &jsonType{kind: "ptr"}, nil
+		case TypePrefixKindSlice:
+			if len(prefixes) == 1 {
+				// []byte is encoded as base64 string
+				if elem, err := g.typeOf(ctx, f, nil); err == nil && elem.kind == "uint" && elem.bits == 8 && elem.marshal == "" && elem.unmarshal == "" {
+					return &jsonType{kind: "bytes"}, nil
+				}
+			}
+			return &jsonType{kind: "slice"}, nil
+		case TypePrefixKindArray:
+			return &jsonType{kind: "array"}, nil
+		}
+		return nil, fmt.Errorf("unknown type prefix: %s", prefixes[0])
+	}
+	switch {
+	case f.IsMap():
+		return &jsonType{kind: "map"}, nil
+	case f.IsFunc():
+		return nil, fmt.Errorf("func can not be encoded")
+	case f.IsUntitledInterface:
+		return &jsonType{kind: "iface"}, nil
+	case f.IsUntitledStruct:
+		if f.Schema == nil {
+			return &jsonType{kind: "other"}, nil
+		}
+		return &jsonType{kind: "untitled"}, nil
+	case f.Type == nil:
+		return &jsonType{kind: "other"}, nil
+	}
+
+	t := f.Type
+	if known, ok := jsonKnownTypes[t.Underlying]; ok {
+		jt := *known
+		return &jt, nil
+	}
+	if ctx.typeParams[t.TypeName] {
+		return &jsonType{kind: "other"}, nil
+	}
+	sc := g.schema(ctx, t)
+	if sc == nil {
+		if t.IsBasic() {
+			return jsonBasicType(t.Underlying)
+		}
+		return &jsonType{kind: "other"}, nil
+	}
+
+	jt := &jsonType{kind: "other", sc: sc}
+	switch {
+	case sc.IsInterface:
+		jt.kind = "iface"
+	case sc.IsStruct() && len(sc.TypePrefixes) == 0:
+		jt.kind = "struct"
+	case sc.Map != nil || len(sc.TypePrefixes) > 0:
+		if len(sc.TypePrefixes) > 0 && sc.TypePrefixes[0].Kind() == TypePrefixKindPtr {
+			return nil, fmt.Errorf("named pointer %s is not supported", sc.Name)
+		}
+		def, err := g.typeOf(ctx, &Field{Type: sc.Type, Map: sc.Map}, sc.TypePrefixes)
+		if err != nil {
+			return nil, err
+		}
+		jt.kind = def.kind
+	case sc.Type != nil && sc.Type.IsBasic():
+		basic, err := jsonBasicType(sc.Type.Underlying)
+		if err != nil {
+			return nil, err
+		}
+		jt.kind, jt.bits = basic.kind, basic.bits
+	}
+	for _, m := range []string{"MarshalJSON", "MarshalText"} {
+		if _, ok := sc.Method(m); ok && jt.marshal == "" {
+			jt.marshal = m
+		}
+	}
+	for _, m := range []string{"UnmarshalJSON", "UnmarshalText"} {
+		if _, ok := sc.Method(m); ok && jt.unmarshal == "" {
+			jt.unmarshal = m
+		}
+	}
+	return jt, nil
+}
+
+// resolve returns definition of named slice, array or map like `type Goods []*Good`.
+func (g *JSONMarshalerGenerator) resolve(ctx *jsonCtx, f *Field, prefixes []TypePrefix, jt *jsonType) (*Field, []TypePrefix, *jsonCtx) {
+	if len(prefixes) > 0 || jt.sc == nil || (jt.sc.Map == nil && len(jt.sc.TypePrefixes) == 0) {
+		return f, prefixes, ctx
+	}
+	sc := jt.sc
+	defCtx := *ctx
+	if sc.Type != nil && sc.Type.PkgID != "" && sc.Type.TypeName != sc.Name {
+		defCtx.srcPkgID = sc.Type.PkgID
+	}
+	return &Field{Type: sc.Type, Map: sc.Map}, sc.TypePrefixes, &defCtx
+}
+
+// schema returns the Schema of named type.
+// It returns nil for basic types, type parameters and types out of the Index.
+func (g *JSONMarshalerGenerator) schema(ctx *jsonCtx, t *Type) *Schema {
+	if sc, ok := g.idx.Lookup(t); ok {
+		return sc
+	}
+	if t.PkgID != "" || t.TypeName == string(t.Underlying) || ctx.typeParams[t.TypeName] {
+		return nil
+	}
+	// named type in the same package like `type Status int`
+	for _, sc := range g.idx.Schemas() {
+		if sc.Name == t.TypeName && !isSelfType(sc) {
+			return sc
+		}
+	}
+	return nil
+}
+
+// typeOf returns type expression of the Field with the prefixes.
+func (c *jsonCtx) typeOf(f *Field, prefixes []TypePrefix) string {
+	cp := *f
+	cp.IsVariadic = false
+	cp.TypePrefixes = prefixes
+	return c.p.field(&cp, c.srcPkgID, c.typeParams)
+}
+
+// openNull writes the beginning of the statement to append null if the value x is nil.
+// It returns false and writes nothing if x is known not to be nil.
+func (c *jsonCtx) openNull(b *strings.Builder, x string) bool {
+	if x == c.nonNil {
+		return false
+	}
+	fmt.Fprintf(b, "if %s == nil {\nbuf = append(buf, \"null\"...)\n} else {\n", x)
+	return true
+}
+
+// closeNull closes the statement written by openNull.
+func (c *jsonCtx) closeNull(b *strings.Builder, nullable bool) {
+	if nullable {
+		b.WriteString("}\n")
+	}
+}
+
+// conv returns expression converting x from the type to the other type.
+func conv(from, to, x string) string {
+	x = unparen(x)
+	if from == to {
+		return x
+	}
+	return to + "(" + x + ")"
+}
+
+// unparen trims parentheses around the dereference, which are redundant in arguments and assignments.
+func unparen(x string) string {
+	if strings.HasPrefix(x, "(*") && strings.HasSuffix(x, ")") {
+		return x[1 : len(x)-1]
+	}
+	return x
+}
+
+func jsonBasicType(u UnderlyingType) (*jsonType, error) {
+	switch u {
+	case "string":
+		return &jsonType{kind: "string"}, nil
+	case "bool":
+		return &jsonType{kind: "bool"}, nil
+	case "int":
+		return &jsonType{kind: "int"}, nil
+	case "int8":
+		return &jsonType{kind: "int", bits: 8}, nil
+	case "int16":
+		return &jsonType{kind: "int", bits: 16}, nil
+	case "int32", "rune":
+		return &jsonType{kind: "int", bits: 32}, nil
+	case "int64":
+		return &jsonType{kind: "int", bits: 64}, nil
+	case "uint", "uintptr":
+		return &jsonType{kind: "uint"}, nil
+	case "uint8", "byte":
+		return &jsonType{kind: "uint", bits: 8}, nil
+	case "uint16":
+		return &jsonType{kind: "uint", bits: 16}, nil
+	case "uint32":
+		return &jsonType{kind: "uint", bits: 32}, nil
+	case "uint64":
+		return &jsonType{kind: "uint", bits: 64}, nil
+	case "float32":
+		return &jsonType{kind: "float", bits: 32}, nil
+	case "float64":
+		return &jsonType{kind: "float", bits: 64}, nil
+	case "error", "any", "interface{}":
+		return &jsonType{kind: "iface"}, nil
+	}
+	return nil, fmt.Errorf("%s can not be encoded", u)
+}
+
+// packages used by jsonHelpers
+var jsonHelperImports = []string{"bytes", "encoding/base64", "encoding/json", "fmt", "math", "strconv", "strings", "unicode", "unicode/utf16", "unicode/utf8"}
+
+// jsonHelpers are declarations used by generated MarshalJSON and UnmarshalJSON.
+// Encoding of strings and floats are the same as encoding/json.
+const jsonHelpers = `
+// jsonDecoder decodes JSON for generated UnmarshalJSON.
+type jsonDecoder struct {
+	data []byte
+	pos  int
+}
+
+// peek returns the next byte except spaces, or 0 at the end.
+func (d *jsonDecoder) peek() byte {
+	for d.pos < len(d.data) {
+		switch c := d.data[d.pos]; c {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return c
+		}
+	}
+	return 0
+}
+
+func (d *jsonDecoder) syntaxError() error {
+	if d.peek() == 0 {
+		return fmt.Errorf("json: unexpected end of JSON input")
+	}
+	return fmt.Errorf("json: invalid character %q at offset %d", d.data[d.pos], d.pos)
+}
+
+func (d *jsonDecoder) typeError(want string) error {
+	start := d.pos
+	if err := d.skip(); err != nil {
+		return err
+	}
+	return fmt.Errorf("json: cannot unmarshal %s into %s at offset %d", d.data[start:d.pos], want, start)
+}
+
+// end returns error if the data has more than one value.
+func (d *jsonDecoder) end() error {
+	if d.peek() != 0 {
+		return d.syntaxError()
+	}
+	return nil
+}
+
+// null consumes null and returns true if the next value is null.
+func (d *jsonDecoder) null() (bool, error) {
+	if d.peek() != 'n' {
+		return false, nil
+	}
+	return true, d.literal("null")
+}
+
+func (d *jsonDecoder) literal(lit string) error {
+	if !bytes.HasPrefix(d.data[d.pos:], []byte(lit)) {
+		return d.syntaxError()
+	}
+	d.pos += len(lit)
+	return nil
+}
+
+// expect consumes the beginning of object or array.
+func (d *jsonDecoder) expect(c byte) error {
+	if d.peek() != c {
+		if c == '{' {
+			return d.typeError("object")
+		}
+		return d.typeError("array")
+	}
+	d.pos++
+	return nil
+}
+
+// next consumes separator and returns true if the object or array has the next element.
+func (d *jsonDecoder) next(end byte, first bool) (bool, error) {
+	c := d.peek()
+	if c == end && first {
+		d.pos++
+		return false, nil
+	}
+	if !first {
+		if c == end {
+			d.pos++
+			return false, nil
+		}
+		if c != ',' {
+			return false, d.syntaxError()
+		}
+		d.pos++
+	}
+	return true, nil
+}
+
+// key reads key of object and colon.
+func (d *jsonDecoder) key() (string, error) {
+	if d.peek() != '"' {
+		return "", d.syntaxError()
+	}
+	key, err := d.readString()
+	if err != nil {
+		return "", err
+	}
+	if d.peek() != ':' {
+		return "", d.syntaxError()
+	}
+	d.pos++
+	return key, nil
+}
+
+func (d *jsonDecoder) readString() (string, error) {
+	d.pos++
+	// fast path for strings without escapes
+	for i := d.pos; i < len(d.data); i++ {
+		c := d.data[i]
+		if c == '"' {
+			s := string(d.data[d.pos:i])
+			d.pos = i + 1
+			return s, nil
+		}
+		if c == '\\' || c < 0x20 || c >= utf8.RuneSelf {
+			break
+		}
+	}
+
+	var buf []byte
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			return string(buf), nil
+		case c < 0x20:
+			return "", d.syntaxError()
+		case c == '\\':
+			if d.pos+1 >= len(d.data) {
+				d.pos = len(d.data)
+				return "", d.syntaxError()
+			}
+			switch e := d.data[d.pos+1]; e {
+			case '"', '\\', '/':
+				buf = append(buf, e)
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				r := d.hex4(d.pos + 2)
+				if r < 0 {
+					d.pos++
+					return "", d.syntaxError()
+				}
+				d.pos += 4
+				if utf16.IsSurrogate(r) {
+					r2 := rune(-1)
+					if d.pos+7 < len(d.data) && d.data[d.pos+2] == '\\' && d.data[d.pos+3] == 'u' {
+						r2 = d.hex4(d.pos + 4)
+					}
+					if dec := utf16.DecodeRune(r, r2); dec != unicode.ReplacementChar {
+						r = dec
+						d.pos += 6
+					} else {
+						r = unicode.ReplacementChar
+					}
+				}
+				buf = utf8.AppendRune(buf, r)
+			default:
+				d.pos++
+				return "", d.syntaxError()
+			}
+			d.pos += 2
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			d.pos++
+		default:
+			r, size := utf8.DecodeRune(d.data[d.pos:])
+			if r == utf8.RuneError && size == 1 {
+				buf = utf8.AppendRune(buf, unicode.ReplacementChar)
+			} else {
+				buf = append(buf, d.data[d.pos:d.pos+size]...)
+			}
+			d.pos += size
+		}
+	}
+	return "", d.syntaxError()
+}
+
+// hex4 returns rune of 4 hex digits at the i, or -1 if they are invalid.
+func (d *jsonDecoder) hex4(i int) rune {
+	if i+4 > len(d.data) {
+		return -1
+	}
+	n, err := strconv.ParseUint(string(d.data[i:i+4]), 16, 16)
+	if err != nil {
+		return -1
+	}
+	return rune(n)
+}
+
+func (d *jsonDecoder) number() (string, error) {
+	start := d.pos
+	digits := func() int {
+		n := 0
+		for d.pos < len(d.data) && '0' <= d.data[d.pos] && d.data[d.pos] <= '9' {
+			d.pos++
+			n++
+		}
+		return n
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '-' {
+		d.pos++
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '0' {
+		d.pos++
+	} else if digits() == 0 {
+		return "", d.syntaxError()
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '.' {
+		d.pos++
+		if digits() == 0 {
+			return "", d.syntaxError()
+		}
+	}
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' || d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if digits() == 0 {
+			return "", d.syntaxError()
+		}
+	}
+	return string(d.data[start:d.pos]), nil
+}
+
+// skip skips the next value.
+func (d *jsonDecoder) skip() error {
+	switch c := d.peek(); c {
+	case '{', '[':
+		end := byte('}')
+		if c == '[' {
+			end = ']'
+		}
+		d.pos++
+		for first := true; ; first = false {
+			if more, err := d.next(end, first); err != nil {
+				return err
+			} else if !more {
+				return nil
+			}
+			if c == '{' {
+				if _, err := d.key(); err != nil {
+					return err
+				}
+			}
+			if err := d.skip(); err != nil {
+				return err
+			}
+		}
+	case '"':
+		_, err := d.readString()
+		return err
+	case 't':
+		return d.literal("true")
+	case 'f':
+		return d.literal("false")
+	case 'n':
+		return d.literal("null")
+	}
+	_, err := d.number()
+	return err
+}
+
+// raw returns the next value.
+func (d *jsonDecoder) raw() ([]byte, error) {
+	d.peek()
+	start := d.pos
+	if err := d.skip(); err != nil {
+		return nil, err
+	}
+	return d.data[start:d.pos], nil
+}
+
+// string reads string, ok is false for null.
+func (d *jsonDecoder) string() (string, bool, error) {
+	switch d.peek() {
+	case 'n':
+		return "", false, d.literal("null")
+	case '"':
+		s, err := d.readString()
+		return s, err == nil, err
+	}
+	return "", false, d.typeError("string")
+}
+
+// quoted reads string of string option as decoder, ok is false for null.
+func (d *jsonDecoder) quoted() (*jsonDecoder, bool, error) {
+	s, ok, err := d.string()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &jsonDecoder{data: []byte(s)}, true, nil
+}
+
+// bytes reads base64 string, it returns nil for null.
+func (d *jsonDecoder) bytes() ([]byte, error) {
+	s, ok, err := d.string()
+	if err != nil || !ok {
+		return nil, err
+	}
+	b := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+	n, err := base64.StdEncoding.Decode(b, []byte(s))
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+
+// bool reads boolean, ok is false for null.
+func (d *jsonDecoder) bool() (bool, bool, error) {
+	switch d.peek() {
+	case 'n':
+		return false, false, d.literal("null")
+	case 't':
+		return true, true, d.literal("true")
+	case 'f':
+		return false, true, d.literal("false")
+	}
+	return false, false, d.typeError("bool")
+}
+
+// int reads integer of the bit size, ok is false for null.
+func (d *jsonDecoder) int(bits int) (int64, bool, error) {
+	switch c := d.peek(); {
+	case c == 'n':
+		return 0, false, d.literal("null")
+	case c == '-' || '0' <= c && c <= '9':
+		start := d.pos
+		s, err := d.number()
+		if err != nil {
+			return 0, false, err
+		}
+		n, err := strconv.ParseInt(s, 10, bits)
+		if err != nil {
+			return 0, false, fmt.Errorf("json: cannot unmarshal number %s into int%d at offset %d", s, bits, start)
+		}
+		return n, true, nil
+	}
+	return 0, false, d.typeError("number")
+}
+
+// uint reads unsigned integer of the bit size, ok is false for null.
+func (d *jsonDecoder) uint(bits int) (uint64, bool, error) {
+	switch c := d.peek(); {
+	case c == 'n':
+		return 0, false, d.literal("null")
+	case c == '-' || '0' <= c && c <= '9':
+		start := d.pos
+		s, err := d.number()
+		if err != nil {
+			return 0, false, err
+		}
+		n, err := strconv.ParseUint(s, 10, bits)
+		if err != nil {
+			return 0, false, fmt.Errorf("json: cannot unmarshal number %s into uint%d at offset %d", s, bits, start)
+		}
+		return n, true, nil
+	}
+	return 0, false, d.typeError("number")
+}
+
+// float reads number of the bit size, ok is false for null.
+func (d *jsonDecoder) float(bits int) (float64, bool, error) {
+	switch c := d.peek(); {
+	case c == 'n':
+		return 0, false, d.literal("null")
+	case c == '-' || '0' <= c && c <= '9':
+		start := d.pos
+		s, err := d.number()
+		if err != nil {
+			return 0, false, err
+		}
+		n, err := strconv.ParseFloat(s, bits)
+		if err != nil {
+			return 0, false, fmt.Errorf("json: cannot unmarshal number %s into float%d at offset %d", s, bits, start)
+		}
+		return n, true, nil
+	}
+	return 0, false, d.typeError("number")
+}
+
+// jsonFoldName returns the name which matches the key, exact match takes precedence over case-insensitive one.
+func jsonFoldName(key string, names ...string) string {
+	for _, name := range names {
+		if name == key {
+			return name
+		}
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+	}
+	return key
+}
+
+// jsonClose closes object or array by replacing trailing comma.
+func jsonClose(buf []byte, c byte) []byte {
+	if buf[len(buf)-1] == ',' {
+		buf[len(buf)-1] = c
+		return buf
+	}
+	return append(buf, c)
+}
+
+const jsonHex = "0123456789abcdef"
+
+// jsonAppendString appends JSON string with HTML escaping.
+func jsonAppendString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', jsonHex[c>>4], jsonHex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', 'f', 'f', 'f', 'd')
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', jsonHex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// jsonAppendFloat appends JSON number of the bit size.
+func jsonAppendFloat(buf []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("json: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf, nil
+}
+
+// jsonAppendBase64 appends the bytes as base64 string.
+func jsonAppendBase64(buf, b []byte) []byte {
+	buf = append(buf, '"')
+	n := len(buf)
+	buf = append(buf, make([]byte, base64.StdEncoding.EncodedLen(len(b)))...)
+	base64.StdEncoding.Encode(buf[n:], b)
+	return append(buf, '"')
+}
+
+// jsonAppendRaw appends JSON returned by MarshalJSON with compaction and HTML escaping.
+func jsonAppendRaw(buf, raw []byte) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return nil, err
+	}
+	out := bytes.NewBuffer(buf)
+	json.HTMLEscape(out, compact.Bytes())
+	return out.Bytes(), nil
+}
+
+func jsonParseInt(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+func jsonParseUint(s string) uint64 {
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
+`
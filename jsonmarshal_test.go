@@ -0,0 +1,175 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonMarshalerSrc = `package data
+
+import "time"
+
+type SampleString string
+
+type Good struct {
+	Name      string        ` + "`json:\"name\"`" + `
+	SamplePtr *SampleString ` + "`json:\"sample_ptr,omitempty\"`" + `
+}
+
+type Order struct {
+	Good
+	ID        int64          ` + "`json:\"id,string\"`" + `
+	Name      string         ` + "`json:\"order_name\"`" + `
+	Note      *string        ` + "`json:\"note,omitempty\"`" + `
+	Codes     []string       ` + "`json:\"codes\"`" + `
+	Counts    map[int]uint8  ` + "`json:\"counts,omitempty\"`" + `
+	CreatedAt time.Time
+	Cache     string ` + "`json:\"-\"`" + `
+	private   string
+}
+
+// Custom has its own MarshalJSON and UnmarshalJSON
+type Custom struct{}
+
+func (x Custom) MarshalJSON() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (x *Custom) UnmarshalJSON(data []byte) error {
+	return nil
+}
+`
+
+func TestJSONMarshalerGenerate(t *testing.T) {
+	schemas := parseSource(t, jsonMarshalerSrc)
+
+	got, err := stst.NewJSONMarshalerGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, []*stst.Schema{findSchema(schemas, "Order"), findSchema(schemas, "Custom")})
+	require.NoError(t, err)
+	typeCheckGenerated(t, jsonMarshalerSrc, got)
+
+	src := string(got)
+	for _, want := range []string{
+		"func (x Order) MarshalJSON() ([]byte, error) {\n\treturn x.appendJSON(make([]byte, 0, 128))\n}",
+		"func (x *Order) UnmarshalJSON(data []byte) error {",
+		"\tbuf = append(buf, \"\\\"name\\\":\"...)\n\tbuf = jsonAppendString(buf, x.Good.Name)\n",
+		"\tbuf = append(buf, \"\\\"id\\\":\"...)\n\tbuf = append(buf, '\"')\n\tbuf = strconv.AppendInt(buf, x.ID, 10)\n\tbuf = append(buf, '\"')\n",
+		"\tif x.Note != nil {\n\t\tbuf = append(buf, \"\\\"note\\\":\"...)\n\t\tbuf = jsonAppendString(buf, *x.Note)\n",
+		"\tif len(x.Counts) != 0 {\n\t\tbuf = append(buf, \"\\\"counts\\\":\"...)\n\t\tkeys0 := make([]string, 0, len(x.Counts))\n",
+		"\t\traw, err := x.CreatedAt.MarshalJSON()\n",
+		"\t\tswitch jsonFoldName(key, \"name\", \"sample_ptr\", \"id\", \"order_name\", \"note\", \"codes\", \"counts\", \"CreatedAt\") {\n",
+		"\t\tcase \"sample_ptr\":\n",
+		"type jsonDecoder struct {",
+	} {
+		assert.Contains(t, src, want)
+	}
+	for _, notWant := range []string{
+		"func (x Custom)",
+		"func (x *Custom)",
+		"Cache",
+		"private",
+	} {
+		assert.NotContains(t, src, notWant)
+	}
+}
+
+func TestJSONMarshalerGenerate_TypeCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "time.Time is the only non-basic field",
+			src: `package data
+
+import "time"
+
+type Event struct {
+	ID int64
+	At time.Time
+}
+`,
+		},
+		{
+			name: "json.Marshaler is the only non-basic field",
+			src: `package data
+
+type Money struct {
+	Units int64
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte("0"), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	return nil
+}
+
+type Price struct {
+	Amount Money
+	Label  string
+}
+`,
+		},
+		{
+			name: "float and nested struct",
+			src: `package data
+
+type Point struct {
+	X float64
+	Y float32
+}
+
+type Line struct {
+	From Point
+	To   *Point
+	Tags map[string][]Point
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			schemas := parseSource(t, tt.src)
+			got, err := stst.NewJSONMarshalerGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+			require.NoError(t, err)
+			typeCheckGenerated(t, tt.src, got)
+		})
+	}
+}
+
+func TestJSONMarshalerGenerate_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+	}{
+		{
+			name:  "func",
+			field: "Fn func() `json:\"fn\"`",
+		},
+		{
+			name:  "embedded not in schemas",
+			field: "strings.Builder",
+		},
+		{
+			name:  "map with bool key",
+			field: "Flags map[bool]int",
+		},
+		{
+			name:  "omitempty on unknown type",
+			field: "Ext strings.Builder `json:\"ext,omitempty\"`",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			schemas := parseSource(t, "package data\n\nimport \"strings\"\n\nvar _ strings.Builder\n\ntype Target struct {\n\t"+tt.field+"\n}\n")
+			_, err := stst.NewJSONMarshalerGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+			assert.Error(t, err)
+		})
+	}
+}
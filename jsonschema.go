@@ -0,0 +1,293 @@
+package stst
+
+import (
+	"fmt"
+)
+
+type (
+	// JSONSchema is document of JSON Schema (draft 2020-12).
+	JSONSchema struct {
+		Schema               string                 `json:"$schema,omitempty"`
+		Ref                  string                 `json:"$ref,omitempty"`
+		Title                string                 `json:"title,omitempty"`
+		Description          string                 `json:"description,omitempty"`
+		Type                 any                    `json:"type,omitempty"`
+		Format               string                 `json:"format,omitempty"`
+		ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+		Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+		Required             []string               `json:"required,omitempty"`
+		AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+		Items                *JSONSchema            `json:"items,omitempty"`
+		MinItems             *int                   `json:"minItems,omitempty"`
+		MaxItems             *int                   `json:"maxItems,omitempty"`
+		MinLength            *int                   `json:"minLength,omitempty"`
+		MaxLength            *int                   `json:"maxLength,omitempty"`
+		MinProperties        *int                   `json:"minProperties,omitempty"`
+		MaxProperties        *int                   `json:"maxProperties,omitempty"`
+		Minimum              *float64               `json:"minimum,omitempty"`
+		Maximum              *float64               `json:"maximum,omitempty"`
+		ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+		Enum                 []any                  `json:"enum,omitempty"`
+		AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+		Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+	}
+
+	// JSONSchemaGenerator generates JSON Schema from Schema.
+	JSONSchemaGenerator struct {
+		idx *Index
+		// refPrefix is prefix of `$ref` to named types
+		refPrefix string
+		// validate is whether `validate` tags are reflected or not
+		validate bool
+
+		defs     map[string]*JSONSchema
+		defNames map[*Schema]string
+	}
+)
+
+const (
+	JSONSchemaDraft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+	jsonTagKey = "json"
+)
+
+// NewJSONSchemaGenerator returns JSONSchemaGenerator.
+// The schemas are used to resolve named types referenced by fields.
+func NewJSONSchemaGenerator(schemas []*Schema) *JSONSchemaGenerator {
+	return &JSONSchemaGenerator{
+		idx:       NewIndex(schemas),
+		refPrefix: "#/$defs/",
+	}
+}
+
+// Generate returns JSON Schema for the Schema.
+// Named types referenced from the Schema are put in `$defs`.
+func (g *JSONSchemaGenerator) Generate(sc *Schema) (*JSONSchema, error) {
+	if sc == nil {
+		return nil, fmt.Errorf("schema is nil")
+	}
+	g.defs = map[string]*JSONSchema{}
+	g.defNames = map[*Schema]string{}
+
+	out := g.schemaOf(sc)
+	out.Schema = JSONSchemaDraft202012
+	out.Title = sc.Name
+	if len(g.defs) > 0 {
+		out.Defs = g.defs
+	}
+	return out, nil
+}
+
+func (g *JSONSchemaGenerator) schemaOf(sc *Schema) *JSONSchema {
+	var out *JSONSchema
+	switch {
+	case sc.IsInterface:
+		out = &JSONSchema{}
+	case sc.IsMap():
+		out = g.mapSchema(sc.Map)
+	case sc.IsStruct():
+		out = g.objectSchema(sc)
+	case sc.Type != nil:
+		out = g.typeSchema(sc.Type)
+	default:
+		out = &JSONSchema{}
+	}
+	return g.wrapPrefixes(out, sc.TypePrefixes)
+}
+
+func (g *JSONSchemaGenerator) objectSchema(sc *Schema) *JSONSchema {
+	out := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{},
+	}
+	if sc != nil {
+		g.addProperties(out, sc.Fields, false)
+	}
+	return out
+}
+
+// addProperties adds fields to obj as properties.
+// Like encoding/json, fields of embedded structs are promoted
+// unless the outer struct has fields with the same name.
+func (g *JSONSchemaGenerator) addProperties(obj *JSONSchema, fields []*Field, promoted bool) {
+	var embedded []*Schema
+	for _, f := range fields {
+		var tag *Tag
+		if t, ok := f.Tag(jsonTagKey); ok {
+			tag = t
+		}
+		if tag != nil && tag.Name() == "-" && len(tag.Values) == 1 {
+			continue
+		}
+
+		name := f.Name
+		if tag != nil && tag.Name() != "" {
+			name = tag.Name()
+		} else if f.IsEmbedded {
+			if emb, ok := g.idx.Lookup(f.Type); ok && emb.IsStruct() {
+				embedded = append(embedded, emb)
+				continue
+			}
+			if f.Type != nil {
+				name = f.Type.TypeName
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if _, ok := obj.Properties[name]; ok && promoted {
+			continue
+		}
+
+		prop := g.fieldSchema(f)
+		if prop == nil {
+			continue
+		}
+		required := tag == nil || !tag.HasOption("omitempty")
+		if g.validate {
+			if applyValidateTag(prop, f) {
+				required = true
+			}
+		}
+		obj.Properties[name] = prop
+		if required {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+	for _, emb := range embedded {
+		g.addProperties(obj, emb.Fields, true)
+	}
+}
+
+func (g *JSONSchemaGenerator) fieldSchema(f *Field) *JSONSchema {
+	prefixes := f.TypePrefixes
+	var base *JSONSchema
+	switch {
+	case f.IsFunc():
+		// function can not be encoded
+		return nil
+	case f.IsMap():
+		base = g.mapSchema(f.Map)
+	case f.IsUntitledStruct:
+		base = g.objectSchema(f.Schema)
+	case f.IsUntitledInterface:
+		base = &JSONSchema{}
+	case f.Type != nil:
+		if n := len(prefixes); n > 0 && prefixes[n-1] == TypePrefixSlice && isByte(f.Type) {
+			// []byte is encoded as base64 string, and nil one as null
+			base = &JSONSchema{Type: []string{"string", "null"}, ContentEncoding: "base64"}
+			prefixes = prefixes[:n-1]
+		} else {
+			base = g.typeSchema(f.Type)
+		}
+	default:
+		return nil
+	}
+	if base == nil {
+		return nil
+	}
+	return g.wrapPrefixes(base, prefixes)
+}
+
+// mapSchema returns schema of the map, which is nullable like nil map encoded as null.
+func (g *JSONSchemaGenerator) mapSchema(m *Map) *JSONSchema {
+	out := &JSONSchema{Type: []string{"object", "null"}}
+	if m == nil {
+		return out
+	}
+	if v := g.fieldSchema(m.Value); v != nil {
+		out.AdditionalProperties = v
+	}
+	return out
+}
+
+func (g *JSONSchemaGenerator) typeSchema(t *Type) *JSONSchema {
+	if t.Underlying == "time.Time" {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+	if t.IsBasic() {
+		return basicJSONSchema(t.Underlying)
+	}
+	if sc, ok := g.idx.Lookup(t); ok {
+		return &JSONSchema{Ref: g.refPrefix + g.define(sc, t)}
+	}
+	return &JSONSchema{}
+}
+
+// define puts the Schema in $defs and returns its name.
+func (g *JSONSchemaGenerator) define(sc *Schema, t *Type) string {
+	if name, ok := g.defNames[sc]; ok {
+		return name
+	}
+	name := sc.Name
+	if _, ok := g.defs[name]; ok && t.PkgPlusName != "" {
+		name = t.PkgPlusName
+	}
+	g.defNames[sc] = name
+	// placeholder for recursive types
+	g.defs[name] = &JSONSchema{}
+	def := g.schemaOf(sc)
+	def.Title = sc.Name
+	g.defs[name] = def
+	return name
+}
+
+func (g *JSONSchemaGenerator) wrapPrefixes(base *JSONSchema, prefixes []TypePrefix) *JSONSchema {
+	out := base
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		switch prefixes[i].Kind() {
+		case TypePrefixKindPtr:
+			out = nullableJSONSchema(out)
+		case TypePrefixKindSlice:
+			// nil slice is encoded as null
+			out = nullableJSONSchema(&JSONSchema{Type: "array", Items: out})
+		case TypePrefixKindArray:
+			l, _ := prefixes[i].ArrayLength()
+			out = &JSONSchema{Type: "array", Items: out, MinItems: &l, MaxItems: &l}
+		}
+	}
+	return out
+}
+
+func nullableJSONSchema(s *JSONSchema) *JSONSchema {
+	switch typ := s.Type.(type) {
+	case string:
+		if s.Ref == "" && len(s.AnyOf) == 0 {
+			s.Type = []string{typ, "null"}
+			return s
+		}
+	case nil:
+		if s.Ref == "" && len(s.AnyOf) == 0 {
+			// empty schema accepts null already
+			return s
+		}
+	case []string:
+		return s
+	}
+	return &JSONSchema{
+		AnyOf: []*JSONSchema{s, {Type: "null"}},
+	}
+}
+
+func basicJSONSchema(u UnderlyingType) *JSONSchema {
+	switch u {
+	case "bool":
+		return &JSONSchema{Type: "boolean"}
+	case "string":
+		return &JSONSchema{Type: "string"}
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return &JSONSchema{Type: "integer"}
+	case "float32", "float64":
+		return &JSONSchema{Type: "number"}
+	case "complex64", "complex128":
+		// complex can not be encoded
+		return nil
+	}
+	return &JSONSchema{}
+}
+
+func isByte(t *Type) bool {
+	return t.Underlying == "byte" || t.Underlying == "uint8"
+}
@@ -0,0 +1,239 @@
+package stst_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonSchemaSrc = `package data
+
+import "time"
+
+type (
+	SampleString string
+
+	Good struct {
+		Name      string        ` + "`json:\"name\" db:\"name\"`" + `
+		SamplePtr *SampleString ` + "`json:\"sample_ptr,omitempty\"`" + `
+	}
+
+	Person struct {
+		// comment
+		Name  string ` + "`json:\"name\" bigquery:\"name\"`" + `
+		Age   int    ` + "`json:\"age,omitempty\" bigquery:\"age\"`" + `
+		Sex   string ` + "`json:\"-\" bigquery:\"-\"`" + `
+		Hobby string ` + "`bigquery:\"hobby,nullable\"`" + `
+		Good
+	}
+
+	Animal struct {
+		ID      string         ` + "`json:\"id\" bigquery:\"id\"`" + `
+		Goods   []*Good        ` + "`json:\"goods\"`" + `
+		GoodPtr *Good          ` + "`json:\"good_ptr,omitempty\" bigquery:\"good_ptr\"`" + `
+		Born    time.Time      ` + "`json:\"born\"`" + `
+		Attrs   map[string]int ` + "`json:\"attrs\"`" + `
+		Meta    struct {
+			Note string ` + "`json:\"note\"`" + `
+		} ` + "`json:\"meta\"`" + `
+		strs []string
+		Fn   func(v any)
+	}
+
+	Blob struct {
+		Raw    []byte             ` + "`json:\"raw\"`" + `
+		Labels *map[string]string ` + "`json:\"labels\"`" + `
+	}
+)
+`
+
+func TestJSONSchemaGenerate(t *testing.T) {
+	schemas := parseSource(t, jsonSchemaSrc)
+
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "ok: embedded struct is promoted",
+			schema: "Person",
+			want: `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Person",
+  "type": "object",
+  "properties": {
+    "Hobby": {
+      "type": "string"
+    },
+    "age": {
+      "type": "integer"
+    },
+    "name": {
+      "type": "string"
+    },
+    "sample_ptr": {
+      "type": [
+        "string",
+        "null"
+      ]
+    }
+  },
+  "required": [
+    "name",
+    "Hobby"
+  ]
+}`,
+		},
+		{
+			name:   "ok: defs, arrays, maps and untitled struct",
+			schema: "Animal",
+			want: `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Animal",
+  "type": "object",
+  "properties": {
+    "attrs": {
+      "type": [
+        "object",
+        "null"
+      ],
+      "additionalProperties": {
+        "type": "integer"
+      }
+    },
+    "born": {
+      "type": "string",
+      "format": "date-time"
+    },
+    "good_ptr": {
+      "anyOf": [
+        {
+          "$ref": "#/$defs/Good"
+        },
+        {
+          "type": "null"
+        }
+      ]
+    },
+    "goods": {
+      "type": [
+        "array",
+        "null"
+      ],
+      "items": {
+        "anyOf": [
+          {
+            "$ref": "#/$defs/Good"
+          },
+          {
+            "type": "null"
+          }
+        ]
+      }
+    },
+    "id": {
+      "type": "string"
+    },
+    "meta": {
+      "type": "object",
+      "properties": {
+        "note": {
+          "type": "string"
+        }
+      },
+      "required": [
+        "note"
+      ]
+    }
+  },
+  "required": [
+    "id",
+    "goods",
+    "born",
+    "attrs",
+    "meta"
+  ],
+  "$defs": {
+    "Good": {
+      "title": "Good",
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "sample_ptr": {
+          "type": [
+            "string",
+            "null"
+          ]
+        }
+      },
+      "required": [
+        "name"
+      ]
+    }
+  }
+}`,
+		},
+		{
+			name:   "ok: nil byte slice and map are nullable",
+			schema: "Blob",
+			want: `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Blob",
+  "type": "object",
+  "properties": {
+    "labels": {
+      "type": [
+        "object",
+        "null"
+      ],
+      "additionalProperties": {
+        "type": "string"
+      }
+    },
+    "raw": {
+      "type": [
+        "string",
+        "null"
+      ],
+      "contentEncoding": "base64"
+    }
+  },
+  "required": [
+    "raw",
+    "labels"
+  ]
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := stst.NewJSONSchemaGenerator(schemas)
+			got, err := g.Generate(findSchema(schemas, tt.schema))
+			require.NoError(t, err)
+
+			b, err := json.MarshalIndent(got, "", "  ")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+		})
+	}
+}
+
+func TestJSONSchemaGenerate_SameNameInOtherPackage(t *testing.T) {
+	other := loadSource(t, "example.com/other", "package other\n\ntype Tags []int\n")
+	src := "package data\n\nimport \"example.com/other\"\n\ntype Tags []string\n\ntype Item struct {\n\tMine  Tags\n\tOther other.Tags\n}\n"
+	// only schemas of the package are given, so other.Tags is unknown
+	schemas := stst.NewParser(loadSource(t, testPkg, src, other)).Parse()
+
+	got, err := stst.NewJSONSchemaGenerator(schemas).Generate(findSchema(schemas, "Item"))
+	require.NoError(t, err)
+	assert.Equal(t, &stst.JSONSchema{Ref: "#/$defs/Tags"}, got.Properties["Mine"])
+	assert.Equal(t, &stst.JSONSchema{}, got.Properties["Other"])
+}
@@ -0,0 +1,124 @@
+package stst
+
+import (
+	"go/types"
+	"runtime"
+	"sort"
+)
+
+type (
+	// FieldOrder is suggested order of fields of struct which minimizes padding.
+	FieldOrder struct {
+		Fields []*Field
+		// Size is size of the struct with the order
+		Size int64
+		// Saved is bytes saved from the current order
+		Saved int64
+	}
+)
+
+// setLayouts sets size, alignment and offsets to the structs and their fields if Layout is enabled.
+func (p *Parser) setLayouts(schemas []*Schema) {
+	if !p.Layout || p.Pkg.Types == nil {
+		return
+	}
+	sizes := p.Pkg.TypesSizes
+	if sizes == nil {
+		sizes = types.SizesFor("gc", runtime.GOARCH)
+	}
+	for _, sc := range schemas {
+		if !sc.IsStruct() || len(sc.TypePrefixes) > 0 || len(sc.TypeParams) > 0 {
+			continue
+		}
+		obj, ok := p.Pkg.Types.Scope().Lookup(sc.Name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		sc.Size, sc.Align = sizes.Sizeof(st), sizes.Alignof(st)
+		setFieldLayouts(sizes, st, sc.Fields)
+	}
+}
+
+// setFieldLayouts sets layouts of the fields including ones of untitled structs.
+// Fields of the struct which are not parsed (like channels) are skipped.
+func setFieldLayouts(sizes types.Sizes, st *types.Struct, fields []*Field) {
+	vars := make([]*types.Var, st.NumFields())
+	for i := range vars {
+		vars[i] = st.Field(i)
+	}
+	offsets := sizes.Offsetsof(vars)
+	var i int
+	for _, f := range fields {
+		for i < len(vars) && vars[i].Name() != f.Name {
+			i++
+		}
+		if i == len(vars) {
+			return
+		}
+		t := vars[i].Type()
+		f.Size, f.Align, f.Offset = sizes.Sizeof(t), sizes.Alignof(t), offsets[i]
+		if nested, ok := t.(*types.Struct); ok && f.Schema != nil {
+			setFieldLayouts(sizes, nested, f.Schema.Fields)
+		}
+		i++
+	}
+}
+
+// SuggestFieldOrder returns order of fields of the struct which minimizes padding.
+// Fields are ordered by zero size first, and then by alignment and size in descending order.
+// It returns false if the Schema does not have layout (see Parser.Layout), some of the fields are not parsed
+// or the order does not save bytes.
+func SuggestFieldOrder(sc *Schema) (*FieldOrder, bool) {
+	if sc.Align == 0 || !sc.IsStruct() {
+		return nil, false
+	}
+	// some of fields are not parsed (like channels)
+	if structSize(sc.Fields, sc.Align) != sc.Size {
+		return nil, false
+	}
+	fields := append([]*Field{}, sc.Fields...)
+	sort.SliceStable(fields, func(i, j int) bool {
+		fi, fj := fields[i], fields[j]
+		if (fi.Size == 0) != (fj.Size == 0) {
+			return fi.Size == 0
+		}
+		if fi.Align != fj.Align {
+			return fi.Align > fj.Align
+		}
+		return fi.Size > fj.Size
+	})
+	size := structSize(fields, sc.Align)
+	if size >= sc.Size {
+		return nil, false
+	}
+	return &FieldOrder{
+		Fields: fields,
+		Size:   size,
+		Saved:  sc.Size - size,
+	}, true
+}
+
+// structSize returns size of struct which has the fields in the order.
+func structSize(fields []*Field, align int64) int64 {
+	var off int64
+	for _, f := range fields {
+		off = alignUp(off, f.Align)
+		off += f.Size
+	}
+	// gc pads zero size field at the end so that its address does not point to the next object
+	if n := len(fields); n > 0 && fields[n-1].Size == 0 && off > 0 {
+		off++
+	}
+	return alignUp(off, align)
+}
+
+func alignUp(n, align int64) int64 {
+	if align <= 1 {
+		return n
+	}
+	return (n + align - 1) / align * align
+}
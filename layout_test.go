@@ -0,0 +1,117 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const layoutSrc = `package model
+
+import "time"
+
+type Padded struct {
+	A bool
+	B int64
+	C bool
+	D int32
+	E struct {
+		X bool
+		Y int16
+	}
+	F time.Time
+	G []string
+	H struct{}
+}
+
+type Packed struct {
+	ID   int64
+	Name string
+	OK   bool
+}
+
+type Chan struct {
+	A  bool
+	Ch chan int
+	B  int64
+}
+
+type Box[T any] struct {
+	V T
+}
+
+type Status int
+`
+
+func TestParser_Layout(t *testing.T) {
+	pkg := loadSource(t, "example.com/model", layoutSrc)
+	p := stst.NewParser(pkg)
+	p.Layout = true
+	schemas := p.Parse()
+
+	padded := findSchema(schemas, "Padded")
+	assert.Equal(t, int64(88), padded.Size)
+	assert.Equal(t, int64(8), padded.Align)
+	type layout struct {
+		name                string
+		size, align, offset int64
+	}
+	var got []layout
+	for _, f := range padded.Fields {
+		got = append(got, layout{f.Name, f.Size, f.Align, f.Offset})
+	}
+	assert.Equal(t, []layout{
+		{"A", 1, 1, 0},
+		{"B", 8, 8, 8},
+		{"C", 1, 1, 16},
+		{"D", 4, 4, 20},
+		{"E", 4, 2, 24},
+		{"F", 24, 8, 32},
+		{"G", 24, 8, 56},
+		{"H", 0, 1, 80},
+	}, got)
+	nested := padded.Fields[4].Schema.Fields
+	assert.Equal(t, int64(2), nested[1].Offset)
+
+	// chan is not parsed as field, but offsets of others are recorded
+	ch := findSchema(schemas, "Chan")
+	require.Len(t, ch.Fields, 2)
+	assert.Equal(t, int64(16), ch.Fields[1].Offset)
+	assert.Equal(t, int64(24), ch.Size)
+
+	assert.Zero(t, findSchema(schemas, "Box").Align)
+	assert.Zero(t, findSchema(schemas, "Status").Align)
+
+	// not recorded by default
+	for _, sc := range stst.NewParser(loadSource(t, "example.com/model", layoutSrc)).Parse() {
+		assert.Zero(t, sc.Align, sc.Name)
+	}
+}
+
+func TestSuggestFieldOrder(t *testing.T) {
+	pkg := loadSource(t, "example.com/model", layoutSrc)
+	p := stst.NewParser(pkg)
+	p.Layout = true
+	schemas := p.Parse()
+
+	order, ok := stst.SuggestFieldOrder(findSchema(schemas, "Padded"))
+	require.True(t, ok)
+	var names []string
+	for _, f := range order.Fields {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"H", "F", "G", "B", "D", "E", "A", "C"}, names)
+	assert.Equal(t, int64(72), order.Size)
+	assert.Equal(t, int64(16), order.Saved)
+
+	_, ok = stst.SuggestFieldOrder(findSchema(schemas, "Packed"))
+	assert.False(t, ok)
+	// fields are not parsed
+	_, ok = stst.SuggestFieldOrder(findSchema(schemas, "Chan"))
+	assert.False(t, ok)
+	// layout is not recorded
+	_, ok = stst.SuggestFieldOrder(findSchema(stst.NewParser(loadSource(t, "example.com/model", layoutSrc)).Parse(), "Padded"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,349 @@
+package stst
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	// LintRule checks a Schema and reports issues to the LintPass.
+	LintRule interface {
+		// Name is used in LintConfig and suppression comments, like `json-tag-required`.
+		Name() string
+		// Doc is short description of the rule.
+		Doc() string
+		// Check returns error only if the rule can not run (like invalid options).
+		Check(pass *LintPass, sc *Schema) error
+	}
+
+	// LintRuleConfig is configuration of a rule.
+	LintRuleConfig struct {
+		Disabled bool `json:"disabled,omitempty"`
+		// Options are rule specific options like `{"keys": "json,db"}`
+		Options map[string]string `json:"options,omitempty"`
+		// Exclude are names of schemas like `Legacy` or fields like `Legacy.UserID` which are not checked
+		Exclude []string `json:"exclude,omitempty"`
+	}
+
+	// LintConfig is configuration of rules by their names.
+	// Rules which are not in the config are enabled with default options.
+	LintConfig map[string]*LintRuleConfig
+
+	// LintIssue is a violation of a rule.
+	LintIssue struct {
+		Rule   string `json:"rule"`
+		Schema string `json:"schema"`
+		// Field is empty if the issue is of the Schema itself
+		Field   string `json:"field,omitempty"`
+		Message string `json:"message"`
+		// Pos is recorded if the schemas are parsed with Parser.Positions
+		Pos token.Position `json:"pos"`
+	}
+
+	// LintPass is passed to LintRule.Check.
+	LintPass struct {
+		// Index has all linted schemas
+		Index *Index
+
+		rule    LintRule
+		config  *LintRuleConfig
+		issues  []*LintIssue
+		exclude map[string]bool
+	}
+
+	// Linter runs rules over schemas.
+	//
+	// Issues can be suppressed by a comment `//stst:lint-ignore rule-a,rule-b` on the Schema or the Field
+	// (doc or line comment). All rules are suppressed if names are omitted.
+	// Suppression on the Schema applies to its fields too.
+	Linter struct {
+		rules  []LintRule
+		config LintConfig
+	}
+
+	lintJSONTagRequired     struct{}
+	lintTagSnakeCase        struct{}
+	lintBigQueryNullablePtr struct{}
+	lintDuplicateTagName    struct{}
+)
+
+const lintIgnoreDirective = "//stst:lint-ignore"
+
+var (
+	lintSnakeCaseReg   = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	lintDefaultTagKeys = []string{jsonTagKey, bigQueryTagKey, dbTagKey}
+)
+
+// DefaultLintRules returns built-in rules.
+//   - json-tag-required: exported fields of structs have `json` tags (option `schemas`: regexp of names of structs to check)
+//   - tag-snake-case: names in tags are snake_case (option `keys`: comma separated tag keys, default `json,bigquery,db`)
+//   - bigquery-nullable-pointer: pointer fields with `bigquery` tags have `nullable` option
+//   - duplicate-tag-name: fields of a struct do not have the same name in tags (option `keys` as tag-snake-case)
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		lintJSONTagRequired{},
+		lintTagSnakeCase{},
+		lintBigQueryNullablePtr{},
+		lintDuplicateTagName{},
+	}
+}
+
+// NewLinter returns Linter with the rules, or DefaultLintRules if no rule is given.
+// It returns error if the config has unknown rules.
+func NewLinter(config LintConfig, rules ...LintRule) (*Linter, error) {
+	if len(rules) == 0 {
+		rules = DefaultLintRules()
+	}
+	names := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if names[r.Name()] {
+			return nil, fmt.Errorf("duplicated lint rule: %s", r.Name())
+		}
+		names[r.Name()] = true
+	}
+	for _, name := range sortedKeys(config) {
+		if !names[name] {
+			return nil, fmt.Errorf("unknown lint rule in config: %s", name)
+		}
+	}
+	return &Linter{
+		rules:  rules,
+		config: config,
+	}, nil
+}
+
+// Lint runs enabled rules over the schemas.
+// Issues are sorted by their positions if they are recorded, otherwise in order of schemas and rules.
+func (l *Linter) Lint(schemas []*Schema) ([]*LintIssue, error) {
+	idx := NewIndex(schemas)
+	passes := make([]*LintPass, 0, len(l.rules))
+	for _, r := range l.rules {
+		cfg := l.config[r.Name()]
+		if cfg == nil {
+			cfg = &LintRuleConfig{}
+		}
+		if cfg.Disabled {
+			continue
+		}
+		pass := &LintPass{
+			Index:   idx,
+			rule:    r,
+			config:  cfg,
+			exclude: make(map[string]bool, len(cfg.Exclude)),
+		}
+		for _, e := range cfg.Exclude {
+			pass.exclude[e] = true
+		}
+		passes = append(passes, pass)
+	}
+
+	var out []*LintIssue
+	for _, sc := range schemas {
+		for _, pass := range passes {
+			pass.issues = nil
+			if err := pass.rule.Check(pass, sc); err != nil {
+				return nil, fmt.Errorf("lint: %s: %s: %w", pass.rule.Name(), sc.Name, err)
+			}
+			out = append(out, pass.issues...)
+		}
+	}
+	for _, issue := range out {
+		if !issue.Pos.IsValid() {
+			return out, nil
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := out[i].Pos, out[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+	return out, nil
+}
+
+// Option returns the option of the rule, or def if it is not configured.
+func (p *LintPass) Option(name, def string) string {
+	if v, ok := p.config.Options[name]; ok {
+		return v
+	}
+	return def
+}
+
+// Reportf reports an issue of the Schema, or its Field if f is not nil.
+// Issues suppressed by comments or excluded by the config are ignored.
+func (p *LintPass) Reportf(sc *Schema, f *Field, format string, args ...any) {
+	if p.exclude[sc.Name] || lintIgnored(p.rule.Name(), sc.Doc, sc.Comment) {
+		return
+	}
+	issue := &LintIssue{
+		Rule:    p.rule.Name(),
+		Schema:  sc.Name,
+		Message: fmt.Sprintf(format, args...),
+		Pos:     sc.Pos,
+	}
+	if f != nil {
+		if p.exclude[sc.Name+"."+f.Name] || lintIgnored(p.rule.Name(), f.Doc, f.Comment) {
+			return
+		}
+		issue.Field = f.Name
+		issue.Pos = f.Pos
+	}
+	p.issues = append(p.issues, issue)
+}
+
+// String returns the issue like `model.go:10:2: User.Name: no json tag (json-tag-required)`.
+func (i *LintIssue) String() string {
+	var b strings.Builder
+	if i.Pos.IsValid() {
+		b.WriteString(i.Pos.String() + ": ")
+	}
+	b.WriteString(i.Schema)
+	if i.Field != "" {
+		b.WriteString("." + i.Field)
+	}
+	fmt.Fprintf(&b, ": %s (%s)", i.Message, i.Rule)
+	return b.String()
+}
+
+// lintIgnored returns whether the comments suppress the rule.
+func lintIgnored(rule string, comments ...[]string) bool {
+	for _, cs := range comments {
+		for _, c := range cs {
+			if !strings.HasPrefix(c, lintIgnoreDirective) {
+				continue
+			}
+			rest := strings.TrimPrefix(c, lintIgnoreDirective)
+			if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				continue
+			}
+			names := strings.Fields(rest)
+			if len(names) == 0 {
+				return true
+			}
+			for _, n := range strings.Split(names[0], ",") {
+				if n == rule {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// lintStructFields returns fields of the struct, or nil if the Schema is not struct.
+func lintStructFields(sc *Schema) []*Field {
+	if !sc.IsStruct() || len(sc.TypePrefixes) > 0 {
+		return nil
+	}
+	return sc.Fields
+}
+
+func lintTagKeys(pass *LintPass) []string {
+	v := pass.Option("keys", "")
+	if v == "" {
+		return lintDefaultTagKeys
+	}
+	var out []string
+	for _, k := range strings.Split(v, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func (lintJSONTagRequired) Name() string { return "json-tag-required" }
+
+func (lintJSONTagRequired) Doc() string { return "exported fields of structs have json tags" }
+
+func (r lintJSONTagRequired) Check(pass *LintPass, sc *Schema) error {
+	if v := pass.Option("schemas", ""); v != "" {
+		reg, err := regexp.Compile(v)
+		if err != nil {
+			return fmt.Errorf("invalid option schemas: %w", err)
+		}
+		if !reg.MatchString(sc.Name) {
+			return nil
+		}
+	}
+	for _, f := range lintStructFields(sc) {
+		// fields of embedded structs are promoted
+		if !f.IsExported() || f.IsEmbedded {
+			continue
+		}
+		if _, ok := f.Tag(jsonTagKey); !ok {
+			pass.Reportf(sc, f, "no json tag")
+		}
+	}
+	return nil
+}
+
+func (lintTagSnakeCase) Name() string { return "tag-snake-case" }
+
+func (lintTagSnakeCase) Doc() string { return "names in tags are snake_case" }
+
+func (r lintTagSnakeCase) Check(pass *LintPass, sc *Schema) error {
+	keys := lintTagKeys(pass)
+	for _, f := range lintStructFields(sc) {
+		for _, key := range keys {
+			t, ok := f.Tag(key)
+			if !ok || t.Name() == "" || t.Name() == "-" {
+				continue
+			}
+			if !lintSnakeCaseReg.MatchString(t.Name()) {
+				pass.Reportf(sc, f, "%s tag %q is not snake_case, use %q", key, t.Name(), toSnakeCase(t.Name()))
+			}
+		}
+	}
+	return nil
+}
+
+func (lintBigQueryNullablePtr) Name() string { return "bigquery-nullable-pointer" }
+
+func (lintBigQueryNullablePtr) Doc() string {
+	return "pointer fields with bigquery tags have nullable option"
+}
+
+func (r lintBigQueryNullablePtr) Check(pass *LintPass, sc *Schema) error {
+	for _, f := range lintStructFields(sc) {
+		t, ok := f.Tag(bigQueryTagKey)
+		if !ok || t.Name() == "-" || len(f.TypePrefixes) == 0 || f.TypePrefixes[0] != TypePrefixPtr {
+			continue
+		}
+		if !t.HasOption(bigQueryNullableOption) {
+			pass.Reportf(sc, f, "pointer field does not have %s option in bigquery tag", bigQueryNullableOption)
+		}
+	}
+	return nil
+}
+
+func (lintDuplicateTagName) Name() string { return "duplicate-tag-name" }
+
+func (lintDuplicateTagName) Doc() string {
+	return "fields of a struct do not have the same name in tags"
+}
+
+func (r lintDuplicateTagName) Check(pass *LintPass, sc *Schema) error {
+	for _, key := range lintTagKeys(pass) {
+		seen := map[string]*Field{}
+		for _, f := range lintStructFields(sc) {
+			t, ok := f.Tag(key)
+			if !ok || t.Name() == "" || t.Name() == "-" {
+				continue
+			}
+			if other, ok := seen[t.Name()]; ok {
+				pass.Reportf(sc, f, "%s tag %q is also used by %s", key, t.Name(), other.Name)
+				continue
+			}
+			seen[t.Name()] = f
+		}
+	}
+	return nil
+}
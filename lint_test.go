@@ -0,0 +1,139 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lintNoComment reports schemas without comments.
+type lintNoComment struct{}
+
+func (lintNoComment) Name() string { return "no-comment" }
+
+func (lintNoComment) Doc() string { return "schemas have comments" }
+
+func (lintNoComment) Check(pass *stst.LintPass, sc *stst.Schema) error {
+	if len(sc.Doc) == 0 && len(sc.Comment) == 0 {
+		pass.Reportf(sc, nil, "no comment")
+	}
+	return nil
+}
+
+const lintSrc = `package data
+
+type Status int
+
+// Old is deprecated.
+//stst:lint-ignore json-tag-required,tag-snake-case
+type Old struct {
+	Name string ` + "`db:\"Name\"`" + `
+}
+
+// Base is embedded.
+type Base struct{}
+
+type User struct {
+	Base
+	ID       int64  ` + "`json:\"id\" db:\"id\"`" + `
+	Email    string
+	UserName string ` + "`json:\"userName\" db:\"user_name\"`" + `
+	Age      *int   ` + "`json:\"age\" bigquery:\"age\"`" + `
+	Nick     string ` + "`json:\"id\"`" + `
+	Legacy   string //stst:lint-ignore json-tag-required
+	//stst:lint-ignore
+	Skip    string ` + "`json:\"SKIP\"`" + `
+	private string
+}
+`
+
+func lintSchemas(t *testing.T) []*stst.Schema {
+	p := stst.NewParser(loadSource(t, testPkg, lintSrc))
+	p.Positions = true
+	return p.Parse()
+}
+
+func TestLinter(t *testing.T) {
+	l, err := stst.NewLinter(nil)
+	require.NoError(t, err)
+	got, err := l.Lint(lintSchemas(t))
+	require.NoError(t, err)
+
+	var strs []string
+	for _, issue := range got {
+		strs = append(strs, issue.String())
+	}
+	assert.Equal(t, []string{
+		`model.go:17:2: User.Email: no json tag (json-tag-required)`,
+		`model.go:18:2: User.UserName: json tag "userName" is not snake_case, use "user_name" (tag-snake-case)`,
+		`model.go:19:2: User.Age: pointer field does not have nullable option in bigquery tag (bigquery-nullable-pointer)`,
+		`model.go:20:2: User.Nick: json tag "id" is also used by ID (duplicate-tag-name)`,
+	}, strs)
+}
+
+func TestLinter_Config(t *testing.T) {
+	tests := []struct {
+		name   string
+		config stst.LintConfig
+		rules  []stst.LintRule
+		want   []string
+	}{
+		{
+			name: "disabled and options",
+			config: stst.LintConfig{
+				"json-tag-required":         {Options: map[string]string{"schemas": "^Old$"}},
+				"tag-snake-case":            {Options: map[string]string{"keys": "db"}},
+				"bigquery-nullable-pointer": {Disabled: true},
+				"duplicate-tag-name":        {Disabled: true},
+			},
+			want: nil,
+		},
+		{
+			name: "exclude",
+			config: stst.LintConfig{
+				"json-tag-required":  {Exclude: []string{"User.Email"}},
+				"tag-snake-case":     {Exclude: []string{"User"}},
+				"duplicate-tag-name": {Options: map[string]string{"keys": "db"}},
+			},
+			want: []string{"User.Age: bigquery-nullable-pointer"},
+		},
+		{
+			name:  "custom rule",
+			rules: []stst.LintRule{lintNoComment{}},
+			want:  []string{"Status: no-comment", "User: no-comment"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := stst.NewLinter(tt.config, tt.rules...)
+			require.NoError(t, err)
+			got, err := l.Lint(lintSchemas(t))
+			require.NoError(t, err)
+			var strs []string
+			for _, issue := range got {
+				s := issue.Schema
+				if issue.Field != "" {
+					s += "." + issue.Field
+				}
+				strs = append(strs, s+": "+issue.Rule)
+			}
+			assert.Equal(t, tt.want, strs)
+		})
+	}
+}
+
+func TestLinter_Error(t *testing.T) {
+	_, err := stst.NewLinter(stst.LintConfig{"unknown": {}})
+	assert.Error(t, err)
+
+	_, err = stst.NewLinter(nil, lintNoComment{}, lintNoComment{})
+	assert.Error(t, err)
+
+	l, err := stst.NewLinter(stst.LintConfig{"json-tag-required": {Options: map[string]string{"schemas": "("}}})
+	require.NoError(t, err)
+	_, err = l.Lint(lintSchemas(t))
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package stst
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// MockGenerator generates mocks of interfaces with testify (github.com/stretchr/testify/mock).
+	// Methods of embedded interfaces are expanded, and generic interfaces become generic mocks.
+	MockGenerator struct {
+		idx *Index
+	}
+
+	mockMethod struct {
+		name string
+		fn   *Func
+		// srcPkgID is package where the method is declared
+		srcPkgID string
+	}
+)
+
+const testifyMockPkg = "github.com/stretchr/testify/mock"
+
+// NewMockGenerator returns MockGenerator.
+// The schemas are used to resolve embedded interfaces.
+func NewMockGenerator(schemas []*Schema) *MockGenerator {
+	return &MockGenerator{
+		idx: NewIndex(schemas),
+	}
+}
+
+// Generate returns Go source of mocks for interfaces in the schemas.
+// Mock of `Xxx` is named `MockXxx` and written in the pkg.
+func (g *MockGenerator) Generate(pkg GoPackage, schemas []*Schema) ([]byte, error) {
+	p := newGoTypePrinter(pkg)
+	var b strings.Builder
+	for _, sc := range schemas {
+		if !sc.IsInterface {
+			continue
+		}
+		if err := g.mock(&b, p, sc); err != nil {
+			return nil, fmt.Errorf("mock: %s: %w", sc.Name, err)
+		}
+	}
+	out, err := p.file(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("mock: %w", err)
+	}
+	return out, nil
+}
+
+func (g *MockGenerator) mock(b *strings.Builder, p *goTypePrinter, sc *Schema) error {
+	var srcPkgID string
+	if sc.Type != nil {
+		srcPkgID = sc.Type.PkgID
+	}
+	methods, err := g.methods(sc.Fields, srcPkgID, map[*Schema]bool{sc: true}, map[string]bool{})
+	if err != nil {
+		return err
+	}
+
+	mockPkg := p.addImport(testifyMockPkg)
+	name := "Mock" + sc.Name
+	decl, args := typeParamsDecl(sc.TypeParams)
+	typeParams := typeParamNames(sc.TypeParams)
+
+	fmt.Fprintf(b, "// %s is a mock of %s.\n", name, sc.Name)
+	fmt.Fprintf(b, "type %s%s struct {\n\t%s.Mock\n}\n\n", name, decl, mockPkg)
+	if len(sc.TypeParams) == 0 {
+		fmt.Fprintf(b, "var _ %s%s = (*%s)(nil)\n\n", p.qualify(srcPkgID), sc.Name, name)
+	}
+
+	fmt.Fprintf(b, "// New%s returns %s which asserts expectations at the end of the test.\n", name, name)
+	fmt.Fprintf(b, "func New%s%s(t interface {\n\t%s.TestingT\n\tCleanup(func())\n}) *%s%s {\n", name, decl, mockPkg, name, args)
+	fmt.Fprintf(b, "m := &%s%s{}\n", name, args)
+	b.WriteString("m.Mock.Test(t)\n")
+	b.WriteString("t.Cleanup(func() { m.AssertExpectations(t) })\n")
+	b.WriteString("return m\n}\n\n")
+
+	for _, m := range methods {
+		g.method(b, p, name+args, m, typeParams)
+	}
+	return nil
+}
+
+func (g *MockGenerator) method(b *strings.Builder, p *goTypePrinter, recv string, m mockMethod, typeParams map[string]bool) {
+	params := make([]string, len(m.fn.Args))
+	names := make([]string, len(m.fn.Args))
+	for i, a := range m.fn.Args {
+		names[i] = fmt.Sprintf("arg%d", i)
+		params[i] = names[i] + " " + p.field(a, m.srcPkgID, typeParams)
+	}
+	results := make([]string, len(m.fn.Results))
+	for i, r := range m.fn.Results {
+		results[i] = p.field(r, m.srcPkgID, typeParams)
+	}
+
+	fmt.Fprintf(b, "// %s mocks base method.\n", m.name)
+	fmt.Fprintf(b, "func (m *%s) %s(%s)", recv, m.name, strings.Join(params, ", "))
+	switch len(results) {
+	case 0:
+	case 1:
+		b.WriteString(" " + results[0])
+	default:
+		b.WriteString(" (" + strings.Join(results, ", ") + ")")
+	}
+	b.WriteString(" {\n")
+
+	// variadic argument is passed as a slice
+	called := "m.Called(" + strings.Join(names, ", ") + ")"
+	if len(results) == 0 {
+		b.WriteString(called + "\n}\n\n")
+		return
+	}
+	b.WriteString("ret := " + called + "\n")
+
+	rets := make([]string, len(results))
+	for i, r := range m.fn.Results {
+		if r.Type != nil && r.Type.Underlying == "error" && len(r.TypePrefixes) == 0 {
+			rets[i] = fmt.Sprintf("ret.Error(%d)", i)
+			continue
+		}
+		rets[i] = fmt.Sprintf("r%d", i)
+		fmt.Fprintf(b, "r%d, _ := ret.Get(%d).(%s)\n", i, i, results[i])
+	}
+	b.WriteString("return " + strings.Join(rets, ", ") + "\n}\n\n")
+}
+
+// methods returns methods of interface.
+// Methods of embedded interfaces are expanded.
+func (g *MockGenerator) methods(fields []*Field, srcPkgID string, visiting map[*Schema]bool, seen map[string]bool) ([]mockMethod, error) {
+	var out []mockMethod
+	for _, f := range fields {
+		if f.IsEmbedded {
+			if f.Type != nil && f.Type.Underlying == "error" && len(f.TypePrefixes) == 0 {
+				if !seen["Error"] {
+					seen["Error"] = true
+					out = append(out, mockMethod{
+						name: "Error",
+						fn:   &Func{Results: []*Field{{Type: &Type{Underlying: "string", TypeName: "string"}}}},
+					})
+				}
+				continue
+			}
+			emb := g.embedded(f.Type)
+			if emb == nil || !emb.IsInterface {
+				return nil, fmt.Errorf("unknown embedded interface: %s", f.Name)
+			}
+			if visiting[emb] {
+				continue
+			}
+			visiting[emb] = true
+			embPkgID := srcPkgID
+			if emb.Type != nil && emb.Type.PkgID != "" {
+				embPkgID = emb.Type.PkgID
+			}
+			ms, err := g.methods(emb.Fields, embPkgID, visiting, seen)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, ms...)
+			continue
+		}
+		if !f.IsFunc() || seen[f.Name] {
+			continue
+		}
+		seen[f.Name] = true
+		out = append(out, mockMethod{
+			name:     f.Name,
+			fn:       f.Func,
+			srcPkgID: srcPkgID,
+		})
+	}
+	return out, nil
+}
+
+// embedded returns the Schema of embedded interface.
+// Interface in the same package may not have package in its Type, so it is looked up by name.
+func (g *MockGenerator) embedded(t *Type) *Schema {
+	if t == nil {
+		return nil
+	}
+	if sc, ok := g.idx.Lookup(t); ok {
+		return sc
+	}
+	for _, sc := range g.idx.Schemas() {
+		if sc.Name == t.TypeName {
+			return sc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package stst_test
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mockSrc = `package data
+
+import "time"
+
+type Good struct {
+	Name string
+}
+
+type Status int
+
+type Reader interface {
+	Read(id string) (*Good, error)
+}
+
+type Store interface {
+	Reader
+	error
+	Touch(at time.Time, status Status, tags ...string)
+}
+
+type Repository[T any, K comparable] interface {
+	Get(id K) (T, error)
+	Attrs() map[K][]T
+}
+`
+
+func TestMockGenerate(t *testing.T) {
+	schemas := parseSource(t, mockSrc)
+
+	got, err := stst.NewMockGenerator(schemas).Generate(stst.GoPackage{Name: "mocks", ID: testPkg + "/mocks"}, schemas)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "mock.go", got, parser.AllErrors)
+	require.NoError(t, err)
+
+	src := string(got)
+	for _, want := range []string{
+		"import (\n\t\"time\"\n\n\t\"github.com/maru44/stst/tests/data\"\n\t\"github.com/stretchr/testify/mock\"\n)",
+		"type MockReader struct {\n\tmock.Mock\n}",
+		"var _ data.Store = (*MockStore)(nil)",
+		"func (m *MockReader) Read(arg0 string) (*data.Good, error) {\n\tret := m.Called(arg0)\n\tr0, _ := ret.Get(0).(*data.Good)\n\treturn r0, ret.Error(1)\n}",
+		// methods of embedded interfaces are expanded
+		"func (m *MockStore) Read(arg0 string) (*data.Good, error) {",
+		"func (m *MockStore) Error() string {",
+		// variadic argument is passed as a slice
+		"func (m *MockStore) Touch(arg0 time.Time, arg1 data.Status, arg2 ...string) {\n\tm.Called(arg0, arg1, arg2)\n}",
+		"type MockRepository[T any, K comparable] struct {",
+		"func NewMockRepository[T any, K comparable](t interface {",
+		"func (m *MockRepository[T, K]) Get(arg0 K) (T, error) {",
+		"func (m *MockRepository[T, K]) Attrs() map[K][]T {",
+	} {
+		assert.Contains(t, src, want)
+	}
+	// Good is not interface
+	assert.NotContains(t, src, "MockGood")
+	// generic interface can not be asserted without type arguments
+	assert.NotContains(t, src, "data.Repository")
+}
+
+func TestMockGenerate_UnknownEmbedded(t *testing.T) {
+	schemas := parseSource(t, "package data\n\nimport \"io\"\n\ntype Broken interface {\n\tio.Reader\n}\n")
+	_, err := stst.NewMockGenerator(schemas).Generate(stst.GoPackage{Name: "mocks"}, schemas)
+	assert.ErrorContains(t, err, "unknown embedded interface")
+}
@@ -1,6 +1,9 @@
 package stst
 
 import (
+	"fmt"
+	"go/token"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -13,7 +16,9 @@ type (
 
 	// Schema is information for defined as type
 	Schema struct {
-		Name         string
+		Name string
+		// PkgID is path of the package declaring the type, it is empty for untitled structs and interfaces
+		PkgID        string
 		Fields       []*Field
 		Type         *Type
 		Func         *Func
@@ -21,6 +26,61 @@ type (
 		IsInterface  bool
 		TypePrefixes []TypePrefix
 		Comment      []string
+		Doc          []string
+		// Pos is position of the name, it is recorded only if Parser.Positions is true
+		Pos token.Position
+		// Consts are constants defined as the type (like enum)
+		Consts []*Const
+		// TypeParams are type parameters of generic type
+		TypeParams []*TypeParam
+		// Methods are methods declared in the package whose receiver is the type
+		Methods []*Method
+		// Size and Align are size and alignment of struct in bytes.
+		// They are recorded only if Parser.Layout is true, and Align is 0 if they are not recorded.
+		Size  int64
+		Align int64
+	}
+
+	// Method is method declared with the Schema as receiver.
+	Method struct {
+		Name string
+		Func *Func
+		// IsPointerReceiver is true for method like `func (x *Xxx) Name()`
+		IsPointerReceiver bool
+		Doc               []string
+	}
+
+	// TypeParam is type parameter of generic type like `T any`.
+	TypeParam struct {
+		Name       string
+		Constraint string
+	}
+
+	// Const is constant whose type is the Schema.
+	Const struct {
+		Name string
+		// Value is exact value of the constant like `1` or `"active"`
+		Value   string
+		Doc     []string
+		Comment []string
+	}
+
+	// ValidateRules is rules in `validate` tag (go-playground/validator style).
+	ValidateRules struct {
+		// Rules are rules for the value, all of them must be satisfied
+		Rules []*ValidateRule
+		// Keys are rules for keys of map between `keys` and `endkeys`
+		Keys *ValidateRules
+		// Dive is rules for elements of slice, array or map after `dive`
+		Dive *ValidateRules
+	}
+
+	// ValidateRule is a rule like `min=1`.
+	ValidateRule struct {
+		Name  string
+		Param string
+		// Or are alternatives joined by `|`, `rgb|rgba` is the rule `rgb` with Or `rgba`
+		Or []*ValidateRule
 	}
 
 	// Func has information of args and results
@@ -34,11 +94,25 @@ type (
 		Type                *Type
 		IsUntitledStruct    bool
 		IsUntitledInterface bool
-		Tags                []*Tag
-		Comment             []string
-		Func                *Func
-		Map                 *Map
-		TypePrefixes        []TypePrefix
+		IsEmbedded          bool
+		// IsVariadic is true for the last argument of function like `args ...int`.
+		// Its TypePrefixes starts with TypePrefixSlice.
+		IsVariadic bool
+		Tags       []*Tag
+		// ValidateRules is parsed `validate` tag, it is nil if the tag is absent or invalid
+		ValidateRules *ValidateRules
+		Comment       []string
+		Doc           []string
+		// Pos is position of the name (or the type if embedded), it is recorded only if Parser.Positions is true
+		Pos token.Position
+		// Size, Align and Offset are size, alignment and offset in the struct in bytes.
+		// They are recorded only for fields of structs if Parser.Layout is true.
+		Size         int64
+		Align        int64
+		Offset       int64
+		Func         *Func
+		Map          *Map
+		TypePrefixes []TypePrefix
 		// Schema is only for untitled struct or untitled interface
 		Schema *Schema
 	}
@@ -76,11 +150,62 @@ const (
 
 var intReg = regexp.MustCompile("[0-9]+")
 
+var basicTypes = map[UnderlyingType]struct{}{
+	"bool":        {},
+	"string":      {},
+	"int":         {},
+	"int8":        {},
+	"int16":       {},
+	"int32":       {},
+	"int64":       {},
+	"uint":        {},
+	"uint8":       {},
+	"uint16":      {},
+	"uint32":      {},
+	"uint64":      {},
+	"uintptr":     {},
+	"byte":        {},
+	"rune":        {},
+	"float32":     {},
+	"float64":     {},
+	"complex64":   {},
+	"complex128":  {},
+	"error":       {},
+	"any":         {},
+	"interface{}": {},
+}
+
 // SetPackage is method to set PkgID and PkgPlusName by UnderlyingType.
 func (t *Type) SetPackage() {
 	t.PkgID, t.PkgPlusName = t.Underlying.pk()
 }
 
+// IsBasic returns whether the Type is predeclared type or not.
+func (t *Type) IsBasic() bool {
+	_, ok := basicTypes[t.Underlying]
+	return ok
+}
+
+// IsStruct returns whether the Schema is defined as struct or not.
+func (s *Schema) IsStruct() bool {
+	return s.Type != nil && s.Type.TypeName == s.Name && !s.IsInterface && !s.IsMap()
+}
+
+// IsEnum returns whether the Schema has constants or not.
+func (s *Schema) IsEnum() bool {
+	return len(s.Consts) > 0
+}
+
+// Method returns the Method of the Schema which has the name.
+func (s *Schema) Method(name string) (*Method, bool) {
+	for _, m := range s.Methods {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
 // IsFunc returns whether the Schema is function or not.
 func (s *Schema) IsFunc() bool {
 	return s.Func != nil
@@ -101,6 +226,81 @@ func (f *Field) IsMap() bool {
 	return f.Map != nil
 }
 
+// Tag returns the Tag of the Field which has the key.
+func (f *Field) Tag(key string) (*Tag, bool) {
+	for _, t := range f.Tags {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// IsExported returns whether the Field is exported or not.
+func (f *Field) IsExported() bool {
+	return token.IsExported(f.Name)
+}
+
+// Name returns the first value of the Tag.
+func (t *Tag) Name() string {
+	if len(t.Values) == 0 {
+		return ""
+	}
+	return t.Values[0]
+}
+
+// HasOption returns whether the Tag has the option or not.
+// The first value is treated as a name, not as an option.
+func (t *Tag) HasOption(opt string) bool {
+	if len(t.Values) < 2 {
+		return false
+	}
+	for _, v := range t.Values[1:] {
+		if v == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// ststTagKey is key of tag whose options are shared by the generators of Go code.
+const ststTagKey = "stst"
+
+// options of `stst` tag
+var ststTagOptions = map[string]struct{}{
+	// constructor
+	"required": {},
+	"option":   {},
+	// accessor
+	"-":       {},
+	"noget":   {},
+	"set":     {},
+	"nobuild": {},
+	// deepcopy
+	"shallow": {},
+	// equal
+	"noeq": {},
+}
+
+// ststOptions returns options in `stst` tag of the Field.
+func ststOptions(f *Field) (map[string]bool, error) {
+	out := map[string]bool{}
+	tag, ok := f.Tag(ststTagKey)
+	if !ok {
+		return out, nil
+	}
+	for _, v := range tag.Values {
+		if v == "" {
+			continue
+		}
+		if _, ok := ststTagOptions[v]; !ok {
+			return nil, fmt.Errorf("field %s: unknown option of stst tag: %s", f.Name, v)
+		}
+		out[v] = true
+	}
+	return out, nil
+}
+
 func (t TypePrefix) Kind() TypePrefixKind {
 	if t == TypePrefixPtr {
 		return TypePrefixKindPtr
@@ -127,17 +327,20 @@ func (t TypePrefix) ArrayLength() (int, bool) {
 }
 
 func (u UnderlyingType) pk() (pack string, pkPlusName string) {
-	arr := strings.Split(string(u), "/")
+	// type arguments like `[github.com/xx/yy.ZZZ]` are shortened to `[yy.ZZZ]`
+	base := u.withoutTypeArgs()
+	args := qualifiedNameReg.ReplaceAllStringFunc(strings.TrimPrefix(string(u), string(base)), path.Base)
+	arr := strings.Split(string(base), "/")
 	if len(arr) == 1 {
-		withoutType := strings.Split(string(u), ".")
+		withoutType := strings.Split(string(base), ".")
 		if len(withoutType) != 1 {
-			pkPlusName = string(u)
+			pkPlusName = string(base) + args
 			pack = withoutType[0]
 		}
 		return
 	}
-	pkPlusName = arr[len(arr)-1]
-	withouType := strings.Split(pkPlusName, ".")
+	pkPlusName = arr[len(arr)-1] + args
+	withouType := strings.Split(arr[len(arr)-1], ".")
 	pack = strings.Join(arr[0:len(arr)-1], "/") + "/" + withouType[0]
 	return
 }
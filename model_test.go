@@ -26,6 +26,32 @@ func TestSetPackage(t *testing.T) {
 				TypeName:    "Fff",
 			},
 		},
+		{
+			name: "ok: type arguments",
+			typ: &stst.Type{
+				Underlying: "sync/atomic.Pointer[aaa/bbb.Ccc]",
+				TypeName:   "Pointer",
+			},
+			want: &stst.Type{
+				Underlying:  "sync/atomic.Pointer[aaa/bbb.Ccc]",
+				PkgID:       "sync/atomic",
+				PkgPlusName: "atomic.Pointer[bbb.Ccc]",
+				TypeName:    "Pointer",
+			},
+		},
+		{
+			name: "ok: type parameters",
+			typ: &stst.Type{
+				Underlying: "aaa/bbb.Pair[K comparable, V time.Time]",
+				TypeName:   "Pair",
+			},
+			want: &stst.Type{
+				Underlying:  "aaa/bbb.Pair[K comparable, V time.Time]",
+				PkgID:       "aaa/bbb",
+				PkgPlusName: "bbb.Pair[K comparable, V time.Time]",
+				TypeName:    "Pair",
+			},
+		},
 		{
 			name: "ok: without set",
 			typ: &stst.Type{
@@ -0,0 +1,186 @@
+package stst
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type (
+	// OpenAPIGenerator generates component schemas of OpenAPI 3.1 from Schema.
+	// In addition to `json` tags, `validate` tags (go-playground/validator style) are reflected
+	// as `required`, `minLength`, `maximum`, `enum`, `format` and so on.
+	OpenAPIGenerator struct {
+		js *JSONSchemaGenerator
+	}
+)
+
+const validateTagKey = "validate"
+
+// formats of JSON Schema for validate tags
+var validateFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+	"datetime": "date-time",
+}
+
+// NewOpenAPIGenerator returns OpenAPIGenerator.
+// The schemas are used to resolve named types referenced by fields.
+func NewOpenAPIGenerator(schemas []*Schema) *OpenAPIGenerator {
+	js := NewJSONSchemaGenerator(schemas)
+	js.refPrefix = "#/components/schemas/"
+	js.validate = true
+	return &OpenAPIGenerator{
+		js: js,
+	}
+}
+
+// Generate returns `components.schemas` of OpenAPI for the schemas.
+// Named types referenced from the schemas are included as well.
+// If names of types in different packages conflict, they are named as `pkg.Name`.
+func (g *OpenAPIGenerator) Generate(schemas []*Schema) (map[string]*JSONSchema, error) {
+	g.js.defs = map[string]*JSONSchema{}
+	g.js.defNames = map[*Schema]string{}
+	for _, sc := range schemas {
+		if sc == nil {
+			return nil, fmt.Errorf("openapi: schema is nil")
+		}
+		t := sc.Type
+		if t == nil {
+			t = &Type{}
+		}
+		g.js.define(sc, t)
+	}
+	return g.js.defs, nil
+}
+
+// applyValidateTag sets constraints of `validate` tag of the field to the JSONSchema.
+// It returns true if the field is required.
+// Rules for elements (after `dive`), rules with alternatives and invalid tags are ignored.
+func applyValidateTag(s *JSONSchema, f *Field) bool {
+	rules := f.ValidateRules
+	if tag, ok := f.Tag(validateTagKey); ok && rules == nil {
+		var err error
+		if rules, err = ParseValidateTag(tag.RawValue); err != nil {
+			return false
+		}
+	}
+	if rules == nil {
+		return false
+	}
+	kind := validateTargetKind(f)
+
+	for _, r := range rules.Rules {
+		if len(r.Or) > 0 {
+			continue
+		}
+		switch r.Name {
+		case "len", "min", "max":
+			setValidateLength(s, kind, r.Name, r.Param)
+		case "gt", "gte", "lt", "lte":
+			n, err := strconv.ParseFloat(r.Param, 64)
+			if err != nil || kind != "number" {
+				continue
+			}
+			switch r.Name {
+			case "gt":
+				s.ExclusiveMinimum = &n
+			case "gte":
+				s.Minimum = &n
+			case "lt":
+				s.ExclusiveMaximum = &n
+			case "lte":
+				s.Maximum = &n
+			}
+		case "oneof":
+			s.Enum = nil
+			for _, o := range splitOneOf(r.Param) {
+				if kind == "number" {
+					if n, err := strconv.ParseFloat(o, 64); err == nil {
+						s.Enum = append(s.Enum, n)
+						continue
+					}
+				}
+				s.Enum = append(s.Enum, o)
+			}
+		default:
+			if format, ok := validateFormats[r.Name]; ok && kind == "string" {
+				s.Format = format
+			}
+		}
+	}
+	return rules.has("required")
+}
+
+func setValidateLength(s *JSONSchema, kind, name, param string) {
+	if kind == "number" {
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		if name != "max" {
+			s.Minimum = &n
+		}
+		if name != "min" {
+			s.Maximum = &n
+		}
+		return
+	}
+
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	var min, max **int
+	switch kind {
+	case "string":
+		min, max = &s.MinLength, &s.MaxLength
+	case "array":
+		min, max = &s.MinItems, &s.MaxItems
+	case "object":
+		min, max = &s.MinProperties, &s.MaxProperties
+	default:
+		return
+	}
+	if name != "max" {
+		*min = &n
+	}
+	if name != "min" {
+		*max = &n
+	}
+}
+
+// validateTargetKind returns kind of value validated by the `validate` tag of the field.
+// Pointers are dereferenced as validator does.
+func validateTargetKind(f *Field) string {
+	for _, pref := range f.TypePrefixes {
+		switch pref.Kind() {
+		case TypePrefixKindSlice, TypePrefixKindArray:
+			if f.Type != nil && isByte(f.Type) {
+				// []byte is encoded as base64 string
+				return ""
+			}
+			return "array"
+		}
+	}
+	if f.IsMap() {
+		return "object"
+	}
+	if f.Type == nil {
+		return ""
+	}
+	switch f.Type.Underlying {
+	case "string":
+		return "string"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune",
+		"float32", "float64":
+		return "number"
+	}
+	return ""
+}
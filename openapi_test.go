@@ -0,0 +1,151 @@
+package stst_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const openAPISrc = `package data
+
+import (
+	"time"
+
+	"github.com/maru44/stst/tests/data/aaa"
+)
+
+type SampleString string
+
+type Good struct {
+	Name      string        ` + "`json:\"name\"`" + `
+	SamplePtr *SampleString ` + "`json:\"sample_ptr,omitempty\"`" + `
+}
+
+type User struct {
+	Name      string    ` + "`json:\"name,omitempty\" validate:\"required,min=1,max=10\"`" + `
+	Email     string    ` + "`json:\"email,omitempty\" validate:\"omitempty,email\"`" + `
+	Age       int       ` + "`json:\"age\" validate:\"gte=0,lt=150\"`" + `
+	Color     string    ` + "`json:\"color\" validate:\"oneof='light red' blue\"`" + `
+	Tags      []string  ` + "`json:\"tags\" validate:\"max=3,dive,min=1\"`" + `
+	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
+	Good      *Good     ` + "`json:\"good,omitempty\"`" + `
+	OtherGood aaa.Good  ` + "`json:\"other_good\"`" + `
+}
+`
+
+const openAPIAaaSrc = `package aaa
+
+type Good struct {
+	Code string ` + "`json:\"code\"`" + `
+}
+`
+
+func TestOpenAPIGenerate(t *testing.T) {
+	aaa := loadSource(t, testPkg+"/aaa", openAPIAaaSrc)
+	schemas := append(stst.NewParser(aaa).Parse(), stst.NewParser(loadSource(t, testPkg, openAPISrc, aaa)).Parse()...)
+
+	g := stst.NewOpenAPIGenerator(schemas)
+	got, err := g.Generate([]*stst.Schema{findSchema(schemas, "User")})
+	require.NoError(t, err)
+
+	b, err := json.MarshalIndent(got, "", "  ")
+	require.NoError(t, err)
+	want := `{
+  "Good": {
+    "title": "Good",
+    "type": "object",
+    "properties": {
+      "name": {
+        "type": "string"
+      },
+      "sample_ptr": {
+        "type": [
+          "string",
+          "null"
+        ]
+      }
+    },
+    "required": [
+      "name"
+    ]
+  },
+  "User": {
+    "title": "User",
+    "type": "object",
+    "properties": {
+      "age": {
+        "type": "integer",
+        "minimum": 0,
+        "exclusiveMaximum": 150
+      },
+      "color": {
+        "type": "string",
+        "enum": [
+          "light red",
+          "blue"
+        ]
+      },
+      "created_at": {
+        "type": "string",
+        "format": "date-time"
+      },
+      "email": {
+        "type": "string",
+        "format": "email"
+      },
+      "good": {
+        "anyOf": [
+          {
+            "$ref": "#/components/schemas/Good"
+          },
+          {
+            "type": "null"
+          }
+        ]
+      },
+      "name": {
+        "type": "string",
+        "minLength": 1,
+        "maxLength": 10
+      },
+      "other_good": {
+        "$ref": "#/components/schemas/aaa.Good"
+      },
+      "tags": {
+        "type": [
+          "array",
+          "null"
+        ],
+        "items": {
+          "type": "string"
+        },
+        "maxItems": 3
+      }
+    },
+    "required": [
+      "name",
+      "age",
+      "color",
+      "tags",
+      "created_at",
+      "other_good"
+    ]
+  },
+  "aaa.Good": {
+    "title": "Good",
+    "type": "object",
+    "properties": {
+      "code": {
+        "type": "string"
+      }
+    },
+    "required": [
+      "code"
+    ]
+  }
+}`
+	assert.Equal(t, want, string(b))
+}
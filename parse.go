@@ -1,8 +1,10 @@
 package stst
 
 import (
-	"fmt"
 	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -10,6 +12,12 @@ import (
 
 type Parser struct {
 	Pkg *packages.Package
+	// Positions enables Schema.Pos and Field.Pos.
+	// They are not recorded by default so that schemas parsed from different sources are comparable.
+	Positions bool
+	// Layout enables size, alignment and offsets of structs and their fields.
+	// They are computed by TypesSizes of the package, or sizes of gc for GOARCH if it is nil.
+	Layout bool
 }
 
 func NewParser(pkg *packages.Package) *Parser {
@@ -22,22 +30,24 @@ func NewParser(pkg *packages.Package) *Parser {
 func (p *Parser) Parse() []*Schema {
 	var schemas []*Schema
 	for _, f := range p.Pkg.Syntax {
-		for _, decl := range f.Decls {
-			if it, ok := decl.(*ast.GenDecl); ok {
-				for _, spec := range it.Specs {
-					switch ts := spec.(type) {
-					case *ast.TypeSpec:
-						sc := p.parseTypeSpec(ts)
-						schemas = append(schemas, sc)
-					}
-				}
-			}
-		}
+		schemas = append(schemas, p.parseDecls(f)...)
 	}
+	p.setConsts(schemas)
+	p.setMethods(schemas)
+	p.setLayouts(schemas)
 	return schemas
 }
 
 func (p *Parser) ParseFile(f *ast.File) []*Schema {
+	schemas := p.parseDecls(f)
+	p.setConsts(schemas)
+	p.setMethods(schemas)
+	p.setLayouts(schemas)
+	return schemas
+}
+
+// parseDecls parses type declarations in the file.
+func (p *Parser) parseDecls(f *ast.File) []*Schema {
 	var schemas []*Schema
 	for _, decl := range f.Decls {
 		if it, ok := decl.(*ast.GenDecl); ok {
@@ -45,6 +55,9 @@ func (p *Parser) ParseFile(f *ast.File) []*Schema {
 				switch ts := spec.(type) {
 				case *ast.TypeSpec:
 					sc := p.parseTypeSpec(ts)
+					if sc.Doc == nil && len(it.Specs) == 1 {
+						sc.Doc = commentTexts(it.Doc)
+					}
 					schemas = append(schemas, sc)
 				}
 			}
@@ -53,9 +66,139 @@ func (p *Parser) ParseFile(f *ast.File) []*Schema {
 	return schemas
 }
 
+// position returns position in the source if Positions is enabled.
+func (p *Parser) position(pos token.Pos) token.Position {
+	if !p.Positions || p.Pkg.Fset == nil {
+		return token.Position{}
+	}
+	return p.Pkg.Fset.Position(pos)
+}
+
+// setConsts sets constants defined in the package to the schemas of their types.
+func (p *Parser) setConsts(schemas []*Schema) {
+	consts := p.parseConsts()
+	if len(consts) == 0 {
+		return
+	}
+	for _, sc := range schemas {
+		if cs, ok := consts[sc.Name]; ok {
+			sc.Consts = cs
+		}
+	}
+}
+
+// parseConsts returns constants whose types are defined in the package.
+// The key of the map is name of the type.
+func (p *Parser) parseConsts() map[string][]*Const {
+	out := map[string][]*Const{}
+	for _, f := range p.Pkg.Syntax {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for _, name := range vs.Names {
+					if name.Name == "_" {
+						continue
+					}
+					obj, ok := p.Pkg.TypesInfo.Defs[name].(*types.Const)
+					if !ok {
+						continue
+					}
+					named, ok := obj.Type().(*types.Named)
+					if !ok || named.Obj().Pkg() != p.Pkg.Types {
+						continue
+					}
+					c := &Const{
+						Name:    name.Name,
+						Value:   obj.Val().ExactString(),
+						Doc:     commentTexts(vs.Doc),
+						Comment: commentTexts(vs.Comment),
+					}
+					if c.Doc == nil && len(gen.Specs) == 1 {
+						c.Doc = commentTexts(gen.Doc)
+					}
+					key := named.Obj().Name()
+					out[key] = append(out[key], c)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// setMethods sets methods declared in the package to the schemas of their receivers.
+func (p *Parser) setMethods(schemas []*Schema) {
+	methods := p.parseMethods()
+	if len(methods) == 0 {
+		return
+	}
+	for _, sc := range schemas {
+		if ms, ok := methods[sc.Name]; ok {
+			sc.Methods = ms
+		}
+	}
+}
+
+// parseMethods returns methods declared in the package.
+// The key of the map is name of the receiver type.
+func (p *Parser) parseMethods() map[string][]*Method {
+	out := map[string][]*Method{}
+	for _, f := range p.Pkg.Syntax {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			m := &Method{
+				Name: fn.Name.Name,
+				Func: p.parseFunc(fn.Type),
+				Doc:  commentTexts(fn.Doc),
+			}
+			if star, ok := recv.(*ast.StarExpr); ok {
+				m.IsPointerReceiver = true
+				recv = star.X
+			}
+			// receiver of generic type like `Gene[T]`
+			switch ex := recv.(type) {
+			case *ast.IndexExpr:
+				recv = ex.X
+			case *ast.IndexListExpr:
+				recv = ex.X
+			}
+			ide, ok := recv.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			out[ide.Name] = append(out[ide.Name], m)
+		}
+	}
+	return out
+}
+
+func commentTexts(cg *ast.CommentGroup) []string {
+	if cg == nil || len(cg.List) == 0 {
+		return nil
+	}
+	out := make([]string, len(cg.List))
+	for i, c := range cg.List {
+		out[i] = c.Text
+	}
+	return out
+}
+
 func (p *Parser) parseTypeSpec(spec *ast.TypeSpec) *Schema {
 	sc := &Schema{
-		Name: spec.Name.Name,
+		Name:  spec.Name.Name,
+		PkgID: p.Pkg.PkgPath,
+		Doc:   commentTexts(spec.Doc),
+		Pos:   p.position(spec.Name.Pos()),
 	}
 
 	var fin bool
@@ -71,6 +214,17 @@ func (p *Parser) parseTypeSpec(spec *ast.TypeSpec) *Schema {
 	}
 	sc.TypePrefixes = prefixes
 
+	if spec.TypeParams != nil {
+		for _, tp := range spec.TypeParams.List {
+			for _, n := range tp.Names {
+				sc.TypeParams = append(sc.TypeParams, &TypeParam{
+					Name:       n.Name,
+					Constraint: types.ExprString(tp.Type),
+				})
+			}
+		}
+	}
+
 	if spec.Comment != nil && len(spec.Comment.List) > 0 {
 		sc.Comment = make([]string, len(spec.Comment.List))
 		for i, c := range spec.Comment.List {
@@ -84,12 +238,10 @@ func (p *Parser) parseTypeSpec(spec *ast.TypeSpec) *Schema {
 		sc.Type.SetPackage()
 
 		for _, f := range typ.Fields.List {
-			ff, ok := p.parseField(f)
-			if !ok {
-				continue
+			for _, ff := range p.parseFields(f) {
+				ff.IsEmbedded = len(f.Names) == 0
+				sc.Fields = append(sc.Fields, ff)
 			}
-
-			sc.Fields = append(sc.Fields, ff)
 		}
 	case *ast.Ident:
 		sc.Type = p.parseIdent(typ)
@@ -104,6 +256,7 @@ func (p *Parser) parseTypeSpec(spec *ast.TypeSpec) *Schema {
 			if !ok {
 				continue
 			}
+			ff.IsEmbedded = len(m.Names) == 0
 			sc.Fields = append(sc.Fields, ff)
 		}
 	case *ast.MapType:
@@ -122,6 +275,17 @@ func (p *Parser) parseField(f *ast.Field) (*Field, bool) {
 
 	out := &Field{
 		Tags: p.parseTag(f.Tag),
+		Doc:  commentTexts(f.Doc),
+	}
+	if len(f.Names) != 0 {
+		out.Pos = p.position(f.Names[0].Pos())
+	} else {
+		out.Pos = p.position(f.Type.Pos())
+	}
+	if t, ok := out.Tag(validateTagKey); ok {
+		if rules, err := ParseValidateTag(t.RawValue); err == nil {
+			out.ValidateRules = rules
+		}
 	}
 
 	if f.Comment != nil {
@@ -135,6 +299,11 @@ func (p *Parser) parseField(f *ast.Field) (*Field, bool) {
 	var fin bool
 	var prefixes []TypePrefix
 	ex := f.Type
+	if ell, ok := ex.(*ast.Ellipsis); ok {
+		out.IsVariadic = true
+		prefixes = append(prefixes, TypePrefixSlice)
+		ex = ell.Elt
+	}
 	for !fin {
 		var pref TypePrefix
 		ex, pref, fin = p.purgePointerOrSlice(ex)
@@ -166,6 +335,30 @@ func (p *Parser) parseField(f *ast.Field) (*Field, bool) {
 			TypeName:   typ.Sel.Name,
 			Underlying: UnderlyingType(p.Pkg.TypesInfo.TypeOf(typ).String()),
 		}
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		// instantiated generic type like `Page[T]` or `aaa.Pair[K, V]`
+		var base ast.Expr
+		if ix, ok := typ.(*ast.IndexExpr); ok {
+			base = ix.X
+		} else {
+			base = typ.(*ast.IndexListExpr).X
+		}
+		var typeName string
+		switch b := base.(type) {
+		case *ast.Ident:
+			typeName = b.Name
+		case *ast.SelectorExpr:
+			typeName = b.Sel.Name
+		default:
+			return nil, false
+		}
+		if name == "" {
+			name = typeName
+		}
+		out.Type = &Type{
+			TypeName:   typeName,
+			Underlying: UnderlyingType(p.Pkg.TypesInfo.TypeOf(ex).String()),
+		}
 	case *ast.FuncType:
 		out.Func = p.parseFunc(typ)
 	case *ast.MapType:
@@ -175,12 +368,10 @@ func (p *Parser) parseField(f *ast.Field) (*Field, bool) {
 		if len(typ.Fields.List) > 0 {
 			sc := &Schema{}
 			for _, fi := range typ.Fields.List {
-				ff, ok := p.parseField(fi)
-				if !ok {
-					continue
+				for _, ff := range p.parseFields(fi) {
+					ff.IsEmbedded = len(fi.Names) == 0
+					sc.Fields = append(sc.Fields, ff)
 				}
-
-				sc.Fields = append(sc.Fields, ff)
 			}
 			out.Schema = sc
 		}
@@ -193,6 +384,7 @@ func (p *Parser) parseField(f *ast.Field) (*Field, bool) {
 				if !ok {
 					continue
 				}
+				ff.IsEmbedded = len(m.Names) == 0
 				sc.Fields = append(sc.Fields, ff)
 			}
 			out.Schema = sc
@@ -209,6 +401,32 @@ func (p *Parser) parseField(f *ast.Field) (*Field, bool) {
 	return out, true
 }
 
+// parseFields parses the field which can have multiple names like `a, b int`.
+// It returns a Field for each name.
+func (p *Parser) parseFields(f *ast.Field) []*Field {
+	if len(f.Names) <= 1 {
+		ff, ok := p.parseField(f)
+		if !ok {
+			return nil
+		}
+		return []*Field{ff}
+	}
+	var out []*Field
+	for _, n := range f.Names {
+		ff, ok := p.parseField(&ast.Field{
+			Doc:     f.Doc,
+			Names:   []*ast.Ident{n},
+			Type:    f.Type,
+			Tag:     f.Tag,
+			Comment: f.Comment,
+		})
+		if ok {
+			out = append(out, ff)
+		}
+	}
+	return out
+}
+
 func (p *Parser) purgePointerOrSlice(ex ast.Expr) (ast.Expr, TypePrefix, bool) {
 	switch typ := ex.(type) {
 	case *ast.StarExpr:
@@ -216,7 +434,7 @@ func (p *Parser) purgePointerOrSlice(ex ast.Expr) (ast.Expr, TypePrefix, bool) {
 	case *ast.ArrayType:
 		if typ.Len != nil {
 			if b, ok := typ.Len.(*ast.BasicLit); ok {
-				return typ.Elt, TypePrefix(fmt.Sprintf("[%d]", b.Kind)), false
+				return typ.Elt, TypePrefix("[" + b.Value + "]"), false
 			}
 		}
 		return typ.Elt, TypePrefixSlice, false
@@ -247,18 +465,12 @@ func (p *Parser) parseFunc(fn *ast.FuncType) *Func {
 	var args, results []*Field
 	if fn.Params != nil {
 		for _, param := range fn.Params.List {
-			ff, ok := p.parseField(param)
-			if ok {
-				args = append(args, ff)
-			}
+			args = append(args, p.parseFields(param)...)
 		}
 	}
 	if fn.Results != nil {
 		for _, res := range fn.Results.List {
-			ff, ok := p.parseField(res)
-			if ok {
-				results = append(results, ff)
-			}
+			results = append(results, p.parseFields(res)...)
 		}
 	}
 	return &Func{
@@ -297,26 +509,63 @@ func (p *Parser) parseIdent(ide *ast.Ident) *Type {
 	}
 }
 
+// parseTag parses tag in the same way as reflect.StructTag,
+// so the value can contain spaces and colons like `validate:"oneof=a b"`.
 func (p *Parser) parseTag(tag *ast.BasicLit) []*Tag {
 	if tag == nil {
 		return nil
 	}
+	raw, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return nil
+	}
 
 	var out []*Tag
-	tags := strings.Split(strings.Trim(tag.Value, "`"), " ")
-	for _, t := range tags {
-		kv := strings.Split(t, ":")
-		if len(kv) != 2 {
-			continue
+	for raw != "" {
+		// skip leading space
+		i := 0
+		for i < len(raw) && raw[i] == ' ' {
+			i++
 		}
+		raw = raw[i:]
+		if raw == "" {
+			break
+		}
+
+		// scan to colon
+		i = 0
+		for i < len(raw) && raw[i] > ' ' && raw[i] != ':' && raw[i] != '"' && raw[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(raw) || raw[i] != ':' || raw[i+1] != '"' {
+			break
+		}
+		key := raw[:i]
+		raw = raw[i+1:]
+
+		// scan quoted string to find value
+		i = 1
+		for i < len(raw) && raw[i] != '"' {
+			if raw[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(raw) {
+			break
+		}
+		qv := raw[:i+1]
+		raw = raw[i+1:]
 
-		v := strings.Trim(kv[1], `"`)
-		tag := &Tag{
-			Key:      kv[0],
+		v, err := strconv.Unquote(qv)
+		if err != nil {
+			break
+		}
+		out = append(out, &Tag{
+			Key:      key,
 			Values:   strings.Split(v, ","),
 			RawValue: v,
-		}
-		out = append(out, tag)
+		})
 	}
 	return out
 }
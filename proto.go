@@ -0,0 +1,466 @@
+package stst
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// ProtoNumbering is persisted field numbers of messages.
+	// The key is message name and the value is map of field name to its number.
+	// Numbers of removed fields are kept so that they are not reused, and they are generated as `reserved`.
+	ProtoNumbering map[string]map[string]int
+
+	// ProtoGenerator generates proto3 definitions from Schema.
+	//
+	// Field numbers are decided by following order.
+	//   - the number in `protobuf` tag generated by protoc-gen-go (like `protobuf:"varint,1,opt,name=id"`)
+	//   - the number in `proto` tag (like `proto:"1"`)
+	//   - ProtoNumbering
+	//   - next number of the largest number in the message (then it is recorded to ProtoNumbering)
+	ProtoGenerator struct {
+		idx       *Index
+		numbering ProtoNumbering
+
+		imports  map[string]struct{}
+		queue    []*Schema
+		queued   map[*Schema]struct{}
+		messages []string
+		// pkgID is PkgID of the schema being generated, named basic types are looked up in the package
+		pkgID string
+	}
+)
+
+const (
+	protobufTagKey = "protobuf"
+	protoTagKey    = "proto"
+)
+
+var protoWellKnownTypes = map[UnderlyingType]struct {
+	name string
+	file string
+}{
+	"time.Time":     {name: "google.protobuf.Timestamp", file: "google/protobuf/timestamp.proto"},
+	"time.Duration": {name: "google.protobuf.Duration", file: "google/protobuf/duration.proto"},
+}
+
+// LoadProtoNumbering reads ProtoNumbering from the JSON file.
+// If the file does not exist, it returns empty ProtoNumbering.
+func LoadProtoNumbering(path string) (ProtoNumbering, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ProtoNumbering{}, nil
+		}
+		return nil, fmt.Errorf("failed to read numbering file: %w", err)
+	}
+	out := ProtoNumbering{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse numbering file: %w", err)
+	}
+	return out, nil
+}
+
+// Save writes ProtoNumbering to the file as JSON.
+func (n ProtoNumbering) Save(path string) error {
+	b, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal numbering: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write numbering file: %w", err)
+	}
+	return nil
+}
+
+// NewProtoGenerator returns ProtoGenerator.
+// The schemas are used to resolve named types referenced by fields.
+// If numbering is nil, numbers which are not decided by tags are assigned in order of fields.
+func NewProtoGenerator(schemas []*Schema, numbering ProtoNumbering) *ProtoGenerator {
+	if numbering == nil {
+		numbering = ProtoNumbering{}
+	}
+	return &ProtoGenerator{
+		idx:       NewIndex(schemas),
+		numbering: numbering,
+	}
+}
+
+// Numbering returns ProtoNumbering including numbers assigned by Generate.
+func (g *ProtoGenerator) Numbering() ProtoNumbering {
+	return g.numbering
+}
+
+// Generate returns proto file content which has messages and enums of the schemas.
+// Named types referenced from the schemas are generated as well.
+func (g *ProtoGenerator) Generate(pkg string, schemas []*Schema) (string, error) {
+	g.imports = map[string]struct{}{}
+	g.queue = nil
+	g.queued = map[*Schema]struct{}{}
+	g.messages = nil
+
+	for _, sc := range schemas {
+		g.enqueue(sc)
+	}
+	for i := 0; i < len(g.queue); i++ {
+		sc := g.queue[i]
+		g.pkgID = sc.PkgID
+		var (
+			def string
+			err error
+		)
+		if sc.IsEnum() {
+			def, err = g.enum(sc)
+		} else {
+			def, err = g.message(sc.Name, sc.Name, sc.Fields, "")
+		}
+		if err != nil {
+			return "", fmt.Errorf("proto: %s: %w", sc.Name, err)
+		}
+		g.messages = append(g.messages, def)
+	}
+
+	var b strings.Builder
+	b.WriteString("syntax = \"proto3\";\n\n")
+	if pkg != "" {
+		fmt.Fprintf(&b, "package %s;\n\n", pkg)
+	}
+	if len(g.imports) > 0 {
+		for _, imp := range sortedKeys(g.imports) {
+			fmt.Fprintf(&b, "import \"%s\";\n", imp)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Join(g.messages, "\n"))
+	return b.String(), nil
+}
+
+func (g *ProtoGenerator) enqueue(sc *Schema) {
+	if _, ok := g.queued[sc]; ok {
+		return
+	}
+	g.queued[sc] = struct{}{}
+	g.queue = append(g.queue, sc)
+}
+
+// message returns definition of the message.
+// The key is used for ProtoNumbering, it is qualified by the parent for nested message.
+func (g *ProtoGenerator) message(key, name string, fields []*Field, indent string) (string, error) {
+	type protoField struct {
+		def    string
+		name   string
+		number int
+	}
+
+	nums := g.numbering[key]
+	if nums == nil {
+		nums = map[string]int{}
+	}
+	used := map[int]string{}
+	var pfs []*protoField
+	var nested []string
+	for _, f := range fields {
+		if !f.IsExported() || f.IsFunc() {
+			continue
+		}
+		if t, ok := f.Tag(protobufTagKey); ok && t.Name() == "-" {
+			continue
+		}
+
+		fname := toSnakeCase(f.Name)
+		typ, err := g.fieldType(key, f, indent, &nested)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		num := protoFieldNumber(f)
+		if num == 0 {
+			num = nums[fname]
+		}
+		pf := &protoField{def: typ, name: fname, number: num}
+		if num != 0 {
+			if other, ok := used[num]; ok {
+				return "", fmt.Errorf("field number %d is used by both %s and %s", num, other, fname)
+			}
+			used[num] = fname
+		}
+		pfs = append(pfs, pf)
+	}
+
+	// assign new numbers after the largest number including removed fields
+	maxNum := 0
+	for _, n := range nums {
+		if n > maxNum {
+			maxNum = n
+		}
+	}
+	for n := range used {
+		if n > maxNum {
+			maxNum = n
+		}
+	}
+	for _, pf := range pfs {
+		if pf.number == 0 {
+			maxNum++
+			pf.number = maxNum
+			used[pf.number] = pf.name
+		}
+		nums[pf.name] = pf.number
+	}
+	g.numbering[key] = nums
+
+	// numbers of removed fields
+	var reserved []int
+	for _, n := range nums {
+		if _, ok := used[n]; !ok {
+			reserved = append(reserved, n)
+		}
+	}
+	sort.Ints(reserved)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%smessage %s {\n", indent, name)
+	for _, n := range nested {
+		b.WriteString(n)
+	}
+	if len(reserved) > 0 {
+		strs := make([]string, len(reserved))
+		for i, n := range reserved {
+			strs[i] = strconv.Itoa(n)
+		}
+		fmt.Fprintf(&b, "%s  reserved %s;\n", indent, strings.Join(strs, ", "))
+	}
+	for _, pf := range pfs {
+		fmt.Fprintf(&b, "%s  %s %s = %d;\n", indent, pf.def, pf.name, pf.number)
+	}
+	fmt.Fprintf(&b, "%s}\n", indent)
+	return b.String(), nil
+}
+
+// fieldType returns type of the field with label like `repeated` or `optional`.
+func (g *ProtoGenerator) fieldType(msg string, f *Field, indent string, nested *[]string) (string, error) {
+	prefixes := f.TypePrefixes
+	bytes := false
+	if n := len(prefixes); n > 0 && prefixes[n-1] == TypePrefixSlice && f.Type != nil && isByte(f.Type) {
+		bytes = true
+		prefixes = prefixes[:n-1]
+	}
+	var repeated, optional bool
+	for _, pref := range prefixes {
+		switch pref.Kind() {
+		case TypePrefixKindPtr:
+			if !repeated {
+				optional = true
+			}
+		case TypePrefixKindSlice, TypePrefixKindArray:
+			if repeated {
+				return "", fmt.Errorf("nested repeated is not supported")
+			}
+			if optional {
+				return "", fmt.Errorf("pointer to slice is not supported")
+			}
+			repeated = true
+		}
+	}
+
+	var typ string
+	var scalar bool
+	switch {
+	case bytes:
+		typ, scalar = "bytes", true
+	case f.IsMap():
+		if repeated {
+			return "", fmt.Errorf("repeated map is not supported")
+		}
+		return g.mapType(msg, f, indent, nested)
+	case f.IsUntitledStruct:
+		typ = f.Name
+		var fields []*Field
+		if f.Schema != nil {
+			fields = f.Schema.Fields
+		}
+		def, err := g.message(msg+"."+typ, typ, fields, indent+"  ")
+		if err != nil {
+			return "", err
+		}
+		*nested = append(*nested, def)
+	case f.Type != nil:
+		var err error
+		typ, scalar, err = g.typeName(f.Type)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported type")
+	}
+
+	switch {
+	case repeated:
+		return "repeated " + typ, nil
+	case optional && scalar:
+		return "optional " + typ, nil
+	}
+	return typ, nil
+}
+
+func (g *ProtoGenerator) mapType(msg string, f *Field, indent string, nested *[]string) (string, error) {
+	k, v := f.Map.Key, f.Map.Value
+	if k == nil || v == nil || k.Type == nil || len(k.TypePrefixes) != 0 {
+		return "", fmt.Errorf("unsupported map key")
+	}
+	kt, _, err := g.typeName(k.Type)
+	if err != nil {
+		return "", err
+	}
+	switch kt {
+	case "double", "float", "bytes":
+		return "", fmt.Errorf("unsupported map key: %s", kt)
+	}
+	if v.IsMap() {
+		return "", fmt.Errorf("map of map is not supported")
+	}
+	vt, err := g.fieldType(msg, &Field{
+		Name:             f.Name + "Value",
+		Type:             v.Type,
+		TypePrefixes:     v.TypePrefixes,
+		IsUntitledStruct: v.IsUntitledStruct,
+		Schema:           v.Schema,
+	}, indent, nested)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(vt, "repeated ") {
+		return "", fmt.Errorf("map of repeated value is not supported")
+	}
+	return fmt.Sprintf("map<%s, %s>", kt, strings.TrimPrefix(vt, "optional ")), nil
+}
+
+// typeName returns name of proto type and whether it is scalar or not.
+func (g *ProtoGenerator) typeName(t *Type) (string, bool, error) {
+	if wk, ok := protoWellKnownTypes[t.Underlying]; ok {
+		g.imports[wk.file] = struct{}{}
+		return wk.name, false, nil
+	}
+	if t.IsBasic() {
+		if t.TypeName != string(t.Underlying) {
+			// named basic type in the same package like `type Status int`
+			if sc, ok := g.idx.LookupName(g.pkgID, t.TypeName); ok && sc.IsEnum() {
+				g.enqueue(sc)
+				return sc.Name, true, nil
+			}
+		}
+		typ, err := protoScalarType(t.Underlying)
+		return typ, true, err
+	}
+	sc, ok := g.idx.Lookup(t)
+	if !ok {
+		return "", false, fmt.Errorf("unknown type: %s", t.Underlying)
+	}
+	if sc.IsEnum() {
+		g.enqueue(sc)
+		return sc.Name, true, nil
+	}
+	if sc.IsStruct() && len(sc.TypePrefixes) == 0 {
+		g.enqueue(sc)
+		return sc.Name, false, nil
+	}
+	if sc.Type != nil && sc.Type.IsBasic() && len(sc.TypePrefixes) == 0 {
+		typ, err := protoScalarType(sc.Type.Underlying)
+		return typ, true, err
+	}
+	return "", false, fmt.Errorf("unsupported type: %s", t.Underlying)
+}
+
+func (g *ProtoGenerator) enum(sc *Schema) (string, error) {
+	prefix := strings.ToUpper(toSnakeCase(sc.Name)) + "_"
+	type value struct {
+		name   string
+		number int64
+	}
+
+	var values []*value
+	numeric := true
+	for _, c := range sc.Consts {
+		if _, err := strconv.ParseInt(c.Value, 10, 32); err != nil {
+			numeric = false
+			break
+		}
+	}
+	hasZero := false
+	for i, c := range sc.Consts {
+		name := strings.ToUpper(toSnakeCase(c.Name))
+		if !strings.HasPrefix(name, prefix) {
+			name = prefix + name
+		}
+		v := &value{name: name, number: int64(i + 1)}
+		if numeric {
+			v.number, _ = strconv.ParseInt(c.Value, 10, 32)
+		}
+		if v.number == 0 {
+			hasZero = true
+		}
+		values = append(values, v)
+	}
+	if !hasZero {
+		// the first value of proto3 enum must be zero
+		values = append([]*value{{name: prefix + "UNSPECIFIED"}}, values...)
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].number < values[j].number
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "enum %s {\n", sc.Name)
+	for i := 1; i < len(values); i++ {
+		// consts having the same value are aliases
+		if values[i].number == values[i-1].number {
+			b.WriteString("  option allow_alias = true;\n")
+			break
+		}
+	}
+	for _, v := range values {
+		fmt.Fprintf(&b, "  %s = %d;\n", v.name, v.number)
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// protoFieldNumber returns field number written in tag or 0.
+func protoFieldNumber(f *Field) int {
+	if t, ok := f.Tag(protobufTagKey); ok && len(t.Values) > 1 {
+		if n, err := strconv.Atoi(t.Values[1]); err == nil {
+			return n
+		}
+	}
+	if t, ok := f.Tag(protoTagKey); ok {
+		if n, err := strconv.Atoi(t.Name()); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+func protoScalarType(u UnderlyingType) (string, error) {
+	switch u {
+	case "string":
+		return "string", nil
+	case "bool":
+		return "bool", nil
+	case "int8", "int16", "int32", "rune":
+		return "int32", nil
+	case "int", "int64":
+		return "int64", nil
+	case "uint8", "uint16", "uint32", "byte":
+		return "uint32", nil
+	case "uint", "uint64":
+		return "uint64", nil
+	case "float32":
+		return "float", nil
+	case "float64":
+		return "double", nil
+	}
+	return "", fmt.Errorf("unsupported type: %s", u)
+}
@@ -0,0 +1,136 @@
+package stst_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const protoSrc = `package data
+
+import "time"
+
+type Status int
+
+const (
+	StatusActive   Status = 1
+	StatusInactive Status = 2
+)
+
+type Order struct {
+	ID     int64 ` + "`protobuf:\"varint,3,opt,name=id,proto3\"`" + `
+	Status Status
+	Note   *string
+	Goods  []*Good
+	Labels map[string]int32
+	Meta   struct {
+		Note string
+	}
+	CreatedAt time.Time
+	secret    string
+}
+
+type Level int
+
+const (
+	LevelLow    Level = 1
+	LevelNormal Level = 1
+	LevelHigh   Level = 2
+)
+
+type Good struct {
+	Name  string ` + "`proto:\"5\"`" + `
+	Raw   []byte
+	Level Level
+}
+`
+
+func TestProtoGenerate(t *testing.T) {
+	schemas := parseSource(t, protoSrc)
+	g := stst.NewProtoGenerator(schemas, stst.ProtoNumbering{
+		"Order": {"status": 1, "removed": 10},
+	})
+	got, err := g.Generate("example.v1", []*stst.Schema{findSchema(schemas, "Order")})
+	require.NoError(t, err)
+
+	want := `syntax = "proto3";
+
+package example.v1;
+
+import "google/protobuf/timestamp.proto";
+
+message Order {
+  message Meta {
+    string note = 1;
+  }
+  reserved 10;
+  int64 id = 3;
+  Status status = 1;
+  optional string note = 11;
+  repeated Good goods = 12;
+  map<string, int32> labels = 13;
+  Meta meta = 14;
+  google.protobuf.Timestamp created_at = 15;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_INACTIVE = 2;
+}
+
+message Good {
+  string name = 5;
+  bytes raw = 6;
+  Level level = 7;
+}
+
+enum Level {
+  option allow_alias = true;
+  LEVEL_UNSPECIFIED = 0;
+  LEVEL_LOW = 1;
+  LEVEL_NORMAL = 1;
+  LEVEL_HIGH = 2;
+}
+`
+	assert.Equal(t, want, got)
+	assert.Equal(t, stst.ProtoNumbering{
+		"Order": {
+			"id": 3, "status": 1, "note": 11, "goods": 12, "labels": 13,
+			"meta": 14, "created_at": 15, "removed": 10,
+		},
+		"Order.Meta": {"note": 1},
+		"Good":       {"name": 5, "raw": 6, "level": 7},
+	}, g.Numbering())
+}
+
+func TestProtoGenerate_SameNameInOtherPackage(t *testing.T) {
+	const src = "package %s\n\ntype Kind int\n\n%s"
+	other := loadSource(t, "example.com/other", fmt.Sprintf(src, "other", "const KindA Kind = 1\n"))
+	local := loadSource(t, testPkg, fmt.Sprintf(src, "data", "type Item struct {\n\tKind Kind\n}\n"))
+	schemas := append(stst.NewParser(other).Parse(), stst.NewParser(local).Parse()...)
+
+	got, err := stst.NewProtoGenerator(schemas, nil).Generate("", []*stst.Schema{findSchema(schemas, "Item")})
+	require.NoError(t, err)
+	// Kind of the package is not enum
+	assert.Equal(t, "syntax = \"proto3\";\n\nmessage Item {\n  int64 kind = 1;\n}\n", got)
+}
+
+func TestProtoNumbering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "numbering.json")
+
+	n, err := stst.LoadProtoNumbering(path)
+	require.NoError(t, err)
+	assert.Equal(t, stst.ProtoNumbering{}, n)
+
+	n["Order"] = map[string]int{"id": 1}
+	require.NoError(t, n.Save(path))
+
+	loaded, err := stst.LoadProtoNumbering(path)
+	require.NoError(t, err)
+	assert.Equal(t, n, loaded)
+}
@@ -0,0 +1,564 @@
+package stst
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	// Query finds schemas by an expression.
+	//
+	// Expression is terms like `key:value` joined by `and` (or spaces), `or` and `not` with parentheses.
+	// Value is matched exactly, or by regexp if it starts with `~` (like `name:~^User`),
+	// Value ends at space or unbalanced `)`, and it can be quoted like `type:"func() error"`.
+	// Boolean keys can be written without value (like `embedded` for `embedded:true`).
+	//
+	// Keys of schemas:
+	//   - name, pkg (package path of the type), type (definition like `[]*Good`), exported, generic
+	//   - kind: struct, interface, func, map, enum or type (defined as other type)
+	//   - field(...): the schema has a field matching the expression in parentheses
+	//   - method(...): the schema has a method (declared method, or method of interface) matching the expression
+	//
+	// Keys of fields:
+	//   - name, type (qualified by package names like `*time.Time` or `[]model.Status`), pkg, exported, embedded
+	//   - prefix: kind of type prefixes (pointer, slice or array) or the prefixes (like `[]*`)
+	//   - tag: key of the tag (like `tag:bigquery`) or key and name (like `tag:json=user_id`)
+	//   - tagopt: key and option of the tag like `tagopt:json=omitempty`
+	//
+	// Keys of methods:
+	//   - name, exported, pointer (pointer receiver)
+	//   - arg, result: type of any argument or result
+	//   - takes, returns: types of all arguments or results joined by `,` (like `returns:int,error`)
+	//   - nargs, nresults: number of arguments or results
+	//
+	// For example, `kind:struct field(tag:bigquery type:*time.Time)` finds structs with a field
+	// tagged `bigquery` whose type is `*time.Time`, and `kind:interface method(returns:error)` finds
+	// interfaces with a method returning only error.
+	Query struct {
+		expr string
+		root queryNode
+	}
+
+	// QueryMatch is a Schema found by Query.
+	QueryMatch struct {
+		Schema *Schema
+		// Fields are fields matched by `field(...)` in the expression (except in `not`)
+		Fields []*Field
+		// Methods are methods matched by `method(...)` in the expression (except in `not`).
+		// Methods of interface are converted from its fields.
+		Methods []*Method
+	}
+
+	// queryScope is what terms are evaluated against.
+	queryScope string
+
+	// queryTarget is evaluated by queryNode.
+	queryTarget struct {
+		schema *Schema
+		field  *Field
+		method *Method
+		// match collects fields and methods, it is nil in `not`
+		match *QueryMatch
+	}
+
+	queryNode interface {
+		eval(t *queryTarget) bool
+	}
+
+	queryAnd struct{ left, right queryNode }
+	queryOr  struct{ left, right queryNode }
+	queryNot struct{ node queryNode }
+
+	// queryHas is `field(...)` or `method(...)`.
+	queryHas struct {
+		scope queryScope
+		node  queryNode
+	}
+
+	queryTerm struct {
+		matcher *queryMatcher
+		match   func(t *queryTarget, m *queryMatcher) bool
+	}
+
+	// queryMatcher matches strings exactly or by regexp.
+	queryMatcher struct {
+		value string
+		reg   *regexp.Regexp
+	}
+
+	queryParser struct {
+		src string
+		pos int
+	}
+)
+
+const (
+	queryScopeSchema = queryScope("schema")
+	queryScopeField  = queryScope("field")
+	queryScopeMethod = queryScope("method")
+)
+
+// queryKeys are keys of terms for each scope.
+var queryKeys = map[queryScope]map[string]func(t *queryTarget, m *queryMatcher) bool{
+	queryScopeSchema: {
+		"name": func(t *queryTarget, m *queryMatcher) bool { return m.match(t.schema.Name) },
+		"pkg": func(t *queryTarget, m *queryMatcher) bool {
+			return isSelfType(t.schema) && m.match(t.schema.Type.PkgID)
+		},
+		"kind":     func(t *queryTarget, m *queryMatcher) bool { return m.match(querySchemaKind(t.schema)) },
+		"exported": func(t *queryTarget, m *queryMatcher) bool { return m.matchBool(token.IsExported(t.schema.Name)) },
+		"generic":  func(t *queryTarget, m *queryMatcher) bool { return m.matchBool(len(t.schema.TypeParams) > 0) },
+		"type": func(t *queryTarget, m *queryMatcher) bool {
+			sc := t.schema
+			return m.match(queryTypeString(&Field{Type: sc.Type, TypePrefixes: sc.TypePrefixes, Func: sc.Func, Map: sc.Map}, sc.TypeParams))
+		},
+	},
+	queryScopeField: {
+		"name": func(t *queryTarget, m *queryMatcher) bool { return m.match(t.field.Name) },
+		"type": func(t *queryTarget, m *queryMatcher) bool {
+			return m.match(queryTypeString(t.field, t.schema.TypeParams))
+		},
+		"pkg":      func(t *queryTarget, m *queryMatcher) bool { return t.field.Type != nil && m.match(t.field.Type.PkgID) },
+		"exported": func(t *queryTarget, m *queryMatcher) bool { return m.matchBool(t.field.IsExported()) },
+		"embedded": func(t *queryTarget, m *queryMatcher) bool { return m.matchBool(t.field.IsEmbedded) },
+		"prefix":   queryPrefix,
+		"tag":      queryTag,
+		"tagopt":   queryTagOption,
+	},
+	queryScopeMethod: {
+		"name":     func(t *queryTarget, m *queryMatcher) bool { return m.match(t.method.Name) },
+		"exported": func(t *queryTarget, m *queryMatcher) bool { return m.matchBool(token.IsExported(t.method.Name)) },
+		"pointer":  func(t *queryTarget, m *queryMatcher) bool { return m.matchBool(t.method.IsPointerReceiver) },
+		"arg":      func(t *queryTarget, m *queryMatcher) bool { return m.matchAny(queryTypeStrings(t, t.method.Func.Args)) },
+		"result": func(t *queryTarget, m *queryMatcher) bool {
+			return m.matchAny(queryTypeStrings(t, t.method.Func.Results))
+		},
+		"takes": func(t *queryTarget, m *queryMatcher) bool {
+			return m.match(strings.Join(queryTypeStrings(t, t.method.Func.Args), ","))
+		},
+		"returns": func(t *queryTarget, m *queryMatcher) bool {
+			return m.match(strings.Join(queryTypeStrings(t, t.method.Func.Results), ","))
+		},
+		"nargs":    func(t *queryTarget, m *queryMatcher) bool { return m.match(strconv.Itoa(len(t.method.Func.Args))) },
+		"nresults": func(t *queryTarget, m *queryMatcher) bool { return m.match(strconv.Itoa(len(t.method.Func.Results))) },
+	},
+}
+
+// ParseQuery parses the expression of Query. Empty expression matches all schemas.
+// It returns error if the expression has syntax errors, unknown keys or invalid regexps.
+func ParseQuery(expr string) (*Query, error) {
+	if strings.TrimSpace(expr) == "" {
+		return &Query{expr: expr}, nil
+	}
+	p := &queryParser{src: expr}
+	root, err := p.parseOr(queryScopeSchema)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	p.skipSpaces()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("query: unexpected %q at %d", p.src[p.pos:], p.pos)
+	}
+	return &Query{expr: expr, root: root}, nil
+}
+
+// String returns the expression.
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Match returns whether the Schema matches the Query.
+func (q *Query) Match(sc *Schema) bool {
+	if q.root == nil {
+		return true
+	}
+	return q.root.eval(&queryTarget{schema: sc})
+}
+
+// Filter returns schemas matching the Query in order of the schemas.
+func (q *Query) Filter(schemas []*Schema) []*Schema {
+	var out []*Schema
+	for _, sc := range schemas {
+		if q.Match(sc) {
+			out = append(out, sc)
+		}
+	}
+	return out
+}
+
+// Find returns schemas matching the Query with their matched fields and methods.
+func (q *Query) Find(schemas []*Schema) []*QueryMatch {
+	var out []*QueryMatch
+	for _, sc := range schemas {
+		m := &QueryMatch{Schema: sc}
+		if q.root != nil && !q.root.eval(&queryTarget{schema: sc, match: m}) {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func (n *queryAnd) eval(t *queryTarget) bool {
+	if t.match == nil {
+		return n.left.eval(t) && n.right.eval(t)
+	}
+	// fields and methods collected by the unmatched operand are discarded
+	nf, nm := len(t.match.Fields), len(t.match.Methods)
+	if n.left.eval(t) && n.right.eval(t) {
+		return true
+	}
+	t.match.Fields, t.match.Methods = t.match.Fields[:nf], t.match.Methods[:nm]
+	return false
+}
+
+func (n *queryOr) eval(t *queryTarget) bool {
+	// both sides are evaluated to collect matched fields and methods
+	l := n.left.eval(t)
+	r := n.right.eval(t)
+	return l || r
+}
+
+func (n *queryNot) eval(t *queryTarget) bool {
+	tt := *t
+	tt.match = nil
+	return !n.node.eval(&tt)
+}
+
+func (n *queryHas) eval(t *queryTarget) bool {
+	var found bool
+	switch n.scope {
+	case queryScopeField:
+		for _, f := range t.schema.Fields {
+			if !n.node.eval(&queryTarget{schema: t.schema, field: f}) {
+				continue
+			}
+			found = true
+			if t.match == nil {
+				return true
+			}
+			t.match.addField(f)
+		}
+	case queryScopeMethod:
+		for _, m := range queryMethods(t.schema) {
+			if !n.node.eval(&queryTarget{schema: t.schema, method: m}) {
+				continue
+			}
+			found = true
+			if t.match == nil {
+				return true
+			}
+			t.match.addMethod(m)
+		}
+	}
+	return found
+}
+
+func (n *queryTerm) eval(t *queryTarget) bool {
+	return n.match(t, n.matcher)
+}
+
+func (m *QueryMatch) addField(f *Field) {
+	for _, ff := range m.Fields {
+		if ff == f {
+			return
+		}
+	}
+	m.Fields = append(m.Fields, f)
+}
+
+func (m *QueryMatch) addMethod(method *Method) {
+	for _, mm := range m.Methods {
+		if mm.Name == method.Name {
+			return
+		}
+	}
+	m.Methods = append(m.Methods, method)
+}
+
+func newQueryMatcher(value string) (*queryMatcher, error) {
+	if !strings.HasPrefix(value, "~") {
+		return &queryMatcher{value: value}, nil
+	}
+	reg, err := regexp.Compile(value[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &queryMatcher{value: value, reg: reg}, nil
+}
+
+func (m *queryMatcher) match(s string) bool {
+	if m.reg != nil {
+		return m.reg.MatchString(s)
+	}
+	return m.value == s
+}
+
+func (m *queryMatcher) matchAny(ss []string) bool {
+	for _, s := range ss {
+		if m.match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *queryMatcher) matchBool(b bool) bool {
+	return m.match(strconv.FormatBool(b))
+}
+
+// querySchemaKind returns kind of the Schema for `kind` key.
+func querySchemaKind(sc *Schema) string {
+	switch {
+	case sc.IsInterface:
+		return "interface"
+	case sc.IsStruct():
+		return "struct"
+	case sc.IsFunc():
+		return "func"
+	case sc.IsMap():
+		return "map"
+	case sc.IsEnum():
+		return "enum"
+	}
+	return "type"
+}
+
+// queryMethods returns declared methods of the Schema and methods of the interface.
+func queryMethods(sc *Schema) []*Method {
+	out := append([]*Method{}, sc.Methods...)
+	if !sc.IsInterface {
+		return out
+	}
+	for _, f := range sc.Fields {
+		if f.IsFunc() && !f.IsEmbedded {
+			out = append(out, &Method{Name: f.Name, Func: f.Func, Doc: f.Doc})
+		}
+	}
+	return out
+}
+
+// queryTypeString returns type expression of the Field qualified by package names like `*time.Time`.
+func queryTypeString(f *Field, typeParams []*TypeParam) string {
+	return newGoTypePrinter(GoPackage{}).field(f, "", typeParamNames(typeParams))
+}
+
+func queryTypeStrings(t *queryTarget, fields []*Field) []string {
+	out := make([]string, len(fields))
+	for i, f := range fields {
+		out[i] = queryTypeString(f, t.schema.TypeParams)
+	}
+	return out
+}
+
+func queryPrefix(t *queryTarget, m *queryMatcher) bool {
+	var b strings.Builder
+	for _, pref := range t.field.TypePrefixes {
+		if m.match(string(pref.Kind())) {
+			return true
+		}
+		b.WriteString(string(pref))
+	}
+	return m.match(b.String())
+}
+
+// queryTag matches `key` or `key=name` of tags.
+func queryTag(t *queryTarget, m *queryMatcher) bool {
+	for _, tag := range t.field.Tags {
+		if m.match(tag.Key) || m.match(tag.Key+"="+tag.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryTagOption matches `key=option` of tags.
+func queryTagOption(t *queryTarget, m *queryMatcher) bool {
+	for _, tag := range t.field.Tags {
+		if len(tag.Values) < 2 {
+			continue
+		}
+		for _, opt := range tag.Values[1:] {
+			if m.match(tag.Key + "=" + opt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// peekWord returns the next word which is a keyword, key or operator.
+func (p *queryParser) peekWord() string {
+	p.skipSpaces()
+	i := p.pos
+	for i < len(p.src) && isQueryKeyChar(p.src[i]) {
+		i++
+	}
+	if i == p.pos {
+		switch {
+		case strings.HasPrefix(p.src[i:], "&&"), strings.HasPrefix(p.src[i:], "||"):
+			return p.src[i : i+2]
+		case i < len(p.src):
+			return p.src[i : i+1]
+		}
+	}
+	return p.src[p.pos:i]
+}
+
+func isQueryKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+func (p *queryParser) parseOr(scope queryScope) (queryNode, error) {
+	left, err := p.parseAnd(scope)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		w := p.peekWord()
+		if w != "or" && w != "||" {
+			return left, nil
+		}
+		p.pos += len(w)
+		right, err := p.parseAnd(scope)
+		if err != nil {
+			return nil, err
+		}
+		left = &queryOr{left: left, right: right}
+	}
+}
+
+func (p *queryParser) parseAnd(scope queryScope) (queryNode, error) {
+	left, err := p.parseUnary(scope)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		w := p.peekWord()
+		switch w {
+		case "", ")", "or", "||":
+			return left, nil
+		case "and", "&&":
+			p.pos += len(w)
+		}
+		right, err := p.parseUnary(scope)
+		if err != nil {
+			return nil, err
+		}
+		left = &queryAnd{left: left, right: right}
+	}
+}
+
+func (p *queryParser) parseUnary(scope queryScope) (queryNode, error) {
+	switch w := p.peekWord(); w {
+	case "not", "!":
+		p.pos += len(w)
+		node, err := p.parseUnary(scope)
+		if err != nil {
+			return nil, err
+		}
+		return &queryNot{node: node}, nil
+	case "(":
+		p.pos++
+		node, err := p.parseOr(scope)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case "":
+		return nil, fmt.Errorf("unexpected end of query")
+	case ")", "and", "&&", "or", "||":
+		return nil, fmt.Errorf("unexpected %q at %d", w, p.pos)
+	default:
+		if !isQueryKeyChar(w[0]) {
+			return nil, fmt.Errorf("unexpected %q at %d", w, p.pos)
+		}
+		return p.parseTerm(scope, w)
+	}
+}
+
+func (p *queryParser) parseTerm(scope queryScope, key string) (queryNode, error) {
+	start := p.pos
+	p.pos += len(key)
+	sub := queryScope(key)
+	if scope == queryScopeSchema && (sub == queryScopeField || sub == queryScopeMethod) && p.pos < len(p.src) && p.src[p.pos] == '(' {
+		p.pos++
+		node, err := p.parseOr(sub)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &queryHas{scope: sub, node: node}, nil
+	}
+
+	match, ok := queryKeys[scope][key]
+	if !ok {
+		return nil, fmt.Errorf("unknown key of %s: %q at %d", scope, key, start)
+	}
+	value := "true"
+	if p.pos < len(p.src) && p.src[p.pos] == ':' {
+		p.pos++
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+	m, err := newQueryMatcher(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regexp of %s at %d: %w", key, start, err)
+	}
+	return &queryTerm{matcher: m, match: match}, nil
+}
+
+// parseValue parses a quoted string or characters until space or unbalanced `)` like `~^(A|B)$`.
+func (p *queryParser) parseValue() (string, error) {
+	rest := p.src[p.pos:]
+	if len(rest) > 0 && (rest[0] == '"' || rest[0] == '`') {
+		q, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value at %d: %w", p.pos, err)
+		}
+		p.pos += len(q)
+		return strconv.Unquote(q)
+	}
+	var depth, i int
+	for ; i < len(rest) && !unicode.IsSpace(rune(rest[i])); i++ {
+		if rest[i] == '(' {
+			depth++
+		} else if rest[i] == ')' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		}
+	}
+	p.pos += i
+	return rest[:i], nil
+}
+
+func (p *queryParser) expect(s string) error {
+	if p.peekWord() != s {
+		if p.pos >= len(p.src) {
+			return fmt.Errorf("expected %q at end of query", s)
+		}
+		return fmt.Errorf("expected %q at %d", s, p.pos)
+	}
+	p.pos += len(s)
+	return nil
+}
@@ -0,0 +1,184 @@
+package stst_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const querySrc = `package data
+
+import "time"
+
+type Good struct {
+	Name      string  ` + "`json:\"name\" db:\"name\"`" + `
+	SamplePtr *string ` + "`json:\"sample_ptr,omitempty\"`" + `
+}
+
+type Person struct {
+	Name  string ` + "`json:\"name\" bigquery:\"name\"`" + `
+	Age   int    ` + "`json:\"age,omitempty\" bigquery:\"age\"`" + `
+	Sex   string ` + "`json:\"-\" bigquery:\"-\"`" + `
+	Hobby string ` + "`bigquery:\"hobby,nullable\"`" + `
+	Good
+}
+
+type Animal struct {
+	ID      string         ` + "`json:\"id\" bigquery:\"id\"`" + `
+	Goods   []*Good        ` + "`json:\"goods\"`" + `
+	GoodPtr *Good          ` + "`json:\"good_ptr,omitempty\" bigquery:\"good_ptr\"`" + `
+	Born    time.Time      ` + "`json:\"born\"`" + `
+	Attrs   map[string]int ` + "`json:\"attrs\"`" + `
+	strs    []string
+	Fn      func(v any)
+}
+
+type Repository interface {
+	Get(id string) (*Animal, error)
+	Delete(id string) error
+}
+
+type Status int
+
+const StatusActive Status = 1
+
+func (s Status) String() string { return "" }
+
+func (s *Status) Validate() error { return nil }
+`
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    []string
+		fields  map[string][]string
+		methods map[string][]string
+	}{
+		{
+			name: "empty",
+			expr: " ",
+			want: []string{"Good", "Person", "Animal", "Repository", "Status"},
+		},
+		{
+			name: "kind and name",
+			expr: `kind:struct name:~^(Good|Animal)$`,
+			want: []string{"Good", "Animal"},
+		},
+		{
+			name:   "field with tag and type",
+			expr:   `kind:struct field(tag:json type:*data.Good)`,
+			want:   []string{"Animal"},
+			fields: map[string][]string{"Animal": {"GoodPtr"}},
+		},
+		{
+			name:   "tag name and option",
+			expr:   `field(tag:json=name) and field(tagopt:json=omitempty)`,
+			want:   []string{"Good", "Person"},
+			fields: map[string][]string{"Good": {"Name", "SamplePtr"}, "Person": {"Name", "Age"}},
+		},
+		{
+			name:   "type of field",
+			expr:   `field(type:time.Time pkg:time) || field(type:"map[string]int")`,
+			want:   []string{"Animal"},
+			fields: map[string][]string{"Animal": {"Born", "Attrs"}},
+		},
+		{
+			name:   "prefix and embedded",
+			expr:   `field(prefix:slice exported) or field(embedded)`,
+			want:   []string{"Person", "Animal"},
+			fields: map[string][]string{"Person": {"Good"}, "Animal": {"Goods"}},
+		},
+		{
+			name:   "prefixes",
+			expr:   `field(prefix:"[]*")`,
+			want:   []string{"Animal"},
+			fields: map[string][]string{"Animal": {"Goods"}},
+		},
+		{
+			name:    "interface method returning only error",
+			expr:    `kind:interface method(returns:error)`,
+			want:    []string{"Repository"},
+			methods: map[string][]string{"Repository": {"Delete"}},
+		},
+		{
+			name:    "methods",
+			expr:    `method(result:error nargs:1 takes:string) or method(pointer)`,
+			want:    []string{"Repository", "Status"},
+			methods: map[string][]string{"Repository": {"Get", "Delete"}, "Status": {"Validate"}},
+		},
+		{
+			name: "not",
+			expr: `!(kind:struct or kind:interface) and kind:enum type:int`,
+			want: []string{"Status"},
+		},
+		{
+			name: "not field",
+			expr: `kind:struct not field(tag:bigquery)`,
+			want: []string{"Good"},
+		},
+		{
+			name:   "unmatched operand",
+			expr:   `(field(name:Name) kind:interface) or field(name:ID)`,
+			want:   []string{"Animal"},
+			fields: map[string][]string{"Animal": {"ID"}},
+		},
+		{
+			name: "pkg",
+			expr: `pkg:github.com/maru44/stst/tests/data exported:false`,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := stst.ParseQuery(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expr, q.String())
+
+			schemas := parseSource(t, querySrc)
+			var got []string
+			fields := map[string][]string{}
+			methods := map[string][]string{}
+			for _, m := range q.Find(schemas) {
+				got = append(got, m.Schema.Name)
+				assert.True(t, q.Match(m.Schema))
+				for _, f := range m.Fields {
+					fields[m.Schema.Name] = append(fields[m.Schema.Name], f.Name)
+				}
+				for _, method := range m.Methods {
+					methods[m.Schema.Name] = append(methods[m.Schema.Name], method.Name)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.want), len(q.Filter(schemas)))
+			if tt.fields == nil {
+				tt.fields = map[string][]string{}
+			}
+			if tt.methods == nil {
+				tt.methods = map[string][]string{}
+			}
+			assert.Equal(t, tt.fields, fields)
+			assert.Equal(t, tt.methods, methods)
+		})
+	}
+}
+
+func TestParseQuery_Error(t *testing.T) {
+	for _, expr := range []string{
+		`kind:struct or`,
+		`(kind:struct`,
+		`kind:struct)`,
+		`unknown:x`,
+		`field(field(name:x))`,
+		`method(tag:json)`,
+		`name:~(`,
+		`name:"x`,
+		`and kind:struct`,
+	} {
+		_, err := stst.ParseQuery(expr)
+		assert.Error(t, err, expr)
+	}
+}
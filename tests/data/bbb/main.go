@@ -0,0 +1,53 @@
+package bbb
+
+type (
+	Status int
+
+	Color string
+
+	Order struct {
+		ID     int64 `protobuf:"varint,1,opt,name=id,proto3"`
+		Status Status
+		Color  *Color
+		Items  []*Item
+		Labels map[string]string
+		Meta   struct {
+			Note string
+		}
+	}
+
+	Item struct {
+		Name  string  `json:"name" validate:"required,oneof=a b"`
+		Price float64 `json:"price,omitempty" validate:"gte=0"`
+	}
+
+	Repository[T any, K comparable] interface {
+		Get(id K) (T, error)
+		Find(ids ...K) []T
+		Set(key, value string)
+	}
+)
+
+const (
+	StatusUnknown Status = iota
+	// active status
+	StatusActive
+	StatusInactive // inactive status
+)
+
+const ColorRed Color = "red"
+
+const (
+	ColorBlue Color = "blue"
+
+	notEnum = 1
+)
+
+// Equal returns whether the items are same.
+func (i *Item) Equal(other *Item) bool {
+	return i.Name == other.Name && i.Price == other.Price
+}
+
+func (s Status) IsActive() bool {
+	return s == StatusActive
+}
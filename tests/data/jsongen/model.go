@@ -0,0 +1,88 @@
+package jsongen
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	Level int
+
+	Tags []string
+
+	Raw []byte
+
+	Base struct {
+		ID        int64     `json:"id"`
+		CreatedAt time.Time `json:"created_at"`
+		// Name is hidden by Order.Name
+		Name string
+	}
+
+	Extra struct {
+		Note string `json:"note,omitempty"`
+	}
+
+	Order struct {
+		Base
+		*Extra
+		Name    string             `json:"name"`
+		Email   string             `json:"email,omitempty"`
+		Price   float64            `json:"price"`
+		Rate    float32            `json:"rate,omitempty"`
+		Count   int                `json:"count,string"`
+		Active  bool               `json:"active,omitempty,string"`
+		Level   Level              `json:"level"`
+		Ptr     *int               `json:"ptr"`
+		Limit   *uint32            `json:"limit,string,omitempty"`
+		Tags    Tags               `json:"tags"`
+		Items   []*Item            `json:"items,omitempty"`
+		Matrix  [2][2]int8         `json:"matrix"`
+		Labels  map[string]string  `json:"labels"`
+		Scores  map[int]float64    `json:"scores,omitempty"`
+		Levels  map[string][]Level `json:"levels,omitempty"`
+		Data    []byte             `json:"data"`
+		Raw     Raw
+		Any     any           `json:"any"`
+		Timeout time.Duration `json:"timeout"`
+		Meta    struct {
+			Source string `json:"source"`
+		} `json:"meta"`
+		Ignored string `json:"-"`
+		Dash    string `json:"-,"`
+		U8      uint8
+		private int
+	}
+
+	Item struct {
+		SKU string `json:"sku"`
+		Qty uint16 `json:"qty"`
+	}
+)
+
+const (
+	LevelLow Level = iota
+	LevelHigh
+)
+
+func (l Level) MarshalText() ([]byte, error) {
+	switch l {
+	case LevelLow:
+		return []byte("low"), nil
+	case LevelHigh:
+		return []byte("high"), nil
+	}
+	return nil, fmt.Errorf("unknown level: %d", l)
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = LevelLow
+	case "high":
+		*l = LevelHigh
+	default:
+		return fmt.Errorf("unknown level: %s", text)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maru44/stst"
+	"github.com/maru44/stst/tests/data/jsongen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update generated files in tests/data")
+
+const jsonGenPath = "data/jsongen/model_gen.go"
+
+func TestJSONMarshalerGenerate(t *testing.T) {
+	ps, err := loadPackages("github.com/maru44/stst/tests/data/jsongen")
+	require.NoError(t, err)
+	require.Len(t, ps, 1)
+
+	// methods in the generated file are ignored
+	var syntax []*ast.File
+	for _, f := range ps[0].Syntax {
+		if len(f.Comments) > 0 && strings.HasPrefix(f.Comments[0].Text(), "Code generated") {
+			continue
+		}
+		syntax = append(syntax, f)
+	}
+	ps[0].Syntax = syntax
+	schemas := stst.NewParser(ps[0]).Parse()
+
+	var targets []*stst.Schema
+	for _, sc := range schemas {
+		// embedded structs are not targets to compare with encoding/json without their methods
+		if sc.Name == "Order" || sc.Name == "Item" {
+			targets = append(targets, sc)
+		}
+	}
+	require.Len(t, targets, 2)
+
+	got, err := stst.NewJSONMarshalerGenerator(schemas).Generate(stst.GoPackage{Name: "jsongen", ID: ps[0].PkgPath}, targets)
+	require.NoError(t, err)
+	if *update {
+		require.NoError(t, os.WriteFile(jsonGenPath, got, 0o644))
+	}
+	want, err := os.ReadFile(jsonGenPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "run `go test ./tests -run TestJSONMarshalerGenerate -update` to update")
+}
+
+type (
+	// stdOrder and stdItem are encoded by encoding/json because they do not have methods.
+	stdOrder jsongen.Order
+	stdItem  jsongen.Item
+)
+
+func conformanceOrders() map[string]*jsongen.Order {
+	n, limit := 42, uint32(7)
+	return map[string]*jsongen.Order{
+		"zero": {},
+		"full": {
+			Base: jsongen.Base{
+				ID:        1,
+				CreatedAt: time.Date(2023, 1, 2, 3, 4, 5, 6, time.UTC),
+				Name:      "hidden",
+			},
+			Extra:   &jsongen.Extra{Note: "note"},
+			Name:    "<a href=\"x\">&amp;</a> \n\t\x01 日本語",
+			Email:   "a@example.com",
+			Price:   1e21,
+			Rate:    0.1,
+			Count:   -3,
+			Active:  true,
+			Level:   jsongen.LevelHigh,
+			Ptr:     &n,
+			Limit:   &limit,
+			Tags:    jsongen.Tags{"a", "b"},
+			Items:   []*jsongen.Item{{SKU: "x", Qty: 2}, nil},
+			Matrix:  [2][2]int8{{1, -2}, {3, 127}},
+			Labels:  map[string]string{"b": "2", "a": "1", "<": ">"},
+			Scores:  map[int]float64{10: 0.5, 9: 1e-7, -1: 0},
+			Levels:  map[string][]jsongen.Level{"x": {jsongen.LevelLow, jsongen.LevelHigh}, "y": nil},
+			Data:    []byte("hello"),
+			Raw:     jsongen.Raw{},
+			Any:     map[string]any{"k": []any{1, "v", nil}},
+			Timeout: time.Second,
+			Ignored: "ignored",
+			Dash:    "dash",
+			U8:      255,
+		},
+		"empty collections": {
+			Tags:   jsongen.Tags{},
+			Items:  []*jsongen.Item{},
+			Labels: map[string]string{},
+			Scores: map[int]float64{},
+			Data:   []byte{},
+			Price:  -0.000001,
+			Rate:   float32(1e-7),
+		},
+	}
+}
+
+func TestJSONMarshalerConformance_Marshal(t *testing.T) {
+	for name, o := range conformanceOrders() {
+		o := o
+		t.Run(name, func(t *testing.T) {
+			got, err := json.Marshal(o)
+			require.NoError(t, err)
+			want, err := json.Marshal((*stdOrder)(o))
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+
+			// MarshalJSON itself returns compact JSON
+			raw, err := o.MarshalJSON()
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(raw))
+		})
+	}
+
+	item := &jsongen.Item{SKU: "a\"b", Qty: 3}
+	got, err := json.Marshal(item)
+	require.NoError(t, err)
+	want, err := json.Marshal((*stdItem)(item))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestJSONMarshalerConformance_Unmarshal(t *testing.T) {
+	inputs := map[string]string{
+		"empty":                 `{}`,
+		"null":                  `null`,
+		"case-insensitive keys": `{"EMAIL":"e","Id":3,"note":"x","u8":1}`,
+		"escapes":               `{"name":"<😀\ud800x\n\"\\\/","email":"aé"}`,
+		"numbers":               `{"price":-1.5e3,"rate":0.25,"count":"12","U8":255,"limit":"9","timeout":1000}`,
+		"null values":           `{"ptr":null,"tags":null,"labels":null,"items":[null],"data":null,"level":null,"count":null}`,
+		"collections":           `{"tags":["a","b"],"items":[{"sku":"x","qty":1}],"matrix":[[1,2,3],[4]],"labels":{"a":"b"},"scores":{"-1":2,"3":4},"levels":{"x":["low","high"]}}`,
+		"bytes":                 `{"data":"aGVsbG8=","Raw":""}`,
+		"any and meta":          `{"any":{"a":[1,true,null]},"meta":{"source":"s","other":1},"created_at":"2023-01-02T03:04:05Z"}`,
+		"unknown fields":        `{"unknown":{"a":[1,{"b":"c"}]},"-":"dash","Ignored":"x"}`,
+		"spaces":                " { \"name\" : \"n\" , \"tags\" : [ \"a\" ] } ",
+		"duplicated key":        `{"name":"a","name":"b"}`,
+		"bool string":           `{"active":"true"}`,
+		"ptr string":            `{"limit":null}`,
+	}
+	for name, in := range inputs {
+		in := in
+		t.Run(name, func(t *testing.T) {
+			var got jsongen.Order
+			require.NoError(t, json.Unmarshal([]byte(in), &got))
+			var want stdOrder
+			require.NoError(t, json.Unmarshal([]byte(in), &want))
+			assert.Equal(t, jsongen.Order(want), got)
+		})
+	}
+
+	invalid := map[string]string{
+		"syntax":                 `{"name":"a"`,
+		"trailing comma":         `{"name":"a",}`,
+		"type mismatch":          `{"name":1}`,
+		"int overflow":           `{"U8":256}`,
+		"float for int":          `{"count":"1.5"}`,
+		"unquoted string option": `{"count":1}`,
+		"unknown level":          `{"level":"middle"}`,
+		"invalid base64":         `{"data":"!"}`,
+		"array for object":       `[]`,
+	}
+	for name, in := range invalid {
+		in := in
+		t.Run(name, func(t *testing.T) {
+			var got jsongen.Order
+			assert.Error(t, got.UnmarshalJSON([]byte(in)))
+			var want stdOrder
+			assert.Error(t, json.Unmarshal([]byte(in), &want))
+		})
+	}
+}
+
+func TestJSONMarshalerConformance_RoundTrip(t *testing.T) {
+	for name, o := range conformanceOrders() {
+		o := o
+		t.Run(name, func(t *testing.T) {
+			b, err := json.Marshal(o)
+			require.NoError(t, err)
+			var got jsongen.Order
+			require.NoError(t, json.Unmarshal(b, &got))
+			var want stdOrder
+			require.NoError(t, json.Unmarshal(b, &want))
+			assert.Equal(t, jsongen.Order(want), got)
+		})
+	}
+}
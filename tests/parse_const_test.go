@@ -0,0 +1,50 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConsts(t *testing.T) {
+	ps, err := loadPackages("github.com/maru44/stst/tests/data/bbb")
+	require.NoError(t, err)
+	require.Len(t, ps, 1)
+
+	schemas := stst.NewParser(ps[0]).Parse()
+	require.Len(t, schemas, 5)
+
+	assert.Equal(t, []*stst.Const{
+		{
+			Name:  "StatusUnknown",
+			Value: "0",
+		},
+		{
+			Name:  "StatusActive",
+			Value: "1",
+			Doc:   []string{"// active status"},
+		},
+		{
+			Name:    "StatusInactive",
+			Value:   "2",
+			Comment: []string{"// inactive status"},
+		},
+	}, schemas[0].Consts)
+	assert.True(t, schemas[0].IsEnum())
+
+	assert.Equal(t, []*stst.Const{
+		{
+			Name:  "ColorRed",
+			Value: `"red"`,
+		},
+		{
+			Name:  "ColorBlue",
+			Value: `"blue"`,
+		},
+	}, schemas[1].Consts)
+
+	assert.False(t, schemas[2].IsEnum())
+	assert.False(t, schemas[3].IsEnum())
+}
@@ -28,10 +28,12 @@ func TestParseFile(t *testing.T) {
 
 	want := []*stst.Schema{
 		{
-			Name: "withIntf",
+			Name:  "withIntf",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Fields: []*stst.Field{
 				{
-					Name: "error",
+					Name:       "error",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying: "error",
 						TypeName:   "error",
@@ -45,7 +47,8 @@ func TestParseFile(t *testing.T) {
 					},
 				},
 				{
-					Name: "Intf",
+					Name:       "Intf",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data/aaa.Intf",
 						PkgID:       "github.com/maru44/stst/tests/data/aaa",
@@ -54,7 +57,8 @@ func TestParseFile(t *testing.T) {
 					},
 				},
 				{
-					Name: "Good",
+					Name:       "Good",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data.Good",
 						PkgID:       "github.com/maru44/stst/tests/data",
@@ -73,7 +77,8 @@ func TestParseFile(t *testing.T) {
 					},
 				},
 				{
-					Name: "IntSample",
+					Name:       "IntSample",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data/aaa.IntSample",
 						PkgID:       "github.com/maru44/stst/tests/data/aaa",
@@ -82,7 +87,8 @@ func TestParseFile(t *testing.T) {
 					},
 				},
 				{
-					Name: "intf",
+					Name:       "intf",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data.intf",
 						PkgID:       "github.com/maru44/stst/tests/data",
@@ -150,7 +156,8 @@ func TestParseFile(t *testing.T) {
 			},
 		},
 		{
-			Name: "intf",
+			Name:  "intf",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Fields: []*stst.Field{
 				{
 					Name: "AAA",
@@ -205,7 +212,8 @@ func TestParseFile(t *testing.T) {
 					Func: &stst.Func{},
 				},
 				{
-					Name: "Intf",
+					Name:       "Intf",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data/aaa.Intf",
 						PkgID:       "github.com/maru44/stst/tests/data/aaa",
@@ -214,7 +222,8 @@ func TestParseFile(t *testing.T) {
 					},
 				},
 				{
-					Name: "childIntf",
+					Name:       "childIntf",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data.childIntf",
 						PkgID:       "github.com/maru44/stst/tests/data",
@@ -232,7 +241,8 @@ func TestParseFile(t *testing.T) {
 			IsInterface: true,
 		},
 		{
-			Name: "childIntf",
+			Name:  "childIntf",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Fields: []*stst.Field{
 				{
 					Name: "CCC",
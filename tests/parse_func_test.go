@@ -0,0 +1,121 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFunc(t *testing.T) {
+	ps, err := loadPackages("github.com/maru44/stst/tests/data/bbb")
+	require.NoError(t, err)
+	require.Len(t, ps, 1)
+
+	schemas := stst.NewParser(ps[0]).Parse()
+	require.Len(t, schemas, 5)
+
+	repo := schemas[4]
+	want := &stst.Schema{
+		Name:  "Repository",
+		PkgID: "github.com/maru44/stst/tests/data/bbb",
+		Type: &stst.Type{
+			Underlying:  "github.com/maru44/stst/tests/data/bbb.Repository[T any, K comparable]",
+			PkgID:       "github.com/maru44/stst/tests/data/bbb",
+			PkgPlusName: "bbb.Repository[T any, K comparable]",
+			TypeName:    "Repository",
+		},
+		IsInterface: true,
+		TypeParams: []*stst.TypeParam{
+			{
+				Name:       "T",
+				Constraint: "any",
+			},
+			{
+				Name:       "K",
+				Constraint: "comparable",
+			},
+		},
+		Fields: []*stst.Field{
+			{
+				Name: "Get",
+				Func: &stst.Func{
+					Args: []*stst.Field{
+						{
+							Name: "id",
+							Type: &stst.Type{
+								Underlying: "K",
+								TypeName:   "K",
+							},
+						},
+					},
+					Results: []*stst.Field{
+						{
+							Name: "T",
+							Type: &stst.Type{
+								Underlying: "T",
+								TypeName:   "T",
+							},
+						},
+						{
+							Name: "error",
+							Type: &stst.Type{
+								Underlying: "error",
+								TypeName:   "error",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: "Find",
+				Func: &stst.Func{
+					Args: []*stst.Field{
+						{
+							Name: "ids",
+							Type: &stst.Type{
+								Underlying: "K",
+								TypeName:   "K",
+							},
+							IsVariadic:   true,
+							TypePrefixes: []stst.TypePrefix{"[]"},
+						},
+					},
+					Results: []*stst.Field{
+						{
+							Name: "T",
+							Type: &stst.Type{
+								Underlying: "T",
+								TypeName:   "T",
+							},
+							TypePrefixes: []stst.TypePrefix{"[]"},
+						},
+					},
+				},
+			},
+			{
+				Name: "Set",
+				Func: &stst.Func{
+					Args: []*stst.Field{
+						{
+							Name: "key",
+							Type: &stst.Type{
+								Underlying: "string",
+								TypeName:   "string",
+							},
+						},
+						{
+							Name: "value",
+							Type: &stst.Type{
+								Underlying: "string",
+								TypeName:   "string",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, want, repo)
+}
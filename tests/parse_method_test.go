@@ -0,0 +1,52 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMethod(t *testing.T) {
+	ps, err := loadPackages("github.com/maru44/stst/tests/data/bbb")
+	require.NoError(t, err)
+	require.Len(t, ps, 1)
+
+	schemas := stst.NewParser(ps[0]).Parse()
+	require.Len(t, schemas, 5)
+
+	status := schemas[0]
+	require.Equal(t, "Status", status.Name)
+	assert.Equal(t, []*stst.Method{
+		{
+			Name: "IsActive",
+			Func: &stst.Func{
+				Results: []*stst.Field{
+					{
+						Name: "bool",
+						Type: &stst.Type{
+							Underlying: "bool",
+							TypeName:   "bool",
+						},
+					},
+				},
+			},
+		},
+	}, status.Methods)
+
+	item := schemas[3]
+	require.Equal(t, "Item", item.Name)
+	m, ok := item.Method("Equal")
+	require.True(t, ok)
+	assert.True(t, m.IsPointerReceiver)
+	assert.Equal(t, []string{"// Equal returns whether the items are same."}, m.Doc)
+	require.Len(t, m.Func.Args, 1)
+	assert.Equal(t, "other", m.Func.Args[0].Name)
+	assert.Equal(t, []stst.TypePrefix{stst.TypePrefixPtr}, m.Func.Args[0].TypePrefixes)
+	assert.Equal(t, "Item", m.Func.Args[0].Type.TypeName)
+
+	_, ok = item.Method("Hash")
+	assert.False(t, ok)
+	assert.Nil(t, schemas[2].Methods)
+}
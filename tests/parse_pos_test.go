@@ -0,0 +1,33 @@
+package tests_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePositions(t *testing.T) {
+	ps, err := loadPackages("github.com/maru44/stst/tests/data/bbb")
+	require.NoError(t, err)
+	require.Len(t, ps, 1)
+
+	// positions are not recorded by default
+	schemas := stst.NewParser(ps[0]).Parse()
+	assert.False(t, schemas[2].Pos.IsValid())
+	assert.False(t, schemas[2].Fields[0].Pos.IsValid())
+
+	p := stst.NewParser(ps[0])
+	p.Positions = true
+	schemas = p.Parse()
+	order := schemas[2]
+	require.Equal(t, "Order", order.Name)
+	assert.Equal(t, "main.go", filepath.Base(order.Pos.Filename))
+	assert.Equal(t, 8, order.Pos.Line)
+	assert.Equal(t, 2, order.Pos.Column)
+	assert.Equal(t, 11, order.Fields[2].Pos.Line)
+	assert.Equal(t, 3, order.Fields[2].Pos.Column)
+	assert.Equal(t, 15, order.Fields[5].Schema.Fields[0].Pos.Line)
+}
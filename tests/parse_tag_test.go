@@ -0,0 +1,54 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTag(t *testing.T) {
+	ps, err := loadPackages("github.com/maru44/stst/tests/data/bbb")
+	require.NoError(t, err)
+	require.Len(t, ps, 1)
+
+	schemas := stst.NewParser(ps[0]).Parse()
+	require.Len(t, schemas, 5)
+
+	item := schemas[3]
+	require.Equal(t, "Item", item.Name)
+	assert.Equal(t, []*stst.Tag{
+		{
+			Key:      "json",
+			Values:   []string{"name"},
+			RawValue: "name",
+		},
+		{
+			Key:      "validate",
+			Values:   []string{"required", "oneof=a b"},
+			RawValue: "required,oneof=a b",
+		},
+	}, item.Fields[0].Tags)
+	assert.Equal(t, []*stst.Tag{
+		{
+			Key:      "json",
+			Values:   []string{"price", "omitempty"},
+			RawValue: "price,omitempty",
+		},
+		{
+			Key:      "validate",
+			Values:   []string{"gte=0"},
+			RawValue: "gte=0",
+		},
+	}, item.Fields[1].Tags)
+	assert.Equal(t, &stst.ValidateRules{
+		Rules: []*stst.ValidateRule{
+			{Name: "required"},
+			{Name: "oneof", Param: "a b"},
+		},
+	}, item.Fields[0].ValidateRules)
+	assert.Equal(t, &stst.ValidateRules{
+		Rules: []*stst.ValidateRule{{Name: "gte", Param: "0"}},
+	}, item.Fields[1].ValidateRules)
+}
@@ -22,7 +22,8 @@ func TestParse(t *testing.T) {
 
 	want := []*stst.Schema{
 		{
-			Name: "SampleString",
+			Name:  "SampleString",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying: "string",
 				TypeName:   "string",
@@ -30,7 +31,8 @@ func TestParse(t *testing.T) {
 			Comment: []string{"// comment"},
 		},
 		{
-			Name: "MapSimple",
+			Name:  "MapSimple",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.MapSimple",
 				PkgID:       "github.com/maru44/stst/tests/data",
@@ -63,7 +65,8 @@ func TestParse(t *testing.T) {
 			},
 		},
 		{
-			Name: "MapS",
+			Name:  "MapS",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.MapS",
 				PkgID:       "github.com/maru44/stst/tests/data",
@@ -91,7 +94,8 @@ func TestParse(t *testing.T) {
 			},
 		},
 		{
-			Name: "SamplePrefixMap",
+			Name:  "SamplePrefixMap",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.SamplePrefixMap",
 				PkgID:       "github.com/maru44/stst/tests/data",
@@ -120,7 +124,8 @@ func TestParse(t *testing.T) {
 			Comment: []string{"// pref comment"},
 		},
 		{
-			Name: "Person",
+			Name:  "Person",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.Person",
 				PkgID:       "github.com/maru44/stst/tests/data",
@@ -197,7 +202,8 @@ func TestParse(t *testing.T) {
 					},
 				},
 				{
-					Name: "Good",
+					Name:       "Good",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data.Good",
 						PkgID:       "github.com/maru44/stst/tests/data",
@@ -211,7 +217,8 @@ func TestParse(t *testing.T) {
 			},
 		},
 		{
-			Name: "Animal",
+			Name:  "Animal",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.Animal",
 				PkgID:       "github.com/maru44/stst/tests/data",
@@ -386,7 +393,8 @@ func TestParse(t *testing.T) {
 			},
 		},
 		{
-			Name: "Gene",
+			Name:  "Gene",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Fields: []*stst.Field{
 				{
 					Name: "One",
@@ -402,9 +410,16 @@ func TestParse(t *testing.T) {
 				PkgPlusName: "data.Gene[T any]",
 				TypeName:    "Gene",
 			},
+			TypeParams: []*stst.TypeParam{
+				{
+					Name:       "T",
+					Constraint: "any",
+				},
+			},
 		},
 		{
-			Name: "Good",
+			Name:  "Good",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.Good",
 				PkgID:       "github.com/maru44/stst/tests/data",
@@ -437,7 +452,8 @@ func TestParse(t *testing.T) {
 			},
 		},
 		{
-			Name: "withIntf",
+			Name:  "withIntf",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.withIntf",
 				PkgID:       "github.com/maru44/stst/tests/data",
@@ -446,7 +462,8 @@ func TestParse(t *testing.T) {
 			},
 			Fields: []*stst.Field{
 				{
-					Name: "error",
+					Name:       "error",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying: "error",
 						TypeName:   "error",
@@ -460,7 +477,8 @@ func TestParse(t *testing.T) {
 					},
 				},
 				{
-					Name: "Intf",
+					Name:       "Intf",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data/aaa.Intf",
 						PkgID:       "github.com/maru44/stst/tests/data/aaa",
@@ -469,7 +487,8 @@ func TestParse(t *testing.T) {
 					},
 				},
 				{
-					Name: "Good",
+					Name:       "Good",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data.Good",
 						PkgID:       "github.com/maru44/stst/tests/data",
@@ -488,7 +507,8 @@ func TestParse(t *testing.T) {
 					},
 				},
 				{
-					Name: "IntSample",
+					Name:       "IntSample",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data/aaa.IntSample",
 						PkgID:       "github.com/maru44/stst/tests/data/aaa",
@@ -497,7 +517,8 @@ func TestParse(t *testing.T) {
 					},
 				},
 				{
-					Name: "intf",
+					Name:       "intf",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data.intf",
 						PkgID:       "github.com/maru44/stst/tests/data",
@@ -559,7 +580,8 @@ func TestParse(t *testing.T) {
 			},
 		},
 		{
-			Name: "intf",
+			Name:  "intf",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.intf",
 				PkgID:       "github.com/maru44/stst/tests/data",
@@ -620,7 +642,8 @@ func TestParse(t *testing.T) {
 					Func: &stst.Func{},
 				},
 				{
-					Name: "Intf",
+					Name:       "Intf",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data/aaa.Intf",
 						PkgID:       "github.com/maru44/stst/tests/data/aaa",
@@ -629,7 +652,8 @@ func TestParse(t *testing.T) {
 					},
 				},
 				{
-					Name: "childIntf",
+					Name:       "childIntf",
+					IsEmbedded: true,
 					Type: &stst.Type{
 						Underlying:  "github.com/maru44/stst/tests/data.childIntf",
 						TypeName:    "childIntf",
@@ -641,7 +665,8 @@ func TestParse(t *testing.T) {
 			IsInterface: true,
 		},
 		{
-			Name: "childIntf",
+			Name:  "childIntf",
+			PkgID: "github.com/maru44/stst/tests/data",
 			Type: &stst.Type{
 				Underlying:  "github.com/maru44/stst/tests/data.childIntf",
 				PkgID:       "github.com/maru44/stst/tests/data",
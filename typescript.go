@@ -0,0 +1,428 @@
+package stst
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type (
+	// TSGenerator generates TypeScript type definitions (.d.ts) of JSON encoded schemas.
+	TSGenerator struct {
+		idx *Index
+
+		pkgID   string
+		local   map[string]*Schema
+		imports map[string]map[string]string
+		aliases map[string]string
+	}
+)
+
+// NewTSGenerator returns TSGenerator.
+// The schemas are used to resolve named types referenced by fields.
+func NewTSGenerator(schemas []*Schema) *TSGenerator {
+	return &TSGenerator{
+		idx: NewIndex(schemas),
+	}
+}
+
+// Generate returns content of .d.ts file for the schemas defined in the package.
+// Types of other packages are imported from relative path between the package directories,
+// so the file is expected to be put as `<package path>/index.d.ts`.
+func (g *TSGenerator) Generate(pkgID string, schemas []*Schema) (string, error) {
+	g.pkgID = pkgID
+	g.local = make(map[string]*Schema, len(schemas))
+	g.imports = map[string]map[string]string{}
+	g.aliases = map[string]string{}
+	for _, sc := range schemas {
+		g.local[sc.Name] = sc
+	}
+
+	var defs []string
+	for _, sc := range schemas {
+		def, err := g.schema(sc)
+		if err != nil {
+			return "", fmt.Errorf("typescript: %s: %w", sc.Name, err)
+		}
+		if def != "" {
+			defs = append(defs, def)
+		}
+	}
+
+	var b strings.Builder
+	for _, from := range sortedKeys(g.imports) {
+		names := g.imports[from]
+		specs := make([]string, 0, len(names))
+		for _, name := range sortedKeys(names) {
+			if alias := names[name]; alias != name {
+				specs = append(specs, name+" as "+alias)
+				continue
+			}
+			specs = append(specs, name)
+		}
+		fmt.Fprintf(&b, "import type { %s } from \"%s\";\n", strings.Join(specs, ", "), from)
+	}
+	if len(g.imports) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Join(defs, "\n"))
+	return b.String(), nil
+}
+
+func (g *TSGenerator) schema(sc *Schema) (string, error) {
+	switch {
+	case sc.IsInterface:
+		return "", nil
+	case sc.IsEnum():
+		var lits []string
+		for _, c := range sc.Consts {
+			lit, ok := tsLiteral(c.Value)
+			if !ok {
+				return "", fmt.Errorf("unsupported constant: %s", c.Name)
+			}
+			lits = append(lits, lit)
+		}
+		return fmt.Sprintf("export type %s = %s;\n", sc.Name, strings.Join(lits, " | ")), nil
+	case sc.IsStruct() && len(sc.TypePrefixes) == 0:
+		var b strings.Builder
+		fmt.Fprintf(&b, "export interface %s {\n", sc.Name)
+		if err := g.properties(&b, sc.Fields, "  "); err != nil {
+			return "", err
+		}
+		b.WriteString("}\n")
+		return b.String(), nil
+	}
+
+	var base string
+	var err error
+	switch {
+	case sc.IsMap():
+		base, err = g.mapType(sc.Map, "")
+		base = tsOrNull(base)
+	case sc.IsStruct():
+		var b strings.Builder
+		b.WriteString("{\n")
+		err = g.properties(&b, sc.Fields, "  ")
+		b.WriteString("}")
+		base = b.String()
+	case sc.Type != nil:
+		base, err = g.typeName(sc.Type)
+	default:
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("export type %s = %s;\n", sc.Name, tsWrapPrefixes(base, sc.TypePrefixes)), nil
+}
+
+// properties writes fields as JSON encoded properties.
+// Like encoding/json, fields of embedded structs are promoted
+// unless the outer struct has fields with the same name.
+func (g *TSGenerator) properties(b *strings.Builder, fields []*Field, indent string) error {
+	type prop struct {
+		name     string
+		typ      string
+		optional bool
+	}
+	var props []*prop
+	seen := map[string]bool{}
+
+	var walk func(fields []*Field, depth int) error
+	walk = func(fields []*Field, depth int) error {
+		var embedded []*Schema
+		for _, f := range fields {
+			var tag *Tag
+			if t, ok := f.Tag(jsonTagKey); ok {
+				tag = t
+			}
+			if tag != nil && tag.Name() == "-" && len(tag.Values) == 1 {
+				continue
+			}
+			name := f.Name
+			if tag != nil && tag.Name() != "" {
+				name = tag.Name()
+			} else if f.IsEmbedded {
+				if emb, ok := g.idx.Lookup(f.Type); ok && emb.IsStruct() {
+					embedded = append(embedded, emb)
+					continue
+				}
+			}
+			if !f.IsExported() || f.IsFunc() || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			typ, err := g.fieldType(f, indent)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if tag != nil && tag.HasOption("string") && f.Type != nil && f.Type.IsBasic() && len(f.TypePrefixes) == 0 {
+				typ = "string"
+			}
+			p := &prop{name: name, typ: typ}
+			isPtr := len(f.TypePrefixes) > 0 && f.TypePrefixes[0] == TypePrefixPtr
+			omitempty := tag != nil && tag.HasOption("omitempty")
+			if omitempty || isPtr {
+				p.optional = true
+			}
+			if omitempty && !isPtr {
+				// nil slice and map are omitted instead of encoded as null
+				p.typ = strings.TrimSuffix(p.typ, " | null")
+			}
+			if isPtr && !omitempty {
+				// nil pointer is encoded as null
+				p.typ = tsOrNull(p.typ)
+			}
+			props = append(props, p)
+		}
+		for _, emb := range embedded {
+			if depth > 10 {
+				return fmt.Errorf("too deep embedding: %s", emb.Name)
+			}
+			if err := walk(emb.Fields, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(fields, 0); err != nil {
+		return err
+	}
+
+	for _, p := range props {
+		name := p.name
+		if !isTSIdentifier(name) {
+			name = strconv.Quote(name)
+		}
+		if p.optional {
+			name += "?"
+		}
+		fmt.Fprintf(b, "%s%s: %s;\n", indent, name, p.typ)
+	}
+	return nil
+}
+
+func (g *TSGenerator) fieldType(f *Field, indent string) (string, error) {
+	prefixes := f.TypePrefixes
+	var base string
+	switch {
+	case f.IsMap():
+		var err error
+		base, err = g.mapType(f.Map, indent)
+		if err != nil {
+			return "", err
+		}
+		// nil map is encoded as null
+		base = tsOrNull(base)
+	case f.IsUntitledStruct:
+		var b strings.Builder
+		b.WriteString("{\n")
+		var fields []*Field
+		if f.Schema != nil {
+			fields = f.Schema.Fields
+		}
+		if err := g.properties(&b, fields, indent+"  "); err != nil {
+			return "", err
+		}
+		b.WriteString(indent + "}")
+		base = b.String()
+	case f.IsUntitledInterface:
+		base = "unknown"
+	case f.Type != nil:
+		if n := len(prefixes); n > 0 && prefixes[n-1] == TypePrefixSlice && isByte(f.Type) {
+			// []byte is encoded as base64 string, and nil one as null
+			base = "string | null"
+			prefixes = prefixes[:n-1]
+			break
+		}
+		var err error
+		base, err = g.typeName(f.Type)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported type")
+	}
+	// pointer at the top level is handled as optional property
+	if len(prefixes) > 0 && prefixes[0] == TypePrefixPtr {
+		prefixes = prefixes[1:]
+	}
+	return tsWrapPrefixes(base, prefixes), nil
+}
+
+func (g *TSGenerator) mapType(m *Map, indent string) (string, error) {
+	if m == nil || m.Key == nil || m.Value == nil {
+		return "Record<string, unknown>", nil
+	}
+	key := "string"
+	if m.Key.Type != nil && len(m.Key.TypePrefixes) == 0 {
+		k, err := g.typeName(m.Key.Type)
+		if err != nil {
+			return "", err
+		}
+		if k == "number" || k == "string" {
+			key = k
+		}
+	}
+	value, err := g.fieldType(m.Value, indent)
+	if err != nil {
+		return "", err
+	}
+	if len(m.Value.TypePrefixes) > 0 && m.Value.TypePrefixes[0] == TypePrefixPtr {
+		value = tsOrNull(value)
+	}
+	return fmt.Sprintf("Record<%s, %s>", key, value), nil
+}
+
+func (g *TSGenerator) typeName(t *Type) (string, error) {
+	if t.Underlying == "time.Time" {
+		return "string", nil
+	}
+	if t.IsBasic() {
+		if t.TypeName != string(t.Underlying) {
+			// named basic type in the same package like `type Status int`
+			if _, ok := g.local[t.TypeName]; ok {
+				return t.TypeName, nil
+			}
+		}
+		return tsBasicType(t.Underlying), nil
+	}
+	if t.PkgID == g.pkgID {
+		if _, ok := g.local[t.TypeName]; ok {
+			return t.TypeName, nil
+		}
+	}
+	sc, ok := g.idx.Lookup(t)
+	if !ok || sc.IsInterface {
+		return "unknown", nil
+	}
+	if t.PkgID == g.pkgID {
+		return sc.Name, nil
+	}
+	return g.importType(t.PkgID, sc.Name), nil
+}
+
+// importType records import of the type and returns the name used in the file.
+func (g *TSGenerator) importType(pkgID, name string) string {
+	from, err := relativeImport(g.pkgID, pkgID)
+	if err != nil {
+		from = pkgID
+	}
+	if g.imports[from] == nil {
+		g.imports[from] = map[string]string{}
+	}
+	if alias, ok := g.imports[from][name]; ok {
+		return alias
+	}
+
+	alias := name
+	key := from + "." + name
+	if _, ok := g.local[name]; ok || g.aliasUsed(alias, key) {
+		alias = path.Base(pkgID) + "_" + name
+	}
+	g.aliases[alias] = key
+	g.imports[from][name] = alias
+	return alias
+}
+
+func (g *TSGenerator) aliasUsed(alias, key string) bool {
+	k, ok := g.aliases[alias]
+	return ok && k != key
+}
+
+// relativeImport returns path to import `<to>/index.d.ts` from `<from>/index.d.ts`.
+func relativeImport(from, to string) (string, error) {
+	fromParts := strings.Split(from, "/")
+	toParts := strings.Split(to, "/")
+	i := 0
+	for i < len(fromParts) && i < len(toParts) && fromParts[i] == toParts[i] {
+		i++
+	}
+	if i == 0 {
+		return "", fmt.Errorf("no common path: %s, %s", from, to)
+	}
+	var rel []string
+	for j := i; j < len(fromParts); j++ {
+		rel = append(rel, "..")
+	}
+	rel = append(rel, toParts[i:]...)
+	out := strings.Join(rel, "/")
+	if !strings.HasPrefix(out, "..") {
+		out = "./" + out
+	}
+	return out, nil
+}
+
+// tsWrapPrefixes wraps base with prefixes.
+// Pointers and slices are nullable like nil ones encoded as null, but arrays are not.
+func tsWrapPrefixes(base string, prefixes []TypePrefix) string {
+	out := base
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		switch kind := prefixes[i].Kind(); kind {
+		case TypePrefixKindPtr:
+			out = tsOrNull(out)
+		case TypePrefixKindSlice, TypePrefixKindArray:
+			if strings.ContainsAny(out, " |") {
+				out = "(" + out + ")"
+			}
+			out += "[]"
+			if kind == TypePrefixKindSlice {
+				out = tsOrNull(out)
+			}
+		}
+	}
+	return out
+}
+
+func tsOrNull(typ string) string {
+	if strings.HasSuffix(typ, " | null") {
+		return typ
+	}
+	return typ + " | null"
+}
+
+func tsBasicType(u UnderlyingType) string {
+	switch u {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune",
+		"float32", "float64":
+		return "number"
+	}
+	return "unknown"
+}
+
+// tsLiteral converts exact value of Go constant to TypeScript literal.
+func tsLiteral(v string) (string, bool) {
+	if s, err := strconv.Unquote(v); err == nil {
+		return strconv.Quote(s), true
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v, true
+	}
+	if v == "true" || v == "false" {
+		return v, true
+	}
+	return "", false
+}
+
+func isTSIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			continue
+		}
+		if i > 0 && r >= '0' && r <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package stst_test
+
+import (
+	"path"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tsSrc = `package data
+
+import (
+	"time"
+
+	"github.com/maru44/stst/tests/data/aaa"
+)
+
+type SampleString string
+
+type Good struct {
+	Name      string        ` + "`json:\"name\" db:\"name\"`" + `
+	SamplePtr *SampleString ` + "`json:\"sample_ptr,omitempty\"`" + `
+}
+
+type Person struct {
+	Name  string ` + "`json:\"name\"`" + ` // comment
+	Age   int    ` + "`json:\"age,omitempty\"`" + `
+	Sex   string ` + "`json:\"-\"`" + `
+	Hobby string
+	Good
+}
+
+type Animal struct {
+	ID      string         ` + "`json:\"id\"`" + `
+	Goods   []*Good        ` + "`json:\"goods\"`" + `
+	GoodPtr *Good          ` + "`json:\"good_ptr,omitempty\"`" + `
+	Born    time.Time      ` + "`json:\"born\"`" + `
+	Attrs   map[string]int ` + "`json:\"attrs\"`" + `
+	Meta    struct {
+		Note string ` + "`json:\"note\"`" + `
+	} ` + "`json:\"meta\"`" + `
+	strs []string
+	Fn   func(v any)
+}
+
+type Status string
+
+const (
+	StatusActive   Status = "active"
+	StatusInactive Status = "inactive"
+)
+
+type Shop struct {
+	Status    Status                ` + "`json:\"status\"`" + `
+	Samples   []aaa.Sample          ` + "`json:\"samples\"`" + `
+	Count     int64                 ` + "`json:\"count,string\"`" + `
+	OwnerName *string               ` + "`json:\"owner-name\"`" + `
+	Tags      []string              ` + "`json:\"tags,omitempty\"`" + `
+	Grid      [][2]int              ` + "`json:\"grid\"`" + `
+	Raw       []byte                ` + "`json:\"raw\"`" + `
+	Nested    *[][]string           ` + "`json:\"nested,omitempty\"`" + `
+	Labels    []map[string][]string ` + "`json:\"labels\"`" + `
+}
+`
+
+const tsAaaSrc = `package aaa
+
+type Sample struct {
+	Str string
+}
+`
+
+func TestTSGenerate(t *testing.T) {
+	aaa := loadSource(t, testPkg+"/aaa", tsAaaSrc)
+	schemas := append(stst.NewParser(aaa).Parse(), stst.NewParser(loadSource(t, testPkg, tsSrc, aaa)).Parse()...)
+
+	g := stst.NewTSGenerator(schemas)
+	var targets []*stst.Schema
+	for _, name := range []string{"Good", "Person", "Animal", "Status", "Shop"} {
+		targets = append(targets, findSchema(schemas, name))
+	}
+	got, err := g.Generate(testPkg, targets)
+	require.NoError(t, err)
+
+	want := `import type { Sample } from "./aaa";
+
+export interface Good {
+  name: string;
+  sample_ptr?: string;
+}
+
+export interface Person {
+  name: string;
+  age?: number;
+  Hobby: string;
+  sample_ptr?: string;
+}
+
+export interface Animal {
+  id: string;
+  goods: (Good | null)[] | null;
+  good_ptr?: Good;
+  born: string;
+  attrs: Record<string, number> | null;
+  meta: {
+    note: string;
+  };
+}
+
+export type Status = "active" | "inactive";
+
+export interface Shop {
+  status: Status;
+  samples: Sample[] | null;
+  count: string;
+  "owner-name"?: string | null;
+  tags?: string[];
+  grid: number[][] | null;
+  raw: string | null;
+  nested?: (string[] | null)[] | null;
+  labels: (Record<string, string[] | null> | null)[] | null;
+}
+`
+	assert.Equal(t, want, got)
+}
+
+func TestTSGenerate_Import(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{name: "ok: sibling", from: "example.com/x/a", to: "example.com/x/b", want: "../b"},
+		{name: "ok: child", from: "example.com/x", to: "example.com/x/a", want: "./a"},
+		{name: "ok: ancestor", from: "example.com/x/a/b", to: "example.com/x", want: "../.."},
+		{name: "ok: cousin", from: "example.com/x/a/b", to: "example.com/x/c/d", want: "../../c/d"},
+		{name: "ok: no common path", from: "example.com/x", to: "example.org/y", want: "example.org/y"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dep := loadSource(t, tt.to, "package "+path.Base(tt.to)+"\n\ntype Item struct {\n\tName string\n}\n")
+			src := "package " + path.Base(tt.from) + "\n\nimport \"" + tt.to + "\"\n\ntype Box struct {\n\tItem " + path.Base(tt.to) + ".Item\n}\n"
+			schemas := append(stst.NewParser(dep).Parse(), stst.NewParser(loadSource(t, tt.from, src, dep)).Parse()...)
+
+			got, err := stst.NewTSGenerator(schemas).Generate(tt.from, []*stst.Schema{findSchema(schemas, "Box")})
+			require.NoError(t, err)
+			assert.Equal(t, "import type { Item } from \""+tt.want+"\";\n\nexport interface Box {\n  Item: Item;\n}\n", got)
+		})
+	}
+}
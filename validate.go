@@ -0,0 +1,675 @@
+package stst
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// ValidateGenerator generates reflection-free `Validate() error` methods of structs from `validate` tags.
+	//
+	// Rules of go-playground/validator below are supported.
+	//   - required, omitempty, -
+	//   - len, min, max, eq, ne, gt, gte, lt, lte (length for strings, slices and maps)
+	//   - oneof
+	//   - email, url, uri, uuid, alpha, alphanum, numeric, hostname, ip, ipv4, ipv6
+	//   - dive, keys, endkeys
+	//
+	// `required` of pointers, slices and maps means not nil, and rules other than `required` and `omitempty`
+	// of pointers are applied to the pointed value if it is not nil.
+	// Nested structs are validated by their `Validate` methods, and `required` of struct values is always satisfied.
+	// Generate returns error for unsupported rules and rules which do not fit the type (like `min` on bool).
+	ValidateGenerator struct {
+		idx *Index
+	}
+
+	validateCtx struct {
+		p          *goTypePrinter
+		typeParams map[string]bool
+		// targets are structs whose Validate are generated
+		targets map[*Schema]bool
+		// regexps are names of used regexps
+		regexps map[string]bool
+	}
+
+	// validatePath is path of the value in error message like `Tags[%d]` with args like `i0`.
+	validatePath struct {
+		format string
+		args   []string
+	}
+)
+
+// patterns of rules which are validated by regexp
+var validateRegexps = map[string]string{
+	"uuid":     `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`,
+	"alpha":    `^[a-zA-Z]+$`,
+	"alphanum": `^[a-zA-Z0-9]+$`,
+	"numeric":  `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"hostname": `^[a-zA-Z]([a-zA-Z0-9\-]+[\.]?)*[a-zA-Z0-9]$`,
+}
+
+// operators of rules comparing size or value
+var validateOperators = map[string]string{
+	"len": "==",
+	"min": ">=",
+	"max": "<=",
+	"eq":  "==",
+	"ne":  "!=",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+// ParseValidateTag parses value of `validate` tag like `required,min=1,dive,keys,max=10,endkeys,email`.
+// `0x2C` and `0x7C` in params are unescaped to `,` and `|`.
+func ParseValidateTag(tag string) (*ValidateRules, error) {
+	if tag == "" {
+		return &ValidateRules{}, nil
+	}
+	return parseValidateTokens(strings.Split(tag, ","))
+}
+
+func parseValidateTokens(tokens []string) (*ValidateRules, error) {
+	out := &ValidateRules{}
+	for i, tok := range tokens {
+		switch tok {
+		case "":
+			return nil, fmt.Errorf("empty rule")
+		case "dive":
+			rest := tokens[i+1:]
+			if len(rest) > 0 && rest[0] == "keys" {
+				end := -1
+				for j, t := range rest {
+					if t == "endkeys" {
+						end = j
+						break
+					}
+				}
+				if end < 0 {
+					return nil, fmt.Errorf("keys without endkeys")
+				}
+				keys, err := parseValidateTokens(rest[1:end])
+				if err != nil {
+					return nil, err
+				}
+				out.Keys = keys
+				rest = rest[end+1:]
+			}
+			dive, err := parseValidateTokens(rest)
+			if err != nil {
+				return nil, err
+			}
+			out.Dive = dive
+			return out, nil
+		case "keys":
+			return nil, fmt.Errorf("keys must follow dive")
+		case "endkeys":
+			return nil, fmt.Errorf("endkeys without keys")
+		}
+
+		var rule *ValidateRule
+		for _, alt := range strings.Split(tok, "|") {
+			name, param, _ := strings.Cut(alt, "=")
+			if name == "" {
+				return nil, fmt.Errorf("empty rule in %s", tok)
+			}
+			r := &ValidateRule{
+				Name:  name,
+				Param: strings.NewReplacer("0x2C", ",", "0x7C", "|").Replace(param),
+			}
+			if rule == nil {
+				rule = r
+				continue
+			}
+			rule.Or = append(rule.Or, r)
+		}
+		out.Rules = append(out.Rules, rule)
+	}
+	return out, nil
+}
+
+// has returns whether the rules have the rule (without alternatives) of the name.
+func (r *ValidateRules) has(name string) bool {
+	if r == nil {
+		return false
+	}
+	for _, rule := range r.Rules {
+		if rule.Name == name && len(rule.Or) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns the rules without the rules of the names.
+func (r *ValidateRules) without(names ...string) *ValidateRules {
+	if r == nil {
+		return nil
+	}
+	out := *r
+	out.Rules = nil
+	for _, rule := range r.Rules {
+		skip := false
+		for _, n := range names {
+			if rule.Name == n && len(rule.Or) == 0 {
+				skip = true
+			}
+		}
+		if !skip {
+			out.Rules = append(out.Rules, rule)
+		}
+	}
+	return &out
+}
+
+// String returns the rule as written in tag like `min=1` or `rgb|rgba`.
+func (r *ValidateRule) String() string {
+	s := r.Name
+	if r.Param != "" {
+		s += "=" + r.Param
+	}
+	for _, o := range r.Or {
+		s += "|" + o.String()
+	}
+	return s
+}
+
+// NewValidateGenerator returns ValidateGenerator.
+// The schemas are used to resolve named types of fields and their methods.
+func NewValidateGenerator(schemas []*Schema) *ValidateGenerator {
+	return &ValidateGenerator{
+		idx: NewIndex(schemas),
+	}
+}
+
+// Generate returns Go source of Validate for struct schemas.
+// The pkg should be the package of the schemas because Validate is method.
+// Structs which already have Validate are skipped.
+//
+// Regexps used by the rules are declared as package variables (like `validateUUIDRegexp`),
+// so generate Validate of a package into one file.
+func (g *ValidateGenerator) Generate(pkg GoPackage, schemas []*Schema) ([]byte, error) {
+	ctx := &validateCtx{
+		p:       newGoTypePrinter(pkg),
+		targets: map[*Schema]bool{},
+		regexps: map[string]bool{},
+	}
+	for _, sc := range schemas {
+		if sc.IsStruct() && len(sc.TypePrefixes) == 0 {
+			if _, ok := sc.Method("Validate"); !ok {
+				ctx.targets[sc] = true
+			}
+		}
+	}
+
+	var body strings.Builder
+	for _, sc := range schemas {
+		if !ctx.targets[sc] {
+			continue
+		}
+		if err := g.validate(&body, ctx, sc); err != nil {
+			return nil, fmt.Errorf("validate: %s: %w", sc.Name, err)
+		}
+	}
+
+	var b strings.Builder
+	if len(ctx.regexps) > 0 {
+		b.WriteString("var (\n")
+		for _, name := range sortedKeys(ctx.regexps) {
+			fmt.Fprintf(&b, "%s = %sMustCompile(`%s`)\n", validateRegexpName(name), ctx.p.qualify("regexp"), validateRegexps[name])
+		}
+		b.WriteString(")\n\n")
+	}
+	b.WriteString(body.String())
+
+	out, err := ctx.p.file(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	return out, nil
+}
+
+func (g *ValidateGenerator) validate(b *strings.Builder, ctx *validateCtx, sc *Schema) error {
+	_, args := typeParamsDecl(sc.TypeParams)
+	ctx.typeParams = typeParamNames(sc.TypeParams)
+
+	fmt.Fprintf(b, "// Validate validates the %s by `validate` tags.\n", sc.Name)
+	fmt.Fprintf(b, "func (x *%s%s) Validate() error {\nif x == nil {\nreturn nil\n}\n", sc.Name, args)
+	if err := g.fields(b, ctx, sc.Fields, "x.", ""); err != nil {
+		return err
+	}
+	b.WriteString("return nil\n}\n\n")
+	return nil
+}
+
+// fields writes validations of fields of struct.
+// The x is prefix of the values like `x.` and the path is prefix in error messages like `Meta.`.
+func (g *ValidateGenerator) fields(b *strings.Builder, ctx *validateCtx, fields []*Field, x, path string) error {
+	for _, f := range fields {
+		rules := f.ValidateRules
+		if tag, ok := f.Tag(validateTagKey); ok && rules == nil {
+			var err error
+			rules, err = ParseValidateTag(tag.RawValue)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+		if rules.has("-") {
+			continue
+		}
+		p := validatePath{format: path + f.Name}
+		if err := g.value(b, ctx, f, f.TypePrefixes, rules, x+f.Name, p, 0); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// value writes validations of the value x which is the Field with the prefixes.
+func (g *ValidateGenerator) value(b *strings.Builder, ctx *validateCtx, f *Field, prefixes []TypePrefix, rules *ValidateRules, x string, path validatePath, depth int) error {
+	if len(prefixes) > 0 && prefixes[0].Kind() == TypePrefixKindPtr {
+		if rules.has("required") {
+			fmt.Fprintf(b, "if %s == nil {\nreturn %s\n}\n", x, path.err(ctx, "required"))
+		}
+		var inner strings.Builder
+		if err := g.value(&inner, ctx, f, prefixes[1:], rules.without("required", "omitempty"), "(*"+x+")", path, depth); err != nil {
+			return err
+		}
+		if inner.Len() > 0 {
+			fmt.Fprintf(b, "if %s != nil {\n%s}\n", x, inner.String())
+		}
+		return nil
+	}
+
+	f, prefixes = g.resolve(ctx, f, prefixes)
+	kind, sc := g.kind(ctx, f, prefixes)
+
+	var checks strings.Builder
+	if rules != nil {
+		for _, rule := range rules.Rules {
+			switch rule.Name {
+			case "omitempty":
+				continue
+			case "required":
+				if len(rule.Or) > 0 {
+					return fmt.Errorf("%s can not have alternatives", rule.Name)
+				}
+				if kind == "struct" {
+					// struct values always pass required as go-playground/validator does
+					continue
+				}
+				zero, err := validateZero(kind, x)
+				if err != nil {
+					return fmt.Errorf("required: %w", err)
+				}
+				fmt.Fprintf(&checks, "if %s {\nreturn %s\n}\n", zero, path.err(ctx, rule.String()))
+				continue
+			}
+
+			var conds []string
+			for _, r := range append([]*ValidateRule{rule}, rule.Or...) {
+				cond, err := g.cond(ctx, kind, r, x)
+				if err != nil {
+					return fmt.Errorf("%s: %w", r.Name, err)
+				}
+				conds = append(conds, cond)
+			}
+			fmt.Fprintf(&checks, "if !(%s) {\nreturn %s\n}\n", strings.Join(conds, " || "), path.err(ctx, rule.String()))
+		}
+
+		if rules.Keys != nil || rules.Dive != nil {
+			if err := g.dive(&checks, ctx, kind, f, prefixes, rules, x, path, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	// nested struct
+	switch {
+	case kind == "struct" && f.IsUntitledStruct && f.Schema != nil:
+		if err := g.fields(&checks, ctx, f.Schema.Fields, x+".", path.format+"."); err != nil {
+			return err
+		}
+	case kind == "struct" && sc != nil && g.hasValidate(ctx, sc):
+		args := append(append([]string{}, path.args...), "err")
+		fmt.Fprintf(&checks, "if err := %s.Validate(); err != nil {\nreturn %sErrorf(%q, %s)\n}\n",
+			derefTrimmed(x), ctx.p.qualify("fmt"), path.format+": %w", strings.Join(args, ", "))
+	}
+
+	if checks.Len() == 0 {
+		return nil
+	}
+	if rules.has("omitempty") {
+		zero, err := validateZero(kind, x)
+		if err != nil {
+			return fmt.Errorf("omitempty: %w", err)
+		}
+		fmt.Fprintf(b, "if !(%s) {\n%s}\n", zero, checks.String())
+		return nil
+	}
+	b.WriteString(checks.String())
+	return nil
+}
+
+func (g *ValidateGenerator) dive(b *strings.Builder, ctx *validateCtx, kind string, f *Field, prefixes []TypePrefix, rules *ValidateRules, x string, path validatePath, depth int) error {
+	switch kind {
+	case "slice", "array":
+		if rules.Keys != nil {
+			return fmt.Errorf("keys is only for map")
+		}
+		i := fmt.Sprintf("i%d", depth)
+		var inner strings.Builder
+		elem := validatePath{format: path.format + "[%d]", args: append(append([]string{}, path.args...), i)}
+		if err := g.value(&inner, ctx, f, prefixes[1:], rules.Dive, x+"["+i+"]", elem, depth+1); err != nil {
+			return err
+		}
+		if inner.Len() > 0 {
+			fmt.Fprintf(b, "for %s := range %s {\n%s}\n", i, x, inner.String())
+		}
+	case "map":
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		elem := validatePath{format: path.format + "[%v]", args: append(append([]string{}, path.args...), k)}
+		var keys, values strings.Builder
+		if rules.Keys != nil {
+			if err := g.value(&keys, ctx, f.Map.Key, f.Map.Key.TypePrefixes, rules.Keys, k, elem, depth+1); err != nil {
+				return fmt.Errorf("keys: %w", err)
+			}
+		}
+		if err := g.value(&values, ctx, f.Map.Value, f.Map.Value.TypePrefixes, rules.Dive, v, elem, depth+1); err != nil {
+			return err
+		}
+		if keys.Len() == 0 && values.Len() == 0 {
+			return nil
+		}
+		if values.Len() == 0 {
+			v = "_"
+		}
+		if keys.Len() == 0 && !strings.Contains(values.String(), k) {
+			k = "_"
+		}
+		fmt.Fprintf(b, "for %s, %s := range %s {\n%s%s}\n", k, v, x, keys.String(), values.String())
+	default:
+		return fmt.Errorf("dive is only for slice, array and map")
+	}
+	return nil
+}
+
+// cond returns condition which is true if the value x satisfies the rule.
+func (g *ValidateGenerator) cond(ctx *validateCtx, kind string, r *ValidateRule, x string) (string, error) {
+	if op, ok := validateOperators[r.Name]; ok {
+		switch kind {
+		case "string":
+			if r.Name == "eq" || r.Name == "ne" {
+				return fmt.Sprintf("%s %s %s", x, op, strconv.Quote(r.Param)), nil
+			}
+			n, err := strconv.Atoi(r.Param)
+			if err != nil {
+				return "", fmt.Errorf("invalid param: %s", r.Param)
+			}
+			return fmt.Sprintf("%sRuneCountInString(%s) %s %d", ctx.p.qualify("unicode/utf8"), x, op, n), nil
+		case "slice", "array", "map":
+			n, err := strconv.Atoi(r.Param)
+			if err != nil {
+				return "", fmt.Errorf("invalid param: %s", r.Param)
+			}
+			return fmt.Sprintf("len(%s) %s %d", x, op, n), nil
+		case "int", "uint", "float":
+			n, err := validateNumber(kind, r.Param)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s %s %s", x, op, n), nil
+		case "duration":
+			d, err := time.ParseDuration(r.Param)
+			if err != nil {
+				return "", fmt.Errorf("invalid param: %s", r.Param)
+			}
+			return fmt.Sprintf("%s %s %sDuration(%d)", x, op, ctx.p.qualify("time"), int64(d)), nil
+		}
+		return "", fmt.Errorf("not supported for %s", kind)
+	}
+
+	switch r.Name {
+	case "oneof":
+		vals := splitOneOf(r.Param)
+		if len(vals) == 0 {
+			return "", fmt.Errorf("no values")
+		}
+		conds := make([]string, len(vals))
+		for i, v := range vals {
+			switch kind {
+			case "string":
+				conds[i] = fmt.Sprintf("%s == %s", x, strconv.Quote(v))
+			case "int", "uint":
+				n, err := validateNumber(kind, v)
+				if err != nil {
+					return "", err
+				}
+				conds[i] = fmt.Sprintf("%s == %s", x, n)
+			default:
+				return "", fmt.Errorf("not supported for %s", kind)
+			}
+		}
+		return strings.Join(conds, " || "), nil
+	}
+
+	if kind != "string" {
+		if _, ok := validateRegexps[r.Name]; ok {
+			return "", fmt.Errorf("not supported for %s", kind)
+		}
+	}
+	switch r.Name {
+	case "email":
+		if kind == "string" {
+			return fmt.Sprintf("func() bool {\na, err := %sParseAddress(%s)\nreturn err == nil && a.Address == %s\n}()", ctx.p.qualify("net/mail"), x, x), nil
+		}
+	case "url":
+		if kind == "string" {
+			return fmt.Sprintf("func() bool {\nu, err := %sParse(%s)\nreturn err == nil && u.Scheme != \"\"\n}()", ctx.p.qualify("net/url"), x), nil
+		}
+	case "uri":
+		if kind == "string" {
+			return fmt.Sprintf("func() bool {\n_, err := %sParseRequestURI(%s)\nreturn err == nil\n}()", ctx.p.qualify("net/url"), x), nil
+		}
+	case "ip":
+		if kind == "string" {
+			return fmt.Sprintf("%sParseIP(%s) != nil", ctx.p.qualify("net"), x), nil
+		}
+	case "ipv4", "ipv6":
+		if kind == "string" {
+			op := "!="
+			if r.Name == "ipv6" {
+				op = "=="
+			}
+			return fmt.Sprintf("func() bool {\nip := %sParseIP(%s)\nreturn ip != nil && ip.To4() %s nil\n}()", ctx.p.qualify("net"), x, op), nil
+		}
+	default:
+		if _, ok := validateRegexps[r.Name]; ok {
+			ctx.regexps[r.Name] = true
+			return fmt.Sprintf("%s.MatchString(%s)", validateRegexpName(r.Name), x), nil
+		}
+		return "", fmt.Errorf("unsupported rule")
+	}
+	return "", fmt.Errorf("not supported for %s", kind)
+}
+
+// resolve returns definition of named slice or map like `type Goods []*Good`.
+func (g *ValidateGenerator) resolve(ctx *validateCtx, f *Field, prefixes []TypePrefix) (*Field, []TypePrefix) {
+	for i := 0; i < 10 && len(prefixes) == 0; i++ {
+		sc := g.schema(ctx, f)
+		if sc == nil || (sc.Map == nil && len(sc.TypePrefixes) == 0) || sc.TypePrefixes[0].Kind() == TypePrefixKindPtr {
+			break
+		}
+		f = &Field{Type: sc.Type, Map: sc.Map}
+		prefixes = sc.TypePrefixes
+	}
+	return f, prefixes
+}
+
+// kind returns kind of the value which is the Field with the prefixes not starting with pointer.
+func (g *ValidateGenerator) kind(ctx *validateCtx, f *Field, prefixes []TypePrefix) (string, *Schema) {
+	if len(prefixes) > 0 {
+		switch prefixes[0].Kind() {
+		case TypePrefixKindSlice:
+			return "slice", nil
+		case TypePrefixKindArray:
+			return "array", nil
+		}
+		return "other", nil
+	}
+	switch {
+	case f.IsMap():
+		return "map", nil
+	case f.IsFunc():
+		return "func", nil
+	case f.IsUntitledInterface:
+		return "iface", nil
+	case f.IsUntitledStruct:
+		return "struct", nil
+	case f.Type == nil:
+		return "other", nil
+	}
+
+	t := f.Type
+	switch t.Underlying {
+	case "time.Duration":
+		return "duration", nil
+	case "time.Time":
+		return "time", nil
+	}
+	if ctx.typeParams[t.TypeName] {
+		return "other", nil
+	}
+	if sc := g.schema(ctx, f); sc != nil {
+		switch {
+		case sc.IsInterface:
+			return "iface", sc
+		case sc.IsStruct() && len(sc.TypePrefixes) == 0:
+			return "struct", sc
+		case sc.Type != nil && sc.Map == nil && len(sc.TypePrefixes) == 0 && sc.Type.IsBasic():
+			return validateBasicKind(sc.Type.Underlying), sc
+		}
+		return "other", sc
+	}
+	if t.IsBasic() {
+		return validateBasicKind(t.Underlying), nil
+	}
+	return "other", nil
+}
+
+// schema returns the Schema of named type of the Field.
+func (g *ValidateGenerator) schema(ctx *validateCtx, f *Field) *Schema {
+	t := f.Type
+	if t == nil || f.IsMap() || f.IsFunc() || ctx.typeParams[t.TypeName] {
+		return nil
+	}
+	if sc, ok := g.idx.Lookup(t); ok {
+		return sc
+	}
+	if t.PkgID != "" || t.TypeName == string(t.Underlying) {
+		return nil
+	}
+	// named type in the same package like `type Status int`
+	for _, sc := range g.idx.Schemas() {
+		if sc.Name == t.TypeName && !isSelfType(sc) {
+			return sc
+		}
+	}
+	return nil
+}
+
+// hasValidate returns whether the Schema has `Validate() error` (including generated one).
+func (g *ValidateGenerator) hasValidate(ctx *validateCtx, sc *Schema) bool {
+	if ctx.targets[sc] {
+		return true
+	}
+	m, ok := sc.Method("Validate")
+	if !ok || m.Func == nil || len(m.Func.Args) != 0 || len(m.Func.Results) != 1 {
+		return false
+	}
+	res := m.Func.Results[0]
+	return res.Type != nil && res.Type.Underlying == "error" && len(res.TypePrefixes) == 0
+}
+
+// err returns expression of error for the rule.
+func (p validatePath) err(ctx *validateCtx, rule string) string {
+	args := append(append([]string{}, p.args...), strconv.Quote(rule))
+	return fmt.Sprintf("%sErrorf(%q, %s)", ctx.p.qualify("fmt"), p.format+": failed on %s", strings.Join(args, ", "))
+}
+
+// validateZero returns condition which is true if the value x is zero.
+func validateZero(kind, x string) (string, error) {
+	switch kind {
+	case "string":
+		return x + ` == ""`, nil
+	case "int", "uint", "float", "duration":
+		return x + " == 0", nil
+	case "bool":
+		return "!" + x, nil
+	case "slice", "map", "func", "iface":
+		return x + " == nil", nil
+	case "time":
+		return x + ".IsZero()", nil
+	}
+	return "", fmt.Errorf("not supported for %s", kind)
+}
+
+func validateBasicKind(u UnderlyingType) string {
+	switch u {
+	case "string":
+		return "string"
+	case "bool":
+		return "bool"
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		return "int"
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		return "uint"
+	case "float32", "float64":
+		return "float"
+	case "error", "any", "interface{}":
+		return "iface"
+	}
+	return "other"
+}
+
+// validateNumber returns the param as number literal of the kind.
+func validateNumber(kind, param string) (string, error) {
+	var err error
+	switch kind {
+	case "int":
+		_, err = strconv.ParseInt(param, 10, 64)
+	case "uint":
+		_, err = strconv.ParseUint(param, 10, 64)
+	default:
+		_, err = strconv.ParseFloat(param, 64)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid param: %s", param)
+	}
+	return param, nil
+}
+
+var oneOfReg = regexp.MustCompile(`'[^']*'|\S+`)
+
+// splitOneOf splits param of oneof like `a b 'c d'`.
+func splitOneOf(param string) []string {
+	vals := oneOfReg.FindAllString(param, -1)
+	for i, v := range vals {
+		if len(v) >= 2 && v[0] == '\'' {
+			vals[i] = v[1 : len(v)-1]
+		}
+	}
+	return vals
+}
+
+func validateRegexpName(rule string) string {
+	name := strings.ToUpper(rule[:1]) + rule[1:]
+	if rule == "uuid" {
+		name = "UUID"
+	}
+	return "validate" + name + "Regexp"
+}
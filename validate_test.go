@@ -0,0 +1,204 @@
+package stst_test
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValidateTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		want    *stst.ValidateRules
+		wantErr bool
+	}{
+		{
+			name: "rules",
+			tag:  "required,min=1,oneof=a b",
+			want: &stst.ValidateRules{
+				Rules: []*stst.ValidateRule{
+					{Name: "required"},
+					{Name: "min", Param: "1"},
+					{Name: "oneof", Param: "a b"},
+				},
+			},
+		},
+		{
+			name: "or and escaped param",
+			tag:  "rgb|rgba,eq=a0x2Cb0x7Cc",
+			want: &stst.ValidateRules{
+				Rules: []*stst.ValidateRule{
+					{Name: "rgb", Or: []*stst.ValidateRule{{Name: "rgba"}}},
+					{Name: "eq", Param: "a,b|c"},
+				},
+			},
+		},
+		{
+			name: "dive with keys",
+			tag:  "max=3,dive,keys,alpha,endkeys,required",
+			want: &stst.ValidateRules{
+				Rules: []*stst.ValidateRule{{Name: "max", Param: "3"}},
+				Keys: &stst.ValidateRules{
+					Rules: []*stst.ValidateRule{{Name: "alpha"}},
+				},
+				Dive: &stst.ValidateRules{
+					Rules: []*stst.ValidateRule{{Name: "required"}},
+				},
+			},
+		},
+		{
+			name: "nested dive",
+			tag:  "dive,dive,email",
+			want: &stst.ValidateRules{
+				Dive: &stst.ValidateRules{
+					Dive: &stst.ValidateRules{
+						Rules: []*stst.ValidateRule{{Name: "email"}},
+					},
+				},
+			},
+		},
+		{
+			name:    "empty rule",
+			tag:     "required,,min=1",
+			wantErr: true,
+		},
+		{
+			name:    "keys without dive",
+			tag:     "keys,alpha,endkeys",
+			wantErr: true,
+		},
+		{
+			name:    "keys without endkeys",
+			tag:     "dive,keys,alpha",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stst.ParseValidateTag(tt.tag)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+const validateSrc = `package data
+
+type SampleString string
+
+type Good struct {
+	Name      string
+	SamplePtr *SampleString
+}
+
+type Status int
+
+type Order struct {
+	ID     int64          ` + "`validate:\"gt=0\"`" + `
+	Name   string         ` + "`validate:\"required,max=10\"`" + `
+	Email  string         ` + "`validate:\"omitempty,email\"`" + `
+	Status Status         ` + "`validate:\"oneof=1 2\"`" + `
+	Good   *Good          ` + "`validate:\"required\"`" + `
+	Item   Good           ` + "`validate:\"required\"`" + `
+	Note   *string        ` + "`validate:\"min=2\"`" + `
+	Codes  []string       ` + "`validate:\"min=1,dive,uuid\"`" + `
+	Labels map[string]int ` + "`validate:\"dive,keys,alpha,endkeys,lte=5\"`" + `
+	Hex    string         ` + "`validate:\"len=3|len=6\"`" + `
+	Cache  string         ` + "`validate:\"-\"`" + `
+}
+
+// Custom has its own Validate
+type Custom struct{}
+
+func (x *Custom) Validate() error {
+	return nil
+}
+`
+
+func TestValidateGenerate(t *testing.T) {
+	schemas := parseSource(t, validateSrc)
+
+	var targets []*stst.Schema
+	for _, name := range []string{"Good", "Order", "Custom"} {
+		targets = append(targets, findSchema(schemas, name))
+	}
+	got, err := stst.NewValidateGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, targets)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "data_gen.go", got, parser.AllErrors)
+	require.NoError(t, err)
+
+	src := string(got)
+	for _, want := range []string{
+		"\tvalidateAlphaRegexp = regexp.MustCompile(",
+		"\tvalidateUUIDRegexp  = regexp.MustCompile(",
+		"func (x *Order) Validate() error {\n\tif x == nil {\n\t\treturn nil\n\t}",
+		"\tif !(x.ID > 0) {\n\t\treturn fmt.Errorf(\"ID: failed on %s\", \"gt=0\")\n\t}",
+		"\tif x.Name == \"\" {\n\t\treturn fmt.Errorf(\"Name: failed on %s\", \"required\")\n\t}\n\tif !(utf8.RuneCountInString(x.Name) <= 10) {",
+		"\tif !(x.Email == \"\") {\n\t\tif !(func() bool {\n\t\t\ta, err := mail.ParseAddress(x.Email)",
+		"\tif !(x.Status == 1 || x.Status == 2) {",
+		"\tif x.Good == nil {\n\t\treturn fmt.Errorf(\"Good: failed on %s\", \"required\")\n\t}\n\tif x.Good != nil {\n\t\tif err := x.Good.Validate(); err != nil {\n\t\t\treturn fmt.Errorf(\"Good: %w\", err)",
+		// required of struct value is skipped
+		"\tif err := x.Item.Validate(); err != nil {\n\t\treturn fmt.Errorf(\"Item: %w\", err)\n\t}",
+		"\tif x.Note != nil {\n\t\tif !(utf8.RuneCountInString((*x.Note)) >= 2) {",
+		"\tif !(len(x.Codes) >= 1) {",
+		"\tfor i0 := range x.Codes {\n\t\tif !(validateUUIDRegexp.MatchString(x.Codes[i0])) {\n\t\t\treturn fmt.Errorf(\"Codes[%d]: failed on %s\", i0, \"uuid\")",
+		"\tfor k0, v0 := range x.Labels {\n\t\tif !(validateAlphaRegexp.MatchString(k0)) {\n\t\t\treturn fmt.Errorf(\"Labels[%v]: failed on %s\", k0, \"alpha\")\n\t\t}\n\t\tif !(v0 <= 5) {",
+		"\tif !(utf8.RuneCountInString(x.Hex) == 3 || utf8.RuneCountInString(x.Hex) == 6) {\n\t\treturn fmt.Errorf(\"Hex: failed on %s\", \"len=3|len=6\")",
+		"func (x *Good) Validate() error {",
+	} {
+		assert.Contains(t, src, want)
+	}
+	for _, notWant := range []string{
+		"func (x *Custom)",
+		"Cache",
+		"Item: failed on",
+	} {
+		assert.NotContains(t, src, notWant)
+	}
+}
+
+func TestValidateGenerate_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+	}{
+		{
+			name:  "min on bool",
+			field: "OK bool `validate:\"min=1\"`",
+		},
+		{
+			name:  "invalid param",
+			field: "Age int `validate:\"max=a\"`",
+		},
+		{
+			name:  "email on int",
+			field: "Age int `validate:\"email\"`",
+		},
+		{
+			name:  "dive on string",
+			field: "Name string `validate:\"dive,required\"`",
+		},
+		{
+			name:  "unsupported rule",
+			field: "Name string `validate:\"unknown\"`",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			schemas := parseSource(t, "package data\n\ntype Target struct {\n\t"+tt.field+"\n}\n")
+			_, err := stst.NewValidateGenerator(schemas).Generate(stst.GoPackage{Name: "data", ID: testPkg}, schemas)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package stst
+
+type (
+	// WireChangeKind is kind of WireChange.
+	WireChangeKind string
+
+	// WireChange is a change of serialized field of struct for a tag key (like `json`).
+	WireChange struct {
+		Kind   WireChangeKind `json:"kind"`
+		TagKey string         `json:"tag_key"`
+		Schema string         `json:"schema"`
+		// Field is name of the Go field, fields promoted from embedded structs are like `Base.ID`
+		Field string `json:"field"`
+		// Old and New are serialized names or encodings
+		Old      string `json:"old,omitempty"`
+		New      string `json:"new,omitempty"`
+		Breaking bool   `json:"breaking"`
+	}
+
+	// WireReport is result of CheckWireCompat.
+	WireReport struct {
+		TagKeys []string      `json:"tag_keys"`
+		Changes []*WireChange `json:"changes"`
+	}
+
+	// wireField is a serialized field of struct.
+	wireField struct {
+		name      string
+		goName    string
+		encoding  string
+		omitempty bool
+	}
+)
+
+const (
+	// WireFieldRenamed is change of serialized name of the same Go field
+	WireFieldRenamed WireChangeKind = "renamed"
+	// WireFieldRemoved is removal of serialized field (including ignoring it by `-`)
+	WireFieldRemoved WireChangeKind = "removed"
+	WireFieldAdded   WireChangeKind = "added"
+	// WireEncodingChanged is change of type which changes how the value is encoded like `int` to `string`.
+	// Changes keeping the encoding like `int32` to `int64` are not reported.
+	WireEncodingChanged WireChangeKind = "encoding_changed"
+	// WireOmitemptyAdded is breaking because clients may expect the field always exists
+	WireOmitemptyAdded   WireChangeKind = "omitempty_added"
+	WireOmitemptyRemoved WireChangeKind = "omitempty_removed"
+)
+
+// CheckWireCompat returns changes of serialized fields of structs from the old schemas to the new schemas
+// for each of the tag keys like `json`, `bigquery` or `db`.
+//
+// Serialized name is the first value of the tag, or name of the Go field if it is empty
+// (snake case of it for `db` in the same way as DDLGenerator).
+// Fields of embedded structs without names are promoted.
+// Fields are matched by serialized names, and then by names of Go fields to detect renames.
+func CheckWireCompat(old, new []*Schema, tagKeys []string) *WireReport {
+	oldIdx, newIdx := NewIndex(old), NewIndex(new)
+	olds, news := exportedSchemas(old), exportedSchemas(new)
+	out := &WireReport{TagKeys: tagKeys, Changes: []*WireChange{}}
+	for _, key := range tagKeys {
+		for _, name := range sortedKeys(olds) {
+			o, n := olds[name], news[name]
+			if n == nil || !o.IsStruct() || !n.IsStruct() {
+				continue
+			}
+			out.Changes = append(out.Changes, wireChanges(key, name,
+				wireFields(oldIdx, o.Fields, key, "", map[*Schema]bool{o: true}),
+				wireFields(newIdx, n.Fields, key, "", map[*Schema]bool{n: true}))...)
+		}
+	}
+	return out
+}
+
+// HasBreaking returns whether the report has breaking changes.
+func (r *WireReport) HasBreaking() bool {
+	for _, c := range r.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+func wireChanges(key, schema string, olds, news []*wireField) []*WireChange {
+	newByName := make(map[string]*wireField, len(news))
+	newByGo := make(map[string]*wireField, len(news))
+	for _, f := range news {
+		if _, ok := newByName[f.name]; !ok {
+			newByName[f.name] = f
+		}
+		newByGo[f.goName] = f
+	}
+	oldNames := make(map[string]bool, len(olds))
+	for _, f := range olds {
+		oldNames[f.name] = true
+	}
+
+	var out []*WireChange
+	renamed := map[*wireField]bool{}
+	for _, of := range olds {
+		c := &WireChange{TagKey: key, Schema: schema, Field: of.goName}
+		nf, ok := newByName[of.name]
+		if !ok {
+			nf, ok = newByGo[of.goName]
+			if !ok || oldNames[nf.name] {
+				c.Kind, c.Old, c.Breaking = WireFieldRemoved, of.name, true
+				out = append(out, c)
+				continue
+			}
+			// encoding and omitempty of the renamed field are checked too
+			renamed[nf] = true
+			cc := *c
+			cc.Kind, cc.Old, cc.New, cc.Breaking = WireFieldRenamed, of.name, nf.name, true
+			out = append(out, &cc)
+		}
+		if of.encoding != nf.encoding {
+			cc := *c
+			cc.Kind, cc.Old, cc.New, cc.Breaking = WireEncodingChanged, of.encoding, nf.encoding, true
+			out = append(out, &cc)
+		}
+		switch {
+		case !of.omitempty && nf.omitempty:
+			c.Kind, c.Old, c.New, c.Breaking = WireOmitemptyAdded, of.name, nf.name, true
+			out = append(out, c)
+		case of.omitempty && !nf.omitempty:
+			c.Kind, c.Old, c.New = WireOmitemptyRemoved, of.name, nf.name
+			out = append(out, c)
+		}
+	}
+	for _, nf := range news {
+		if !oldNames[nf.name] && !renamed[nf] {
+			out = append(out, &WireChange{Kind: WireFieldAdded, TagKey: key, Schema: schema, Field: nf.goName, New: nf.name})
+		}
+	}
+	return out
+}
+
+// wireFields returns serialized fields of the struct for the tag key.
+func wireFields(idx *Index, fields []*Field, key, path string, visiting map[*Schema]bool) []*wireField {
+	var out []*wireField
+	for _, f := range fields {
+		tag, _ := f.Tag(key)
+		if tag != nil && tag.Name() == "-" && len(tag.Values) == 1 {
+			continue
+		}
+		if f.IsEmbedded && (tag == nil || tag.Name() == "") {
+			if emb := lookupLocal(idx, f.Type); emb != nil && emb.IsStruct() && !visiting[emb] {
+				visiting[emb] = true
+				out = append(out, wireFields(idx, emb.Fields, key, path+f.Name+".", visiting)...)
+				delete(visiting, emb)
+				continue
+			}
+		}
+		if !f.IsExported() || f.IsFunc() {
+			continue
+		}
+
+		wf := &wireField{
+			name:     f.Name,
+			goName:   path + f.Name,
+			encoding: wireEncoding(idx, f, f.TypePrefixes, map[*Schema]bool{}),
+		}
+		if key == dbTagKey {
+			wf.name = toSnakeCase(f.Name)
+		}
+		if tag != nil {
+			if tag.Name() != "" {
+				wf.name = tag.Name()
+			}
+			wf.omitempty = tag.HasOption("omitempty")
+			if key == jsonTagKey && tag.HasOption("string") {
+				wf.encoding = "string<" + wf.encoding + ">"
+			}
+		}
+		out = append(out, wf)
+	}
+	return out
+}
+
+// wireEncoding returns how the value is encoded like `integer`, `array<string>` or `object`.
+// Pointers do not change the encoding.
+func wireEncoding(idx *Index, f *Field, prefixes []TypePrefix, visiting map[*Schema]bool) string {
+	if len(prefixes) > 0 {
+		switch prefixes[0].Kind() {
+		case TypePrefixKindPtr:
+			return wireEncoding(idx, f, prefixes[1:], visiting)
+		default:
+			if len(prefixes) == 1 && prefixes[0].Kind() == TypePrefixKindSlice && f.Type != nil && isByte(f.Type) {
+				return "bytes"
+			}
+			return "array<" + wireEncoding(idx, f, prefixes[1:], visiting) + ">"
+		}
+	}
+	switch {
+	case f.IsMap():
+		if f.Map == nil || f.Map.Value == nil {
+			return "object"
+		}
+		return "object<" + wireEncoding(idx, f.Map.Value, f.Map.Value.TypePrefixes, visiting) + ">"
+	case f.IsUntitledStruct:
+		return "object"
+	case f.IsUntitledInterface:
+		return "any"
+	case f.Type == nil:
+		return "any"
+	}
+
+	t := f.Type
+	if t.Underlying == "time.Time" {
+		return "timestamp"
+	}
+	if t.IsBasic() {
+		return basicWireEncoding(t.Underlying)
+	}
+	sc := lookupLocal(idx, t)
+	switch {
+	case sc == nil:
+		return string(t.Underlying)
+	case sc.IsStruct():
+		return "object"
+	case sc.IsInterface:
+		return "any"
+	case visiting[sc]:
+		return sc.Name
+	}
+	// defined as other type like `type Status int`
+	visiting[sc] = true
+	defer delete(visiting, sc)
+	return wireEncoding(idx, &Field{Type: sc.Type, Map: sc.Map, Func: sc.Func}, sc.TypePrefixes, visiting)
+}
+
+func basicWireEncoding(u UnderlyingType) string {
+	switch u {
+	case "bool":
+		return "boolean"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return "integer"
+	case "float32", "float64":
+		return "number"
+	case "error", "any":
+		return "any"
+	}
+	return string(u)
+}
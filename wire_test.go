@@ -0,0 +1,98 @@
+package stst_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/maru44/stst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const wireOldSrc = `package data
+
+import "time"
+
+type (
+	Base struct {
+		ID int64 ` + "`json:\"id\"`" + `
+	}
+
+	Status int
+
+	Order struct {
+		Base
+		UserName  string   ` + "`json:\"user_name\" db:\"user_name\"`" + `
+		Total     int32    ` + "`json:\"total\"`" + `
+		Status    Status   ` + "`json:\"status\"`" + `
+		Note      string   ` + "`json:\"note\"`" + `
+		Memo      string   ` + "`json:\"memo,omitempty\"`" + `
+		Codes     []string ` + "`json:\"codes\"`" + `
+		Ref       int      ` + "`json:\"ref\"`" + `
+		Secret    string   ` + "`json:\"secret\"`" + `
+		CreatedAt time.Time
+	}
+)
+`
+
+const wireNewSrc = `package data
+
+import "time"
+
+type (
+	Base struct {
+		ID int64 ` + "`json:\"order_id\"`" + `
+	}
+
+	Status int
+
+	Order struct {
+		Base
+		UserName  string    ` + "`json:\"userName\" db:\"user_name\"`" + `
+		Total     *int64    ` + "`json:\"total,string\"`" + `
+		Status    Status    ` + "`json:\"status,omitempty\"`" + `
+		Memo      string    ` + "`json:\"memo\"`" + `
+		Codes     string    ` + "`json:\"codes\"`" + `
+		Ref       string    ` + "`json:\"ref_id,omitempty\"`" + `
+		Secret    string    ` + "`json:\"-\"`" + `
+		CreatedAt time.Time
+		UpdatedAt time.Time ` + "`json:\"updated_at\"`" + `
+	}
+)
+`
+
+func TestCheckWireCompat(t *testing.T) {
+	old := parseSource(t, wireOldSrc)
+	new := parseSource(t, wireNewSrc)
+
+	got := stst.CheckWireCompat(old, new, []string{"json", "db"})
+	assert.Equal(t, []*stst.WireChange{
+		{Kind: stst.WireFieldRenamed, TagKey: "json", Schema: "Base", Field: "ID", Old: "id", New: "order_id", Breaking: true},
+		{Kind: stst.WireFieldRenamed, TagKey: "json", Schema: "Order", Field: "Base.ID", Old: "id", New: "order_id", Breaking: true},
+		{Kind: stst.WireFieldRenamed, TagKey: "json", Schema: "Order", Field: "UserName", Old: "user_name", New: "userName", Breaking: true},
+		{Kind: stst.WireEncodingChanged, TagKey: "json", Schema: "Order", Field: "Total", Old: "integer", New: "string<integer>", Breaking: true},
+		{Kind: stst.WireOmitemptyAdded, TagKey: "json", Schema: "Order", Field: "Status", Old: "status", New: "status", Breaking: true},
+		{Kind: stst.WireFieldRemoved, TagKey: "json", Schema: "Order", Field: "Note", Old: "note", Breaking: true},
+		{Kind: stst.WireOmitemptyRemoved, TagKey: "json", Schema: "Order", Field: "Memo", Old: "memo", New: "memo"},
+		{Kind: stst.WireEncodingChanged, TagKey: "json", Schema: "Order", Field: "Codes", Old: "array<string>", New: "string", Breaking: true},
+		// renamed field is checked too
+		{Kind: stst.WireFieldRenamed, TagKey: "json", Schema: "Order", Field: "Ref", Old: "ref", New: "ref_id", Breaking: true},
+		{Kind: stst.WireEncodingChanged, TagKey: "json", Schema: "Order", Field: "Ref", Old: "integer", New: "string", Breaking: true},
+		{Kind: stst.WireOmitemptyAdded, TagKey: "json", Schema: "Order", Field: "Ref", Old: "ref", New: "ref_id", Breaking: true},
+		{Kind: stst.WireFieldRemoved, TagKey: "json", Schema: "Order", Field: "Secret", Old: "secret", Breaking: true},
+		{Kind: stst.WireFieldAdded, TagKey: "json", Schema: "Order", Field: "UpdatedAt", New: "updated_at"},
+		{Kind: stst.WireFieldRemoved, TagKey: "db", Schema: "Order", Field: "Note", Old: "note", Breaking: true},
+		{Kind: stst.WireEncodingChanged, TagKey: "db", Schema: "Order", Field: "Codes", Old: "array<string>", New: "string", Breaking: true},
+		{Kind: stst.WireEncodingChanged, TagKey: "db", Schema: "Order", Field: "Ref", Old: "integer", New: "string", Breaking: true},
+		{Kind: stst.WireFieldAdded, TagKey: "db", Schema: "Order", Field: "UpdatedAt", New: "updated_at"},
+	}, got.Changes)
+	assert.True(t, got.HasBreaking())
+
+	b, err := json.Marshal(got.Changes[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"renamed","tag_key":"json","schema":"Base","field":"ID","old":"id","new":"order_id","breaking":true}`, string(b))
+
+	same := stst.CheckWireCompat(old, parseSource(t, wireOldSrc), []string{"json", "db"})
+	assert.Empty(t, same.Changes)
+	assert.False(t, same.HasBreaking())
+}